@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/handlers"
+	"gorm.io/gorm"
+	"main.go/config"
+	"main.go/internal/database"
+	"main.go/internal/websocket"
+	"main.go/middlewares"
+	"main.go/pkg/storage"
+	"main.go/pkg/tracing"
+	"main.go/services"
+	"main.go/services/auth"
+	"main.go/services/health"
+	"main.go/services/image"
+	"main.go/services/user"
+)
+
+// App holds the dependencies of the api, they are built once by NewApp and passed explicitly to the services.
+type App struct {
+	Config    config.Config
+	DB        *gorm.DB
+	Images    storage.ImageStorage
+	WsManager *websocket.Manager
+	Health    *health.Handler
+	Server    *http.Server
+
+	shutdownTimeout time.Duration
+	drainDelay      time.Duration
+	shutdownTracing func(ctx context.Context) error
+	// the background jobs, the database is closed once they returned.
+	workers sync.WaitGroup
+}
+
+// builds the app and registers its routes, ctx stops the background goroutines (otps retention, accounts purger).
+func NewApp(ctx context.Context, cfg config.Config) (*App, error) {
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Options{
+		Exporter:    cfg.TRACES_EXPORTER,
+		ServiceName: cfg.OTEL_SERVICE_NAME,
+		SampleRatio: cfg.TRACES_SAMPLE_RATIO,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup the tracing: %w", err)
+	}
+
+	DB, err := database.Init(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := storage.New(cfg)
+	if err != nil {
+		database.Close(DB)
+		return nil, err
+	}
+
+	app := &App{
+		Config:          cfg,
+		DB:              DB,
+		Images:          images,
+		shutdownTimeout: cfg.SHUTDOWN_TIMEOUT,
+		drainDelay:      cfg.SHUTDOWN_DRAIN_DELAY,
+		shutdownTracing: shutdownTracing,
+	}
+	if err := app.setupRoutes(ctx); err != nil {
+		database.Close(DB)
+		return nil, err
+	}
+
+	return app, nil
+}
+
+func (a *App) setupRoutes(ctx context.Context) error {
+	// the authorization middlewares are bound to the lookups when the routes are registered
+	if err := middlewares.Setup(a.DB, a.Config); err != nil {
+		return err
+	}
+
+	server := http.NewServeMux()
+
+	a.WsManager = websocket.NewManager(ctx, a.DB)
+	websocket.Setup(a.WsManager, server)
+
+	services.SetupAllServices(a.DB, a.Images, server)
+	storage.RegisterRoutes(a.Images, server)
+	a.Health = health.Setup(a.DB, server)
+	registerMetrics(a.DB, a.Config, a.WsManager)
+	server.HandleFunc("GET /metrics", middlewares.MetricsHandler(a.Config.METRICS_TOKEN))
+	user.StartDeletionPurger(ctx, &a.workers, a.DB, time.Hour)
+	image.StartDeletionsWorker(ctx, &a.workers, a.DB, a.Images, time.Minute)
+	loggedServer := middlewares.RequestID(middlewares.Tracing(middlewares.Logger(middlewares.Metrics(server))))
+
+	corsServer := handlers.CORS(
+		handlers.AllowedOrigins([]string{"*"}),
+		handlers.AllowedMethods([]string{"GET", "POST", "DELETE", "PATCH", "PUT"}),
+		handlers.AllowedHeaders([]string{"Content-Type", "Authorization", "X-CSRF-Token", "X-Refresh-Token", "X-API-Key", middlewares.RequestIDHeader, "traceparent", "tracestate"}),
+		handlers.ExposedHeaders([]string{middlewares.RequestIDHeader, tracing.TraceIDHeader, auth.ImpersonatedByHeader, "Retry-After"}),
+	)(loggedServer)
+
+	a.Server = &http.Server{
+		Addr:              a.Config.Port,
+		Handler:           corsServer,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	// the websocket connections are hijacked so Shutdown does not wait for them, they are closed by the manager
+	a.Server.RegisterOnShutdown(a.WsManager.Shutdown)
+
+	return nil
+}
+
+// serves until ctx is cancelled (SIGINT/SIGTERM) then drains the in-flight requests, it returns the exit code.
+func (a *App) Run(ctx context.Context) int {
+	serverErr := make(chan error, 1)
+	go func() {
+		_, port, _ := net.SplitHostPort(a.Config.Port)
+		slog.Info("listening", "port", port)
+		serverErr <- a.Server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("server stopped", "error", err)
+			return 1
+		}
+		return 0
+	case <-ctx.Done():
+	}
+
+	// the new requests are still served until the load balancer sees the failing readiness check
+	slog.Info("shutting down, failing the readiness check", "drainDelayInSeconds", a.drainDelay.Seconds())
+	a.Health.SetShuttingDown()
+	time.Sleep(a.drainDelay)
+
+	slog.Info("draining the in-flight requests", "timeoutInSeconds", a.shutdownTimeout.Seconds())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+	exitCode := 0
+	err := a.Server.Shutdown(shutdownCtx)
+	cancel()
+	if err != nil {
+		slog.Error("failed to drain the in-flight requests", "error", err)
+		exitCode = 1
+	}
+
+	// flushes the spans of the drained requests, it shares the shutdown timeout
+	tracingCtx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+	err = a.shutdownTracing(tracingCtx)
+	cancel()
+	if err != nil {
+		slog.Error("failed to flush the traces", "error", err)
+		exitCode = 1
+	}
+
+	// the jobs were stopped by ctx, a running pass is waited for so it does not fail on a closed pool
+	workersDone := make(chan struct{})
+	go func() {
+		a.workers.Wait()
+		close(workersDone)
+	}()
+	select {
+	case <-workersDone:
+	case <-time.After(a.shutdownTimeout):
+		slog.Error("the background jobs did not stop in time")
+		exitCode = 1
+	}
+
+	err = database.Close(a.DB)
+	if err != nil {
+		slog.Error("failed to close the database pool", "error", err)
+		exitCode = 1
+	}
+
+	slog.Info("server stopped")
+	return exitCode
+}
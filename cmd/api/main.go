@@ -2,37 +2,61 @@ package main
 
 import (
 	"context"
-	"log"
-	"net/http"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 
-	"github.com/gorilla/handlers"
 	"main.go/config"
-	"main.go/internal/database"
-	"main.go/internal/websocket"
-	"main.go/middlewares"
-	"main.go/services"
+	"main.go/pkg/logger"
 )
 
+// Example use
+// go run ./cmd/api/ --config=config.yaml --set PORT=:9090 --set LOG_LEVEL=debug
+// go run ./cmd/api/ --print-config      prints the effective config with the secrets redacted
 func main() {
-	port := config.Envs.Port
-	server := http.NewServeMux()
+	configFile := flag.String("config", "", "yaml or json config file, CONFIG_FILE is read when it is not given")
+	printConfig := flag.Bool("print-config", false, "print the effective config with the secrets redacted and exit")
+	overrides := config.Overrides{}
+	flag.Var(overrides, "set", "override a config variable, KEY=VALUE (can be repeated)")
+	flag.Parse()
 
-	DB := database.DB
-	wsManager := websocket.NewManager(context.Background())
-	websocket.Setup(wsManager, server)
+	cfg, err := config.Load(config.LoadOptions{File: *configFile, Overrides: overrides})
+	if *printConfig {
+		if printErr := cfg.Print(os.Stdout); printErr != nil {
+			err = printErr
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	services.SetupAllServices(DB, server)
-	loggedServer := middlewares.Logger(server)
+	slog.SetDefault(logger.New(os.Stdout, cfg.LOG_LEVEL, cfg.LOG_FORMAT))
+	if err != nil {
+		slog.Error("invalid config", "error", err)
+		os.Exit(1)
+	}
+	// the packages reading config.Envs see the config file and the flags too
+	config.Envs = cfg
 
-	corsServer := handlers.CORS(
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "DELETE", "PATCH", "PUT"}),
-		handlers.AllowedHeaders([]string{"Content-Type"}),
-	)(loggedServer)
+	// cancelled on SIGINT/SIGTERM, it stops the background goroutines (otps retention, accounts purger)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		// a second signal during the shutdown kills the process
+		<-ctx.Done()
+		stop()
+	}()
 
-	log.Printf("Listening to Port: %s", port[1:])
-	err := http.ListenAndServe(port, corsServer)
+	app, err := NewApp(ctx, cfg)
 	if err != nil {
-		log.Fatal(err)
+		slog.Error("failed to start the api", "error", err)
+		os.Exit(1)
 	}
+
+	os.Exit(app.Run(ctx))
 }
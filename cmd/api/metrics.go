@@ -0,0 +1,34 @@
+package main
+
+import (
+	"log/slog"
+
+	"gorm.io/gorm"
+	"main.go/config"
+	"main.go/internal/websocket"
+	"main.go/middlewares"
+	"main.go/pkg/metrics"
+	"main.go/pkg/models"
+)
+
+func registerMetrics(DB *gorm.DB, cfg config.Config, wsManager *websocket.Manager) {
+	sqlDB, err := DB.DB()
+	if err != nil {
+		slog.Error("failed to get the database pool, its stats are not exposed", "error", err)
+	} else {
+		metrics.RegisterDBStats(sqlDB, cfg.DBName)
+	}
+
+	metrics.RegisterGaugeFunc("websocket_clients", "Count of the connected websocket clients.", func() float64 {
+		return float64(wsManager.ClientsCount())
+	})
+	metrics.RegisterGaugeFunc("websocket_otps", "Count of the websocket otps that were not used yet.", func() float64 {
+		return float64(wsManager.Otps.Size())
+	})
+	metrics.RegisterGaugeFunc("storage_pending_deletions", "Count of the images waiting to be deleted from the storage.", func() float64 {
+		var count int64
+		DB.Model(&models.StorageDeletion{}).Count(&count)
+		return float64(count)
+	})
+	metrics.RegisterCacheStats(middlewares.UserCacheStats)
+}
@@ -8,13 +8,19 @@ import (
 	"path/filepath"
 	"time"
 
+	"main.go/config"
 	"main.go/internal/database"
 )
 
 func createBackup() {
-	DB := database.DB
-	var tables []string
-	err := DB.Raw("SHOW TABLES").Find(&tables).Error
+	DB, err := database.Open(config.Envs)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+	defer database.Close(DB)
+
+	tables, err := DB.Migrator().GetTables()
 	if err != nil {
 		log.Fatal(err)
 		return
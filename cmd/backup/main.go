@@ -16,6 +16,7 @@ type DatabaseFlags struct {
 	Name       *string
 	Password   *string
 	Port       *string
+	Dialect    *string
 	BackupFile *string
 }
 
@@ -43,7 +44,8 @@ func main() {
 		Host:       flag.String("host", "127.0.0.1", "DB Host"),
 		Password:   flag.String("password", "", "Database password"),
 		User:       flag.String("user", "root", "Database user"),
-		Port:       flag.String("port", "3306", "Database port (default 3306)"),
+		Port:       flag.String("port", "", "Database port (default 3306 for mysql and 5432 for postgres)"),
+		Dialect:    flag.String("dialect", "mysql", "Database dialect: mysql, postgres or sqlite (dbname is the file path)"),
 		Name:       flag.String("dbname", "", "Database name"),
 		BackupFile: flag.String("backupFile", "", "backup file"),
 	}
@@ -9,9 +9,10 @@ import (
 	"path/filepath"
 	"strconv"
 
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
-	"main.go/pkg/models"
+	"main.go/config"
+	"main.go/internal/database/dialect"
+	"main.go/internal/database/migrations"
 )
 
 func restoreData() {
@@ -21,9 +22,15 @@ func restoreData() {
 		return
 	}
 
+	dbDialect, err := dialect.Get(*ScannedDatabaseFlags.Dialect)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
 	dsn := createDSN()
 	backupFile := ScannedDatabaseFlags.BackupFile
-	DB, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	DB, err := dialect.Open(dbDialect, dsn, &gorm.Config{})
 	if err != nil {
 		log.Fatal(err)
 		return
@@ -57,9 +64,23 @@ func restoreData() {
 	}
 
 
-	disableForeignKeyChecks(DB)
-	defer enableForeignKeyChecks(DB)
+	// the tables are restored in the order of the backup, not the order of their relations
+	err = dbDialect.WithoutForeignKeys(DB, func(conn *gorm.DB) error {
+		restoreTables(conn, dbDialect, tables)
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func restoreTables(DB *gorm.DB, dbDialect dialect.Dialect, tables []TableData) {
 	for _, table := range tables {
+		// already filled by migrateDB with the versions of this build
+		if table.TableName == (migrations.SchemaMigration{}).TableName() {
+			continue
+		}
+
 		log.Printf("Restoring data to table %v\n has begun", table.TableName)
 
 		for _, row := range table.Rows {
@@ -69,6 +90,11 @@ func restoreData() {
 			}
 		}
 
+		err := dbDialect.SyncSequence(DB, table.TableName)
+		if err != nil {
+			log.Printf("an error has occurred during syncing the id sequence of table: %v: %v\n", table.TableName, err)
+		}
+
 		log.Printf("Restoring data to table %v\n has finished", table.TableName)
 	}
 }
@@ -82,7 +108,7 @@ func validateInputs() error {
 	if *dbName == "" {
 		return fmt.Errorf("database name is required (use --dbname)")
 	}
-	if *dbPassword == "" {
+	if *dbPassword == "" && *ScannedDatabaseFlags.Dialect != dialect.SQLite {
 		return fmt.Errorf("database password is required (use --password)")
 	}
 	if *backupFile == "" {
@@ -108,34 +134,25 @@ func createDSN() string {
 	dbName := ScannedDatabaseFlags.Name
 	dbHost := ScannedDatabaseFlags.Host
 
-	dsn := fmt.Sprintf("%v:%v@tcp(%v:%v)/%v?charset=utf8mb4&parseTime=True&loc=Local",
-		*dbUser, *dbPassword, *dbHost,*dbPort, *dbName)
+	dsn := config.BuildDSN(config.DSNOptions{
+		Dialect:  *ScannedDatabaseFlags.Dialect,
+		Host:     *dbHost,
+		Port:     *dbPort,
+		User:     *dbUser,
+		Password: *dbPassword,
+		Name:     *dbName,
+	})
 
 	return dsn
 }
 
+// applies every migration so the restored rows match the schema of this build.
 func migrateDB(db *gorm.DB) error {
-	err := db.AutoMigrate(
-		&models.Category{}, &models.Product{}, &models.User{},
-		&models.CartItem{}, &models.Review{}, &models.Order{},
-		&models.OrderItem{}, &models.Image{}, &models.Role{},
-		&models.UserRoles{}, &models.Address{}, &models.Message{},
-	)
-	if err != nil {
-		return err
-	}
-	err = db.SetupJoinTable(&models.User{}, "Roles", &models.UserRoles{})
+	migrator, err := migrations.NewMigrator(db, migrations.All())
 	if err != nil {
 		return err
 	}
 
-	return nil
+	_, err = migrator.Up(0, false)
+	return err
 }
-
-func disableForeignKeyChecks(db *gorm.DB) error {
-	return db.Exec("SET FOREIGN_KEY_CHECKS = 0;").Error
-}
-
-func enableForeignKeyChecks(db *gorm.DB) error {
-	return db.Exec("SET FOREIGN_KEY_CHECKS = 1;").Error
-}
\ No newline at end of file
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"main.go/config"
+	"main.go/internal/database"
+	"main.go/internal/database/migrations"
+)
+
+// Example use
+// make migrate                          applies every pending migration
+// make migrate ARGS="status"            lists the migrations and whether they are applied
+// make migrate ARGS="up --steps=1"      applies the next pending migration
+// make migrate ARGS="down"              rolls back the last applied migration
+// make migrate ARGS="down --steps=2 --dry-run"
+// or without make files: go run ./cmd/migrate/ status
+// ** the connection is read from the same env variables as the api (DB_HOST, DB_NAME, ...)
+func main() {
+	command := "up"
+	args := os.Args[1:]
+	if len(args) > 0 && args[0][0] != '-' {
+		command = args[0]
+		args = args[1:]
+	}
+
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	steps := flags.Int("steps", 0, "number of migrations to apply (0 applies all of them) or to roll back (at least 1)")
+	dryRun := flags.Bool("dry-run", false, "print the migrations that would be applied or rolled back without running them")
+	flags.Parse(args)
+
+	if err := config.LoadError(); err != nil {
+		log.Fatal(err)
+	}
+	DB, err := database.Open(config.Envs)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer database.Close(DB)
+
+	migrator, err := migrations.NewMigrator(DB, migrations.All())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch command {
+	case "status":
+		err = printStatus(migrator)
+	case "up":
+		err = up(migrator, *steps, *dryRun)
+	case "down":
+		err = down(migrator, *steps, *dryRun)
+	default:
+		err = fmt.Errorf("unknown command '%v', expected one of: up, down, status", command)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func printStatus(migrator *migrations.Migrator) error {
+	statuses, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		if status.Applied {
+			fmt.Printf("applied  %v  (%v)\n", status.Migration, status.AppliedAt.Format("2006-01-02 15:04:05"))
+		} else {
+			fmt.Printf("pending  %v\n", status.Migration)
+		}
+	}
+	return nil
+}
+
+func up(migrator *migrations.Migrator, steps int, dryRun bool) error {
+	applied, err := migrator.Up(steps, dryRun)
+	printMigrations(applied, "apply", dryRun)
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		fmt.Println("the database is up to date")
+	}
+	return nil
+}
+
+func down(migrator *migrations.Migrator, steps int, dryRun bool) error {
+	rolledBack, err := migrator.Down(steps, dryRun)
+	printMigrations(rolledBack, "roll back", dryRun)
+	if err != nil {
+		return err
+	}
+	if len(rolledBack) == 0 {
+		fmt.Println("there is no applied migration to roll back")
+	}
+	return nil
+}
+
+func printMigrations(list []migrations.Migration, verb string, dryRun bool) {
+	for _, migration := range list {
+		if dryRun {
+			fmt.Printf("would %v  %v\n", verb, migration)
+		} else {
+			fmt.Printf("%v  %v  done\n", verb, migration)
+		}
+	}
+}
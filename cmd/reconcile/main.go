@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"main.go/config"
+	"main.go/internal/database"
+	"main.go/pkg/storage"
+	"main.go/services/image"
+	"main.go/types"
+)
+
+// Example use
+// make reconcile                            lists the product images of the storage that have no row
+// make reconcile ARGS="--min-age=1h"        includes the files written in the last day except the last hour
+// make reconcile ARGS="--delete"            records them for deletion, the api deletes them from the storage
+// or without make files: go run ./cmd/reconcile/ --delete
+// ** the database and the storage are read from the same env variables as the api (DB_HOST, IMAGE_STORAGE, ...)
+func main() {
+	minAge := flag.Duration("min-age", 24*time.Hour, "the files written more recently are skipped, their rows may not be committed yet")
+	deleteOrphans := flag.Bool("delete", false, "record the orphan files for deletion, the deletions worker of the api deletes them")
+	flag.Parse()
+
+	if err := config.LoadError(); err != nil {
+		log.Fatal(err)
+	}
+	DB, err := database.Open(config.Envs)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer database.Close(DB)
+
+	images, err := storage.New(config.Envs)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	orphans, err := image.FindOrphans(context.Background(), DB, images, types.ProductsFolder, time.Now().Add(-*minAge))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	publicIds := make([]string, 0, len(orphans))
+	for _, orphan := range orphans {
+		fmt.Printf("%v  %v\n", orphan.UpdatedAt.Format("2006-01-02 15:04:05"), orphan.PublicId)
+		publicIds = append(publicIds, orphan.PublicId)
+	}
+	fmt.Printf("%v images without a row in %v\n", len(orphans), types.ProductsFolder)
+
+	if *deleteOrphans && len(publicIds) > 0 {
+		if err := image.EnqueueDeletions(DB, publicIds); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("recorded for deletion, the api deletes them from the storage")
+	}
+}
@@ -1,28 +1,41 @@
 package main
 
 import (
+	"log"
+
 	"main.go/cmd/seed/seed_data"
+	"main.go/config"
+	"main.go/internal/database"
 )
 
 func main() {
+	if err := config.LoadError(); err != nil {
+		log.Fatal(err)
+	}
+	DB, err := database.Init(config.Envs)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer database.Close(DB)
+
 	setFakeUsers := false
 	setFakeProducts := false
 	setFakeReviews := true
 	setFakeCategories := false
 
 	if setFakeUsers {
-		seed_data.SeedUsers()
+		seed_data.SeedUsers(DB)
 	}
 
 	if setFakeCategories {
-		seed_data.SeedCategories()
+		seed_data.SeedCategories(DB)
 	}
 
 	if setFakeProducts {
-		seed_data.SeedProducts()
+		seed_data.SeedProducts(DB)
 	}
 
 	if setFakeReviews {
-		seed_data.SeedReviews()
+		seed_data.SeedReviews(DB)
 	}
 }
@@ -3,17 +3,16 @@ package seed_data
 import (
 
 	"github.com/brianvoe/gofakeit/v6"
-	"main.go/internal/database"
+	"gorm.io/gorm"
 	"main.go/pkg/models"
 )
 
-func SeedCategories() {
+func SeedCategories(DB *gorm.DB) {
 	for i := 0; i < 17; i++ {
 		category := models.Category{
 			Name: gofakeit.ProductCategory(),
 		}
 
-		DB := database.InitDB()
 		err := DB.Create(&category).Error
 		if err != nil {
 			continue
@@ -4,11 +4,12 @@ import (
 	"log"
 
 	"github.com/brianvoe/gofakeit/v6"
-	"main.go/internal/database"
+	"gorm.io/gorm"
 	"main.go/pkg/models"
+	"main.go/pkg/utils"
 )
 
-func SeedProducts() {
+func SeedProducts(DB *gorm.DB) {
 		for i := 0; i < 100 ; i++ {
 			images := []models.Image{}
 			prodDesc := gofakeit.ProductDescription()
@@ -32,8 +33,13 @@ func SeedProducts() {
 				}
 			}
 			
+			name := capStrLength(gofakeit.ProductName(), 32)
+			sku := gofakeit.UUID()
 			product := models.Product{
-				Name: capStrLength(gofakeit.ProductName(), 32),
+				Name: name,
+				SKU: sku,
+				// the uuid of the sku keeps the slugs of the repeated names unique
+				Slug: utils.Slugify(name) + "-" + sku[:8],
 				Description: &prodDesc,
 				Quantity: gofakeit.UintRange(10,300),
 				CategoryID: gofakeit.UintRange(1,17),
@@ -41,7 +47,6 @@ func SeedProducts() {
 				Images: images,	
 			}
 
-			DB := database.InitDB()
 			err := DB.Create(&product).Error
 			if err != nil {
 				log.Fatal(err)
@@ -4,11 +4,11 @@ import (
 	"log"
 
 	"github.com/brianvoe/gofakeit/v6"
-	"main.go/internal/database"
+	"gorm.io/gorm"
 	"main.go/pkg/models"
 )
 
-func SeedReviews() {
+func SeedReviews(DB *gorm.DB) {
 	
 	for i := 0; i < 100 ; i++ {
 		review := models.Review{
@@ -18,7 +18,6 @@ func SeedReviews() {
 			Rate: uint8(gofakeit.UintRange(1, 5)),
 		}
 
-		DB := database.InitDB()
 		err := DB.Create(&review).Error
 		if err != nil {
 			log.Fatal(err)
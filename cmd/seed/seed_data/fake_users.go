@@ -5,12 +5,12 @@ import (
 	"log"
 
 	"github.com/brianvoe/gofakeit/v6"
-	"main.go/internal/database"
+	"gorm.io/gorm"
 	"main.go/pkg/models"
 	"main.go/services/auth"
 )
 
-func SeedUsers() {
+func SeedUsers(DB *gorm.DB) {
 	for i := 0; i < 100; i++ {
 		pw, _ := auth.HashPassword(gofakeit.Password(true, true, true, true, true, 6))
 		user := models.User{
@@ -19,7 +19,6 @@ func SeedUsers() {
 			Password: pw,
 		}
 
-		DB := database.InitDB()
 		err := DB.Create(&user).Error
 		if err != nil {
 			log.Fatal(err)
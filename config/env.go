@@ -1,83 +1,238 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
+	"net"
+	"net/netip"
+	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// Config is read from the variables named by the "env" tags, see Load for the sources. The durations are given in
+// the "unit" of their tag (ACCESS_JWT_EXPIRATION_IN_SECONDS=900) or as go durations (ACCESS_JWT_EXPIRATION_IN_SECONDS=15m).
 type Config struct {
-	PublicHost                string
-	Port                      string
-	DBUser                    string
-	DBPassword                string
-	DBName                    string
-	DBAddress                 string
-	ACCESS_JWT_EXPIRATION_IN_SECONDS string
-	REFRESH_JWT_EXPIRATION_IN_SECONDS string
-	JWT_SECRET                string
-	Env                       string
-	CLOUDINARY_APIKEY         string
-	CLOUDINARY_SECRET         string
-	CLOUDINARY_NAME           string
-	DSN                       string
-	DSN_NO_DB                 string
-	AUTH_STORE_KEY            string
+	PublicHost *url.URL `env:"PUBLIC_HOST" default:"http://localhost" validate:"required"`
+	Port       string   `env:"PORT" default:":8080" validate:"hostname_port"`
+	Env        string   `env:"env" default:"production"`
+	// the proxies (ips or cidrs) whose "X-Real-IP" header is trusted as the ip of the client, the header is ignored
+	// for the other peers.
+	TRUSTED_PROXIES []netip.Prefix `env:"TRUSTED_PROXIES"`
+
+	// one of mysql, postgres or sqlite, the DSNs are built for it.
+	DB_DIALECT string `env:"DB_DIALECT" default:"mysql" validate:"oneof=mysql postgres sqlite"`
+	DBHost     string `env:"DB_HOST" default:"127.0.0.1"`
+	// empty for the default port of the dialect.
+	DBPort     string `env:"DB_PORT"`
+	DBUser     string `env:"DB_USER" default:"root"`
+	DBPassword string `env:"DB_PASSWORD" default:"mypassword" secret:"true"`
+	DBName     string `env:"DB_NAME" default:"dbname" validate:"required"`
+	DBSSLMode  string `env:"DB_SSLMODE"`
+	// built from the DB_* variables.
+	DSN       string
+	DSN_NO_DB string
+
+	JWT_SECRET             string        `env:"JWT_SECRET" secret:"true" validate:"required"`
+	ACCESS_JWT_EXPIRATION  time.Duration `env:"ACCESS_JWT_EXPIRATION_IN_SECONDS" unit:"1s" validate:"gt=0s"`
+	REFRESH_JWT_EXPIRATION time.Duration `env:"REFRESH_JWT_EXPIRATION_IN_SECONDS" unit:"1s" validate:"gtfield=ACCESS_JWT_EXPIRATION"`
+	AUTH_STORE_KEY         string        `env:"AUTH_STORE_KEY" secret:"true"`
+	// the page of the frontend the oidc callback redirects the browser to, the public host is used when it is not set.
+	OIDC_FRONTEND_URL *url.URL `env:"OIDC_FRONTEND_URL"`
+
+	// where the product images and the avatars are stored: cloudinary, local (the disk of the api) or s3.
+	IMAGE_STORAGE     string `env:"IMAGE_STORAGE" default:"cloudinary" validate:"oneof=cloudinary local s3"`
+	CLOUDINARY_APIKEY string `env:"CLOUDINARY_APIKEY" validate:"required_if=IMAGE_STORAGE cloudinary"`
+	CLOUDINARY_SECRET string `env:"CLOUDINARY_SECRET" secret:"true" validate:"required_if=IMAGE_STORAGE cloudinary"`
+	CLOUDINARY_NAME   string `env:"CLOUDINARY_NAME" validate:"required_if=IMAGE_STORAGE cloudinary"`
+	LOCAL_STORAGE_DIR string `env:"LOCAL_STORAGE_DIR" default:"uploads"`
+	// signs the temporary urls of the local images.
+	STORAGE_SIGNING_KEY string `env:"STORAGE_SIGNING_KEY" secret:"true" validate:"required_if=IMAGE_STORAGE local"`
+	// any s3 compatible service (aws, minio, r2), the region is optional for most of them.
+	S3_ENDPOINT   string `env:"S3_ENDPOINT" default:"s3.amazonaws.com"`
+	S3_REGION     string `env:"S3_REGION"`
+	S3_BUCKET     string `env:"S3_BUCKET" validate:"required_if=IMAGE_STORAGE s3"`
+	S3_ACCESS_KEY string `env:"S3_ACCESS_KEY" validate:"required_if=IMAGE_STORAGE s3"`
+	S3_SECRET_KEY string `env:"S3_SECRET_KEY" secret:"true" validate:"required_if=IMAGE_STORAGE s3"`
+	S3_USE_SSL    bool   `env:"S3_USE_SSL" default:"true"`
+	// the base url of the public objects (a cdn), the objects are read from the endpoint when it is not set.
+	S3_PUBLIC_URL *url.URL `env:"S3_PUBLIC_URL"`
+	// the uploaded images wider or taller than this many pixels are refused.
+	IMAGE_MAX_DIMENSION int `env:"IMAGE_MAX_DIMENSION" default:"6000" validate:"gt=0"`
+
+	// a zero ttl keeps the users until they are evicted.
+	USER_CACHE_TTL                time.Duration `env:"USER_CACHE_TTL_IN_SECONDS" default:"60" unit:"1s" validate:"gte=0s"`
+	USER_CACHE_SIZE               int           `env:"USER_CACHE_SIZE" default:"10000" validate:"gt=0"`
+	ACCOUNT_DELETION_GRACE_PERIOD time.Duration `env:"ACCOUNT_DELETION_GRACE_PERIOD_IN_DAYS" default:"30" unit:"24h" validate:"gte=0s"`
+
+	LOG_LEVEL        string        `env:"LOG_LEVEL" default:"info" validate:"oneof=debug info warn warning error"`
+	LOG_FORMAT       string        `env:"LOG_FORMAT" default:"json" validate:"oneof=json text"`
+	METRICS_TOKEN    string        `env:"METRICS_TOKEN" secret:"true"`
+	SHUTDOWN_TIMEOUT time.Duration `env:"SHUTDOWN_TIMEOUT_IN_SECONDS" default:"30" unit:"1s" validate:"gt=0s"`
+	// the time the load balancer is given to see the failing readiness check before the server stops accepting connections.
+	SHUTDOWN_DRAIN_DELAY time.Duration `env:"SHUTDOWN_DRAIN_DELAY_IN_SECONDS" default:"5" unit:"1s" validate:"gte=0s"`
+	TRACES_EXPORTER      string        `env:"TRACES_EXPORTER" default:"none" validate:"oneof=none stdout otlp"`
+	TRACES_SAMPLE_RATIO  float64       `env:"TRACES_SAMPLE_RATIO" default:"1" validate:"gte=0,lte=1"`
+	OTEL_SERVICE_NAME    string        `env:"OTEL_SERVICE_NAME" default:"golang-shop" validate:"required"`
 }
 
-var Envs = initConfig()
+// loaded on init so a package can read a value without carrying the config, it never panics: the invalid values are
+// returned by LoadError. The commands taking flags load the config again and replace Envs.
+var Envs, loadErr = Load(LoadOptions{})
 
-// dsn := "user:pass@tcp(127.0.0.1:3306)/go-shop?charset=utf8mb4&parseTime=True&loc=Local"
-func initConfig() Config {
-	err := loadEnvFile()
-	if err != nil {
-		panic(err)
+// must be checked by the commands before using Envs.
+func LoadError() error {
+	return loadErr
+}
+
+type LoadOptions struct {
+	// a yaml or json file of variables (PORT: ":8080"), CONFIG_FILE is read when it is empty.
+	File string
+	// the values given by the flags, they take precedence over every other source.
+	Overrides Overrides
+}
+
+// reads the config from, by precedence: the overrides, the process env, the env file (.env), the config file and the
+// defaults of the "default" tags. Every invalid value is reported in the returned error, the config is returned
+// anyway so it can be printed.
+func Load(options LoadOptions) (Config, error) {
+	var errs []error
+	if err := loadEnvFile(); err != nil {
+		errs = append(errs, err)
+	}
+
+	file := options.File
+	if file == "" {
+		file = os.Getenv("CONFIG_FILE")
+	}
+	fileValues = nil
+	if file != "" {
+		values, err := readConfigFile(file)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		fileValues = values
+	}
+
+	lookup := func(key string) (string, bool) {
+		if value, ok := options.Overrides[key]; ok {
+			return value, true
+		}
+		return lookupEnv(key)
 	}
 
-	return Config{
-		PublicHost:        getEnv("PUBLIC_HOST", "http://localhost"),
-		Port:              getEnv("PORT", ":8080"),
-		DBUser:            getEnv("DB_USER", "root"),
-		DBPassword:        getEnv("DB_PASSWORD", "mypassword"),
-		DBAddress:         fmt.Sprintf("%s:%s", getEnv("DB_HOST", "127.0.0.1"), getEnv("DB_PORT", "3306")),
-		DBName:            getEnv("DB_NAME", "dbname"),
-		Env:               getEnv("env", "production"),
-		CLOUDINARY_APIKEY: getEnv("CLOUDINARY_APIKEY", ""),
-		CLOUDINARY_SECRET: getEnv("CLOUDINARY_SECRET", ""),
-		CLOUDINARY_NAME:   getEnv("CLOUDINARY_NAME", ""),
-		DSN: fmt.Sprintf("%s:%s@tcp(%s%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-			getEnv("DB_USER", ""), getEnv("DB_PASSWORD", ""), getEnv("DB_HOST", ""),
-			getEnv("DB_PORT", ""), getEnv("DB_NAME", "")),
-		DSN_NO_DB: fmt.Sprintf("%s:%s@tcp(%s%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-			getEnv("DB_USER", ""), getEnv("DB_PASSWORD", ""), getEnv("DB_HOST", ""),
-			getEnv("DB_PORT", ""), ""),
-		AUTH_STORE_KEY:            getEnv("AUTH_STORE_KEY", ""),
-		JWT_SECRET:                getEnv("JWT_SECRET", ""),
-		ACCESS_JWT_EXPIRATION_IN_SECONDS: getEnv("ACCESS_JWT_EXPIRATION_IN_SECONDS", ""),
-		REFRESH_JWT_EXPIRATION_IN_SECONDS:  getEnv("REFRESH_JWT_EXPIRATION_IN_SECONDS", ""),
+	var cfg Config
+	decodeErrs := decode(&cfg, lookup)
+	for _, key := range slices.Sorted(maps.Keys(decodeErrs)) {
+		errs = append(errs, decodeErrs[key])
 	}
+	cfg.DSN = BuildDSN(cfg.dsnOptions(true))
+	cfg.DSN_NO_DB = BuildDSN(cfg.dsnOptions(false))
+	errs = append(errs, cfg.validate(decodeErrs))
+
+	return cfg, errors.Join(errs...)
 }
 
-var envs map[string]string
+// the values of the config file, the variables that are not part of Config (OIDC_*) are read from it too.
+var fileValues map[string]string
 
-func getEnv(key, fallback string) string {
-	if envs[key] != "" {
-		return envs[key]
+type DSNOptions struct {
+	Dialect  string
+	Host     string
+	Port     string
+	User     string
+	Password string
+	// empty for the DSN of the server without a database, it is used to create the database.
+	Name    string
+	SSLMode string
+}
+
+// sqlite has no server so the name is the path of its file.
+func BuildDSN(options DSNOptions) string {
+	port := strings.TrimPrefix(options.Port, ":")
+	switch options.Dialect {
+	case "postgres":
+		name := options.Name
+		if name == "" {
+			name = "postgres"
+		}
+		if port == "" {
+			port = "5432"
+		}
+		sslMode := options.SSLMode
+		if sslMode == "" {
+			sslMode = "disable"
+		}
+		return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+			options.Host, port, options.User, options.Password, name, sslMode)
+	case "sqlite":
+		if options.Name == "" {
+			return ""
+		}
+		return fmt.Sprintf("file:%s?_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL", options.Name)
+	default:
+		address := options.Host
+		if port != "" {
+			address += ":" + port
+		}
+		return fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+			options.User, options.Password, address, options.Name)
+	}
+}
+
+func (c Config) dsnOptions(withDatabase bool) DSNOptions {
+	options := DSNOptions{
+		Dialect:  c.DB_DIALECT,
+		Host:     c.DBHost,
+		Port:     c.DBPort,
+		User:     c.DBUser,
+		Password: c.DBPassword,
+		SSLMode:  c.DBSSLMode,
+	}
+	if withDatabase {
+		options.Name = c.DBName
 	}
+	return options
+}
 
-	// for docker env
-	val, exists := os.LookupEnv(key)
-	if exists {
-		return val
+// the url of the api under PublicHost, the port of the server is added when PublicHost has none
+// (http://localhost and :8080 give http://localhost:8080/...).
+func (c Config) PublicURL(elem ...string) (string, error) {
+	public := *c.PublicHost
+	if public.Port() == "" {
+		_, port, err := net.SplitHostPort(c.Port)
+		if err != nil {
+			return "", fmt.Errorf("invalid PORT '%v': %w", c.Port, err)
+		}
+		public.Host = net.JoinHostPort(public.Hostname(), port)
 	}
+	return public.JoinPath(elem...).String(), nil
+}
 
+func getEnv(key, fallback string) string {
+	if value, ok := lookupEnv(key); ok && value != "" {
+		return value
+	}
 	return fallback
 }
 
+// the env file is already part of the process env, godotenv does not override the variables that are set.
+func lookupEnv(key string) (string, bool) {
+	if value, ok := os.LookupEnv(key); ok {
+		return value, true
+	}
+	value, ok := fileValues[key]
+	return value, ok
+}
+
 func loadEnvFile() error {
 	if isDocker() {
 		return nil
@@ -86,18 +241,10 @@ func loadEnvFile() error {
 	err := godotenv.Load()
 	if err != nil {
 		err = handleTestEnv()
-		if err != nil { 
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
 			return err
 		}
-		return nil
 	}
-	
-	envVariables, err := godotenv.Read()
-	if err != nil {
-		return err
-	}
-	
-	envs = envVariables
 	return nil
 }
 
@@ -110,18 +257,7 @@ func handleTestEnv() error {
 		return fmt.Errorf("failed to load .env.test file")
 	}
 	basepath := filepath.Dir(file)
-	err := godotenv.Load(filepath.Join(basepath, "../.env.test"))
-	if err != nil {
-		return err
-	}
-
-	envVariables, err := godotenv.Read(filepath.Join(basepath, "../.env.test"))
-	if err != nil {
-		return err
-	}
-
-	envs = envVariables
-	return err
+	return godotenv.Load(filepath.Join(basepath, "../.env.test"))
 }
 
 func isDocker() bool {
@@ -132,4 +268,4 @@ func isDocker() bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"bytes"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func validOverrides() Overrides {
+	return Overrides{
+		"JWT_SECRET":                        "secret",
+		"ACCESS_JWT_EXPIRATION_IN_SECONDS":  "900",
+		"REFRESH_JWT_EXPIRATION_IN_SECONDS": "2h",
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	content := "LOG_LEVEL: debug\nUSER_CACHE_SIZE: 5\nTRACES_SAMPLE_RATIO: 0.5\n"
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("USER_CACHE_SIZE", "50")
+
+	overrides := validOverrides()
+	overrides["TRACES_SAMPLE_RATIO"] = "0.25"
+	cfg, err := Load(LoadOptions{File: file, Overrides: overrides})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.LOG_LEVEL != "debug" {
+		t.Errorf("expected the level of the file, got %v", cfg.LOG_LEVEL)
+	}
+	if cfg.USER_CACHE_SIZE != 50 {
+		t.Errorf("expected the env to override the file, got %v", cfg.USER_CACHE_SIZE)
+	}
+	if cfg.TRACES_SAMPLE_RATIO != 0.25 {
+		t.Errorf("expected the flags to override the file, got %v", cfg.TRACES_SAMPLE_RATIO)
+	}
+	if cfg.ACCESS_JWT_EXPIRATION != 15*time.Minute || cfg.REFRESH_JWT_EXPIRATION != 2*time.Hour {
+		t.Errorf("expected the durations in seconds and as go durations, got %v and %v", cfg.ACCESS_JWT_EXPIRATION, cfg.REFRESH_JWT_EXPIRATION)
+	}
+}
+
+func TestLoadReportsEveryInvalidValue(t *testing.T) {
+	overrides := validOverrides()
+	overrides["USER_CACHE_SIZE"] = "many"
+	overrides["LOG_FORMAT"] = "xml"
+	overrides["PUBLIC_HOST"] = "localhost"
+	overrides["JWT_SECRET"] = ""
+
+	_, err := Load(LoadOptions{Overrides: overrides})
+	if err == nil {
+		t.Fatal("expected the invalid values to be reported")
+	}
+	for _, expected := range []string{"USER_CACHE_SIZE", "LOG_FORMAT", "PUBLIC_HOST", "JWT_SECRET is required"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected %v to be reported in: %v", expected, err)
+		}
+	}
+	if strings.Count(err.Error(), "USER_CACHE_SIZE") != 1 {
+		t.Errorf("expected a value that can not be parsed to be reported once: %v", err)
+	}
+}
+
+func TestPrintRedactsSecrets(t *testing.T) {
+	overrides := validOverrides()
+	overrides["METRICS_TOKEN"] = "metrics-token"
+	cfg, err := Load(LoadOptions{Overrides: overrides})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var output bytes.Buffer
+	if err := cfg.Print(&output); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(output.String(), "metrics-token") || !strings.Contains(output.String(), `METRICS_TOKEN: "[REDACTED]"`) {
+		t.Errorf("expected the secrets to be redacted:\n%v", output.String())
+	}
+	if !strings.Contains(output.String(), `ACCESS_JWT_EXPIRATION_IN_SECONDS: "900"`) {
+		t.Errorf("expected the durations to be printed in their unit:\n%v", output.String())
+	}
+
+	// the printed config is a valid config file
+	file := filepath.Join(t.TempDir(), "printed.yaml")
+	if err := os.WriteFile(file, output.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readConfigFile(file); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadTrustedProxies(t *testing.T) {
+	overrides := validOverrides()
+	overrides["TRUSTED_PROXIES"] = "10.0.0.0/8, 127.0.0.1"
+	cfg, err := Load(LoadOptions{Overrides: overrides})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.TRUSTED_PROXIES) != 2 || cfg.TRUSTED_PROXIES[1].String() != "127.0.0.1/32" {
+		t.Fatalf("expected a cidr and an ip, got %v", cfg.TRUSTED_PROXIES)
+	}
+
+	overrides["TRUSTED_PROXIES"] = "10.0.0.0/8,nginx"
+	if _, err := Load(LoadOptions{Overrides: overrides}); err == nil || !strings.Contains(err.Error(), "TRUSTED_PROXIES") {
+		t.Fatalf("expected a host name to be refused, got %v", err)
+	}
+}
+
+func TestPublicURL(t *testing.T) {
+	tests := []struct {
+		publicHost, port, expected string
+	}{
+		{"http://localhost", ":8080", "http://localhost:8080/static/images/"},
+		{"http://localhost/", "0.0.0.0:8080", "http://localhost:8080/static/images/"},
+		{"https://shop.example.com:443", ":8080", "https://shop.example.com:443/static/images/"},
+		{"http://[::1]", "[::]:9000", "http://[::1]:9000/static/images/"},
+	}
+	for _, test := range tests {
+		publicHost, err := url.Parse(test.publicHost)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg := Config{PublicHost: publicHost, Port: test.port}
+		publicURL, err := cfg.PublicURL("/static/images/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if publicURL != test.expected {
+			t.Errorf("expected %v for %v and %v, got %v", test.expected, test.publicHost, test.port, publicURL)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "[REDACTED]"
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	// the errors name the variables instead of the fields
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return field.Tag.Get("env")
+	})
+	return v
+}
+
+// Overrides are the values given by the flags, it is a flag.Value of "KEY=VALUE" pairs.
+type Overrides map[string]string
+
+func (o Overrides) String() string {
+	pairs := make([]string, 0, len(o))
+	for key, value := range o {
+		pairs = append(pairs, key+"="+value)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (o Overrides) Set(pair string) error {
+	key, value, ok := strings.Cut(pair, "=")
+	if !ok || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("expected KEY=VALUE, got '%v'", pair)
+	}
+	o[strings.TrimSpace(key)] = value
+	return nil
+}
+
+// sets the fields with an "env" tag from lookup or from their default, the values that can not be parsed are
+// returned by variable.
+func decode(cfg *Config, lookup func(key string) (string, bool)) map[string]error {
+	errs := map[string]error{}
+	value := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		key := field.Tag.Get("env")
+		if key == "" {
+			continue
+		}
+
+		raw, ok := lookup(key)
+		if !ok || raw == "" {
+			raw = field.Tag.Get("default")
+		}
+		if raw == "" {
+			continue
+		}
+
+		if err := setField(value.Field(i), field, strings.TrimSpace(raw)); err != nil {
+			errs[key] = fmt.Errorf("invalid %v: %w", key, err)
+		}
+	}
+	return errs
+}
+
+func setField(value reflect.Value, field reflect.StructField, raw string) error {
+	switch value.Interface().(type) {
+	case string:
+		value.SetString(raw)
+	case int:
+		number, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("expected an integer, got '%v'", raw)
+		}
+		value.SetInt(int64(number))
+	case bool:
+		boolean, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("expected a boolean, got '%v'", raw)
+		}
+		value.SetBool(boolean)
+	case float64:
+		number, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("expected a number, got '%v'", raw)
+		}
+		value.SetFloat(number)
+	case time.Duration:
+		duration, err := parseDuration(raw, field.Tag.Get("unit"))
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(duration))
+	case []netip.Prefix:
+		prefixes, err := parsePrefixes(raw)
+		if err != nil {
+			return err
+		}
+		value.Set(reflect.ValueOf(prefixes))
+	case *url.URL:
+		parsed, err := url.Parse(raw)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return fmt.Errorf("expected an absolute url, got '%v'", raw)
+		}
+		value.Set(reflect.ValueOf(parsed))
+	default:
+		return fmt.Errorf("unsupported type %v", field.Type)
+	}
+	return nil
+}
+
+// a number is a count of units, anything else must be a go duration ("15m", "1h30m").
+func parseDuration(raw, unit string) (time.Duration, error) {
+	if count, err := strconv.Atoi(raw); err == nil && unit != "" {
+		unitDuration, err := time.ParseDuration(unit)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(count) * unitDuration, nil
+	}
+
+	duration, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("expected a duration, got '%v'", raw)
+	}
+	return duration, nil
+}
+
+// a comma separated list of ips and cidrs ("10.0.0.0/8,127.0.0.1"), an ip is a prefix of its own.
+func parsePrefixes(raw string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0)
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if addr, err := netip.ParseAddr(part); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(part)
+		if err != nil {
+			return nil, fmt.Errorf("expected an ip or a cidr, got '%v'", part)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// checks the rules of the "validate" tags, every broken rule is reported in the returned error.
+func (c Config) Validate() error {
+	return c.validate(nil)
+}
+
+// the rules of the variables in skip are not checked, their value could not be parsed.
+func (c Config) validate(skip map[string]error) error {
+	err := validate.Struct(c)
+	if err == nil {
+		return nil
+	}
+
+	validationErrs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return err
+	}
+	errs := make([]error, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		if _, ok := skip[fieldErr.Field()]; ok {
+			continue
+		}
+		if fieldErr.Tag() == "required" {
+			errs = append(errs, fmt.Errorf("%v is required", fieldErr.Field()))
+			continue
+		}
+		rule := fieldErr.Tag()
+		if fieldErr.Param() != "" {
+			rule += "=" + fieldErr.Param()
+		}
+		errs = append(errs, fmt.Errorf("invalid %v: '%v' does not satisfy '%v'", fieldErr.Field(), fieldErr.Value(), rule))
+	}
+	return errors.Join(errs...)
+}
+
+// the keys of the file are the names of the variables, nested values are rejected.
+func readConfigFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the config file: %w", err)
+	}
+
+	var document map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(content, &document)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &document)
+	default:
+		return nil, fmt.Errorf("unsupported config file '%v', expected a .yaml, .yml or .json file", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the config file '%v': %w", path, err)
+	}
+
+	values := make(map[string]string, len(document))
+	for key, value := range document {
+		switch value.(type) {
+		case map[string]any, []any:
+			return nil, fmt.Errorf("the config file '%v' must only have scalar values, '%v' is not", path, key)
+		case nil:
+			values[key] = ""
+		default:
+			values[key] = fmt.Sprint(value)
+		}
+	}
+	return values, nil
+}
+
+// writes the config as a yaml config file, the secrets are redacted.
+func (c Config) Print(w io.Writer) error {
+	document := &yaml.Node{Kind: yaml.MappingNode}
+	value := reflect.ValueOf(c)
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		key := field.Tag.Get("env")
+		if key == "" {
+			continue
+		}
+
+		formatted := formatField(value.Field(i), field)
+		if field.Tag.Get("secret") == "true" && formatted != "" {
+			formatted = redacted
+		}
+		document.Content = append(document.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: key},
+			&yaml.Node{Kind: yaml.ScalarNode, Value: formatted, Style: yaml.DoubleQuotedStyle},
+		)
+	}
+
+	encoder := yaml.NewEncoder(w)
+	defer encoder.Close()
+	return encoder.Encode(document)
+}
+
+// the durations are written in their unit so the output can be read back.
+func formatField(value reflect.Value, field reflect.StructField) string {
+	switch typed := value.Interface().(type) {
+	case time.Duration:
+		unit, err := time.ParseDuration(field.Tag.Get("unit"))
+		if err == nil && typed%unit == 0 {
+			return strconv.FormatInt(int64(typed/unit), 10)
+		}
+		return typed.String()
+	case []netip.Prefix:
+		formatted := make([]string, 0, len(typed))
+		for _, prefix := range typed {
+			formatted = append(formatted, prefix.String())
+		}
+		return strings.Join(formatted, ",")
+	case *url.URL:
+		if typed == nil {
+			return ""
+		}
+		return typed.String()
+	default:
+		return fmt.Sprint(typed)
+	}
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"strings"
+)
+
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+}
+
+// the providers are listed in "OIDC_PROVIDERS" (e.g "google,microsoft") and each one is configured by
+// "OIDC_<NAME>_ISSUER", "OIDC_<NAME>_CLIENT_ID", "OIDC_<NAME>_CLIENT_SECRET" and optionally "OIDC_<NAME>_REDIRECT_URL",
+// providers with missing issuer or client id are skipped. Only the providers returning an id token are supported,
+// plain OAuth2 providers (e.g github) can't be used.
+func GetOIDCProviders() []OIDCProviderConfig {
+	providers := []OIDCProviderConfig{}
+	for _, name := range strings.Split(getEnv("OIDC_PROVIDERS", ""), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		envPrefix := "OIDC_" + strings.ToUpper(name) + "_"
+		// the PORT is validated by Load, the url can't fail.
+		callbackURL, _ := Envs.PublicURL("/api/v1/auth/oidc", name, "callback")
+		provider := OIDCProviderConfig{
+			Name:         name,
+			Issuer:       getEnv(envPrefix+"ISSUER", ""),
+			ClientID:     getEnv(envPrefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(envPrefix+"CLIENT_SECRET", ""),
+			RedirectURL:  getEnv(envPrefix+"REDIRECT_URL", callbackURL),
+		}
+		if provider.Issuer == "" || provider.ClientID == "" {
+			continue
+		}
+
+		providers = append(providers, provider)
+	}
+
+	return providers
+}
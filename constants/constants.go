@@ -7,15 +7,17 @@ const (
 	TokenPayload = types.TokenKey("TokenPayload")
 	UserKey = types.UserKey("UserKey")
 	ResourceKey = types.AuthorizedResource("AuthorizedResource")
+	AuthSourceKey = types.AuthSourceKey("AuthSource")
+	PrincipalKey = types.PrincipalKey("Principal")
 )
 
 // This contains the columns that can be changed by the user, it's used for create and update processes
 //
 // It's set as var because golang do not allow slices as constant
 var (
-	ProductCols = []string{"Name","Quantity","Description","CategoryID","Price"}
+	ProductCols = []string{"Name","Quantity","Description","CategoryID","Price","SKU","Barcode"}
 	CategoryCols = []string{"Name"}
-	ImageCols = []string{"ProductID","ImageUrl","IsMain","ImagePublicId"}
+	ImageCols = []string{"ProductID","ImageUrl","IsMain","ImagePublicId","Variants","Position","AltText"}
 	IdUrlPathKey = "id"
 	CommentCreateCols = []string{"Comment","Rate", "UserID", "ProductID"}
 	CommentUpdateCols = []string{"Comment","Rate"}
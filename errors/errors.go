@@ -13,6 +13,9 @@ var (
 	ErrForbidden = errors.New("forbidden")
 
 	ErrInvalidToken = errors.New("invalid token")
+	ErrInvalidCSRFToken = errors.New("invalid or missing csrf token")
+	ErrInvalidOIDCState = errors.New("invalid or expired login state, please try again")
+	ErrNotAllowedWhileImpersonating = errors.New("this action is not allowed while impersonating a user")
 
 	ErrNoFileFound = errors.New("no file was found")
 	ErrUnexpectedDuringImageUpload = errors.New("an error has occurred during uploading image")
@@ -1,59 +1,96 @@
 package database
 
 import (
-	"database/sql"
 	"fmt"
+	"time"
 
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"main.go/config"
+	"main.go/internal/database/dialect"
+	"main.go/internal/database/migrations"
 	"main.go/pkg/models"
+	"main.go/pkg/tracing"
 )
 
-var DB *gorm.DB = InitDB()
+// creates the database when it does not exist and connects to it, the schema is not checked, see EnsureMigrated.
+func Open(cfg config.Config) (*gorm.DB, error) {
+	dbDialect, err := dialect.Get(cfg.DB_DIALECT)
+	if err != nil {
+		return nil, err
+	}
 
-func InitDB() *gorm.DB {
-	Envs := config.Envs
-	config := &gorm.Config{}
+	err = dbDialect.CreateDatabase(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the database: %w", err)
+	}
 
-	// adding queries logger
-	if Envs.Env == "development" {
-		config.Logger = SQLLogger
+	DB, err := dialect.Open(dbDialect, cfg.DSN, &gorm.Config{
+		Logger: NewSQLLogger(time.Second),
+	})
+	if err != nil {
+		return nil, err
 	}
-	dsnNoDB := Envs.DSN_NO_DB
-	dbName := Envs.DBName
 
-	db, err := sql.Open("mysql", dsnNoDB)
+	if err := DB.Use(tracing.NewGormPlugin(dbDialect.Name())); err != nil {
+		return nil, err
+	}
+
+	err = DB.SetupJoinTable(&models.User{}, "Roles", &models.UserRoles{})
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	createDbIfNotExistQ := fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s", dbName)
-	_, err = db.Exec(createDbIfNotExistQ)
+	err = DB.SetupJoinTable(&models.Role{}, "Permissions", &models.RolePermissions{})
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	defer db.Close()
-
-	DB, err := gorm.Open(mysql.Open(Envs.DSN), config)
+	// the other side of the relations, otherwise the associations through them miss the "assigned_at" column
+	err = DB.SetupJoinTable(&models.Role{}, "Users", &models.UserRoles{})
+	if err != nil {
+		return nil, err
+	}
+	err = DB.SetupJoinTable(&models.Permission{}, "Roles", &models.RolePermissions{})
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	err = DB.AutoMigrate(
-		&models.Category{}, &models.Product{}, &models.User{},
-		&models.CartItem{}, &models.Review{}, &models.Order{},
-		&models.OrderItem{}, &models.Image{}, &models.Role{},
-		&models.UserRoles{}, &models.Address{}, &models.Message{},
-	)
+	return DB, nil
+}
+
+// fails when a migration is pending, the schema is changed by "cmd/migrate" only so the api refuses to start
+// on a schema it was not built for.
+func EnsureMigrated(DB *gorm.DB) error {
+	migrator, err := migrations.NewMigrator(DB, migrations.All())
 	if err != nil {
-		panic(err)
+		return err
 	}
-	err = DB.SetupJoinTable(&models.User{}, "Roles", &models.UserRoles{})
+	pending, err := migrator.Pending()
 	if err != nil {
-		panic(err)
+		return err
+	}
+	if len(pending) != 0 {
+		return fmt.Errorf("the database has %d pending migrations starting at %v, run \"make migrate\" first", len(pending), pending[0])
+	}
+
+	migrated.Store(true)
+	return nil
+}
+
+// opens the database of the api, checks its schema and seeds the roles and the permissions.
+func Init(cfg config.Config) (*gorm.DB, error) {
+	DB, err := Open(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := EnsureMigrated(DB); err != nil {
+		Close(DB)
+		return nil, err
+	}
+
+	if err := SeedData(DB); err != nil {
+		Close(DB)
+		return nil, fmt.Errorf("failed to seed the roles and permissions: %w", err)
 	}
 
-	SeedData(DB)
-	
-	return DB
-}
\ No newline at end of file
+	return DB, nil
+}
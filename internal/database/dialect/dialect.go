@@ -0,0 +1,63 @@
+// Package dialect holds what differs between the supported databases (mysql, postgres and sqlite), the stores only
+// use gorm and portable sql so they run on any of them. The drivers errors are translated by gorm
+// (gorm.ErrDuplicatedKey, gorm.ErrForeignKeyViolated) since the connections are opened with TranslateError.
+package dialect
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"main.go/config"
+)
+
+const (
+	MySQL    = "mysql"
+	Postgres = "postgres"
+	SQLite   = "sqlite"
+)
+
+type Dialect interface {
+	// the value of DB_DIALECT and of the "db.system" attribute of the query spans.
+	Name() string
+	Dialector(dsn string) gorm.Dialector
+	// creates the database of the config when it does not exist, sqlite creates its file when it is opened.
+	CreateDatabase(cfg config.Config) error
+	// runs fn on a single connection with the foreign keys checks disabled, so the rows of a backup can be
+	// inserted in any order. The checks are session settings, that is why fn must only use the given connection.
+	WithoutForeignKeys(DB *gorm.DB, fn func(conn *gorm.DB) error) error
+	// moves the id sequence of the table after its greatest id, the rows restored with their ids do not advance it
+	// in postgres. mysql and sqlite already continue after the greatest id.
+	SyncSequence(DB *gorm.DB, table string) error
+}
+
+var dialects = map[string]Dialect{
+	MySQL:    mysqlDialect{},
+	Postgres: postgresDialect{},
+	SQLite:   sqliteDialect{},
+}
+
+func Get(name string) (Dialect, error) {
+	dialect, ok := dialects[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, fmt.Errorf("unknown database dialect '%v', expected one of: mysql, postgres, sqlite", name)
+	}
+	return dialect, nil
+}
+
+// opens the database with the options shared by every dialect.
+func Open(dialect Dialect, dsn string, gormConfig *gorm.Config) (*gorm.DB, error) {
+	gormConfig.TranslateError = true
+	return gorm.Open(dialect.Dialector(dsn), gormConfig)
+}
+
+// reports whether the error was returned by one of the database drivers, the errors that were not translated by gorm
+// (syntax, constraints checks, connection) are not shown to the clients in production.
+func IsDriverError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	var pgErr *pgconn.PgError
+	return errors.As(err, &mysqlErr) || errors.As(err, &pgErr) || isSQLiteError(err)
+}
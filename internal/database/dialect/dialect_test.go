@@ -0,0 +1,91 @@
+package dialect
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"main.go/config"
+)
+
+type owner struct {
+	ID    uint
+	Email string `gorm:"uniqueIndex"`
+}
+
+type pet struct {
+	ID      uint
+	OwnerID uint
+	Owner   owner
+}
+
+func openSQLite(t *testing.T) (Dialect, *gorm.DB) {
+	t.Helper()
+	dbDialect, err := Get("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dsn := config.BuildDSN(config.DSNOptions{Dialect: SQLite, Name: t.TempDir() + "/test.db"})
+	DB, err := Open(dbDialect, dsn, &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := DB.AutoMigrate(&owner{}, &pet{}); err != nil {
+		t.Fatal(err)
+	}
+	return dbDialect, DB
+}
+
+func TestGetUnknownDialect(t *testing.T) {
+	if _, err := Get("oracle"); err == nil {
+		t.Fatal("expected an error for an unknown dialect")
+	}
+}
+
+func TestErrorsAreTranslated(t *testing.T) {
+	_, DB := openSQLite(t)
+
+	if err := DB.Create(&owner{Email: "a@test.com"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	err := DB.Create(&owner{Email: "a@test.com"}).Error
+	if !errors.Is(err, gorm.ErrDuplicatedKey) {
+		t.Fatalf("expected a duplicated key error, got %v", err)
+	}
+
+	err = DB.Create(&pet{OwnerID: 999}).Error
+	if !errors.Is(err, gorm.ErrForeignKeyViolated) {
+		t.Fatalf("expected a foreign key error, got %v", err)
+	}
+}
+
+func TestIsDriverError(t *testing.T) {
+	_, DB := openSQLite(t)
+
+	err := DB.Exec("SELECT * FROM missing_table").Error
+	if !IsDriverError(err) {
+		t.Fatalf("expected a driver error, got %v", err)
+	}
+	if IsDriverError(errors.New("not a driver error")) {
+		t.Fatal("expected a plain error not to be a driver error")
+	}
+}
+
+func TestWithoutForeignKeys(t *testing.T) {
+	dbDialect, DB := openSQLite(t)
+
+	err := dbDialect.WithoutForeignKeys(DB, func(conn *gorm.DB) error {
+		return conn.Create(&pet{ID: 1, OwnerID: 7}).Error
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the checks are enabled again after
+	err = DB.Create(&pet{OwnerID: 8}).Error
+	if !errors.Is(err, gorm.ErrForeignKeyViolated) {
+		t.Fatalf("expected a foreign key error, got %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package dialect
+
+import (
+	"database/sql"
+	"fmt"
+
+	gormmysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"main.go/config"
+)
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string {
+	return MySQL
+}
+
+func (mysqlDialect) Dialector(dsn string) gorm.Dialector {
+	return gormmysql.Open(dsn)
+}
+
+func (mysqlDialect) CreateDatabase(cfg config.Config) error {
+	db, err := sql.Open("mysql", cfg.DSN_NO_DB)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	_, err = db.Exec(fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s`", cfg.DBName))
+	return err
+}
+
+func (mysqlDialect) WithoutForeignKeys(DB *gorm.DB, fn func(conn *gorm.DB) error) error {
+	return DB.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SET FOREIGN_KEY_CHECKS = 0").Error; err != nil {
+			return err
+		}
+		defer conn.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+		return fn(conn)
+	})
+}
+
+func (mysqlDialect) SyncSequence(DB *gorm.DB, table string) error {
+	return nil
+}
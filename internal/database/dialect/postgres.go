@@ -0,0 +1,68 @@
+package dialect
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"main.go/config"
+)
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string {
+	return Postgres
+}
+
+func (postgresDialect) Dialector(dsn string) gorm.Dialector {
+	return postgres.Open(dsn)
+}
+
+// postgres has no "CREATE DATABASE IF NOT EXISTS", the catalog is checked first.
+func (postgresDialect) CreateDatabase(cfg config.Config) error {
+	DB, err := gorm.Open(postgres.Open(cfg.DSN_NO_DB), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		return err
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	var count int64
+	err = DB.Raw("SELECT COUNT(*) FROM pg_database WHERE datname = ?", cfg.DBName).Scan(&count).Error
+	if err != nil || count != 0 {
+		return err
+	}
+
+	return DB.Exec(fmt.Sprintf(`CREATE DATABASE "%s"`, strings.ReplaceAll(cfg.DBName, `"`, `""`))).Error
+}
+
+// the foreign keys triggers are skipped by the "replica" role, it requires a superuser.
+func (postgresDialect) WithoutForeignKeys(DB *gorm.DB, fn func(conn *gorm.DB) error) error {
+	return DB.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SET session_replication_role = replica").Error; err != nil {
+			return err
+		}
+		defer conn.Exec("SET session_replication_role = DEFAULT")
+
+		return fn(conn)
+	})
+}
+
+func (postgresDialect) SyncSequence(DB *gorm.DB, table string) error {
+	if !DB.Migrator().HasColumn(table, "id") {
+		return nil
+	}
+
+	var sequence *string
+	if err := DB.Raw("SELECT pg_get_serial_sequence(?, 'id')", table).Scan(&sequence).Error; err != nil || sequence == nil {
+		return err
+	}
+
+	return DB.Exec(fmt.Sprintf(`SELECT setval(?, COALESCE((SELECT MAX(id) FROM "%s"), 0) + 1, false)`,
+		strings.ReplaceAll(table, `"`, `""`)), *sequence).Error
+}
@@ -0,0 +1,44 @@
+package dialect
+
+import (
+	"errors"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"main.go/config"
+)
+
+// the driver needs cgo, the binaries must be built with CGO_ENABLED=1 to use it.
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string {
+	return SQLite
+}
+
+func (sqliteDialect) Dialector(dsn string) gorm.Dialector {
+	return sqlite.Open(dsn)
+}
+
+func (sqliteDialect) CreateDatabase(cfg config.Config) error {
+	return nil
+}
+
+// the pragma is ignored inside a transaction so it is set on the connection, the default transactions of gorm are
+// skipped too otherwise the pragma is not enabled again on the connection returned to the pool.
+func (sqliteDialect) WithoutForeignKeys(DB *gorm.DB, fn func(conn *gorm.DB) error) error {
+	return DB.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("PRAGMA foreign_keys = OFF").Error; err != nil {
+			return err
+		}
+
+		err := fn(conn.Session(&gorm.Session{SkipDefaultTransaction: true}))
+		if enableErr := conn.Exec("PRAGMA foreign_keys = ON").Error; enableErr != nil {
+			return errors.Join(err, enableErr)
+		}
+		return err
+	})
+}
+
+func (sqliteDialect) SyncSequence(DB *gorm.DB, table string) error {
+	return nil
+}
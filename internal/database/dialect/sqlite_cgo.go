@@ -0,0 +1,14 @@
+//go:build cgo
+
+package dialect
+
+import (
+	"errors"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+func isSQLiteError(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr)
+}
@@ -0,0 +1,8 @@
+//go:build !cgo
+
+package dialect
+
+// the sqlite driver is not built without cgo, opening a sqlite database fails so there is no sqlite error.
+func isSQLiteError(err error) bool {
+	return false
+}
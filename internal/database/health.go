@@ -0,0 +1,33 @@
+package database
+
+import (
+	"context"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+// set once the schema was checked to have no pending migration, the readiness check fails until then.
+var migrated atomic.Bool
+
+func IsMigrated() bool {
+	return migrated.Load()
+}
+
+func Ping(ctx context.Context, DB *gorm.DB) error {
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.PingContext(ctx)
+}
+
+func Close(DB *gorm.DB) error {
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.Close()
+}
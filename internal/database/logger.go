@@ -1,20 +1,78 @@
 package database
 
 import (
-	"log"
-	"os"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"time"
 
+	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
-var SQLLogger = logger.New(
-	log.New(os.Stdout, "\r\n", log.LstdFlags),
-	logger.Config{
-		SlowThreshold:             time.Second,
-		LogLevel:                  logger.Info,
-		IgnoreRecordNotFoundError: true,          
-		ParameterizedQueries:      true,          
-		Colorful:                  true,         
-	},
-)
\ No newline at end of file
+// logs the queries through slog so they are written in the same format as the rest of the logs, the queries made with
+// "DB.WithContext(r.Context())" include the id of the request that made them. The handlers query through
+// "h.store.WithContext(r.Context())", the stores are rebuilt on the request bound DB.
+//
+// every query is logged at the debug level, slow queries at the warn level and failed queries at the error level.
+type SQLLogger struct {
+	SlowThreshold time.Duration
+	level         logger.LogLevel
+}
+
+func NewSQLLogger(slowThreshold time.Duration) *SQLLogger {
+	return &SQLLogger{
+		SlowThreshold: slowThreshold,
+		level:         logger.Info,
+	}
+}
+
+func (l *SQLLogger) LogMode(level logger.LogLevel) logger.Interface {
+	newLogger := *l
+	newLogger.level = level
+	return &newLogger
+}
+
+func (l *SQLLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Info {
+		slog.InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *SQLLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Warn {
+		slog.WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *SQLLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Error {
+		slog.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *SQLLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	attrs := func() []slog.Attr {
+		sql, rows := fc()
+		return []slog.Attr{
+			slog.String("sql", sql),
+			slog.Int64("rows", rows),
+			slog.Float64("durationMs", float64(elapsed.Microseconds())/1000),
+		}
+	}
+
+	switch {
+	case err != nil && l.level >= logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		slog.LogAttrs(ctx, slog.LevelError, "query failed", append(attrs(), slog.String("error", err.Error()))...)
+	case l.SlowThreshold != 0 && elapsed > l.SlowThreshold && l.level >= logger.Warn:
+		slog.LogAttrs(ctx, slog.LevelWarn, "slow query", attrs()...)
+	case l.level >= logger.Info && slog.Default().Enabled(ctx, slog.LevelDebug):
+		slog.LogAttrs(ctx, slog.LevelDebug, "query", attrs()...)
+	}
+}
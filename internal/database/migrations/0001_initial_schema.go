@@ -0,0 +1,68 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+	"main.go/internal/database/migrations/baseline"
+)
+
+// the tables created by AutoMigrate on start before the versioned migrations, on an existing database it only
+// records the baseline since every table is already there. The tables are created from the frozen copies of the
+// baseline package, not from the models, so the later columns and indexes are only added by their own migrations.
+func init() {
+	register(Migration{
+		Version: 1,
+		Name:    "initial_schema",
+		Up: func(tx *gorm.DB) error {
+			// both sides of the relations, otherwise the join tables can be created from the side without a join model
+			joinTables := []struct {
+				model     any
+				field     string
+				joinTable any
+			}{
+				{&baseline.User{}, "Roles", &baseline.UserRoles{}},
+				{&baseline.Role{}, "Users", &baseline.UserRoles{}},
+				{&baseline.Role{}, "Permissions", &baseline.RolePermissions{}},
+				{&baseline.Permission{}, "Roles", &baseline.RolePermissions{}},
+			}
+			for _, joinTable := range joinTables {
+				if err := tx.SetupJoinTable(joinTable.model, joinTable.field, joinTable.joinTable); err != nil {
+					return err
+				}
+			}
+
+			missing := []any{}
+			for _, table := range initialSchemaTables() {
+				if !tx.Migrator().HasTable(table) {
+					missing = append(missing, table)
+				}
+			}
+			if len(missing) == 0 {
+				return nil
+			}
+			return tx.AutoMigrate(missing...)
+		},
+		Down: func(tx *gorm.DB) error {
+			tables := initialSchemaTables()
+			for i := len(tables) - 1; i >= 0; i-- {
+				if err := tx.Migrator().DropTable(tables[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
+
+// ordered so every table comes after the tables it references.
+func initialSchemaTables() []any {
+	return []any{
+		&baseline.Category{}, &baseline.Product{}, &baseline.User{},
+		&baseline.CartItem{}, &baseline.Review{}, &baseline.Order{},
+		&baseline.OrderItem{}, &baseline.Image{}, &baseline.Role{},
+		&baseline.UserRoles{}, &baseline.Address{}, &baseline.Message{},
+		&baseline.ApiKey{}, &baseline.Permission{}, &baseline.RolePermissions{},
+		&baseline.AccountLockout{}, &baseline.Identity{}, &baseline.AccountDeletion{},
+		&baseline.ImpersonationSession{}, &baseline.ImpersonationRequest{},
+		&baseline.AuditLog{},
+	}
+}
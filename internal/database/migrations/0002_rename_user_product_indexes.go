@@ -0,0 +1,46 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// the cart items and the reviews shared the "idx_user_product" index name, the names of the indexes are global in
+// postgres and sqlite so they are prefixed by their table. The databases created after the rename already have them.
+func init() {
+	// the tables are named so the migration does not depend on the models
+	renames := []struct {
+		table   string
+		oldName string
+		newName string
+	}{
+		{"cart_items", "idx_user_product", "idx_cart_items_user_product"},
+		{"reviews", "idx_user_product", "idx_reviews_user_product"},
+	}
+
+	register(Migration{
+		Version: 2,
+		Name:    "rename_user_product_indexes",
+		Up: func(tx *gorm.DB) error {
+			for _, rename := range renames {
+				if tx.Migrator().HasIndex(rename.table, rename.oldName) {
+					if err := tx.Migrator().RenameIndex(rename.table, rename.oldName, rename.newName); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			if tx.Dialector.Name() != "mysql" {
+				// the old names can not coexist outside mysql
+				return nil
+			}
+			for _, rename := range renames {
+				if tx.Migrator().HasIndex(rename.table, rename.newName) {
+					if err := tx.Migrator().RenameIndex(rename.table, rename.newName, rename.oldName); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	})
+}
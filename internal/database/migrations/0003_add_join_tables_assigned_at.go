@@ -0,0 +1,56 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// the join tables are created from the other side of the relation (permissions, roles) on sqlite and postgres, the
+// default join table has no "assigned_at" column. The mysql databases already have it, the rollback drops it on
+// every database.
+func init() {
+	joinTables := []any{&userRolesAssignedAt{}, &rolePermissionsAssignedAt{}}
+
+	register(Migration{
+		Version: 3,
+		Name:    "add_join_tables_assigned_at",
+		Up: func(tx *gorm.DB) error {
+			for _, joinTable := range joinTables {
+				if !tx.Migrator().HasColumn(joinTable, "AssignedAt") {
+					if err := tx.Migrator().AddColumn(joinTable, "AssignedAt"); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, joinTable := range joinTables {
+				if tx.Migrator().HasColumn(joinTable, "AssignedAt") {
+					if err := tx.Migrator().DropColumn(joinTable, "AssignedAt"); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	})
+}
+
+// the column as it was added to both join tables.
+type userRolesAssignedAt struct {
+	AssignedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (userRolesAssignedAt) TableName() string {
+	return "user_roles"
+}
+
+type rolePermissionsAssignedAt struct {
+	AssignedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (rolePermissionsAssignedAt) TableName() string {
+	return "role_permissions"
+}
@@ -0,0 +1,58 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// the resized copies of the product images, the images uploaded before have none.
+func init() {
+	register(Migration{
+		Version: 4,
+		Name:    "add_image_variants",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&imageVariants{}, "Variants") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&imageVariants{}, "Variants")
+		},
+		Down: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&imageVariants{}, "Variants") {
+				return nil
+			}
+			if err := tx.Migrator().DropColumn(&imageVariants{}, "Variants"); err != nil {
+				return err
+			}
+			return createImagesIndexes(tx)
+		},
+	})
+}
+
+// the column as it was added, the variants are a json object.
+type imageVariants struct {
+	Variants map[string]any `gorm:"serializer:json;type:text"`
+}
+
+func (imageVariants) TableName() string {
+	return "images"
+}
+
+// the indexes of the images table.
+type imageIndexes struct {
+	DeletedAt *gorm.DeletedAt `gorm:"index"`
+	ProductID uint            `gorm:"index"`
+}
+
+func (imageIndexes) TableName() string {
+	return "images"
+}
+
+// sqlite recreates the table to drop a column, which drops its indexes.
+func createImagesIndexes(tx *gorm.DB) error {
+	for _, column := range []string{"DeletedAt", "ProductID"} {
+		if tx.Migrator().HasIndex(&imageIndexes{}, column) {
+			continue
+		}
+		if err := tx.Migrator().CreateIndex(&imageIndexes{}, column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// the outbox of the image storage deletions.
+func init() {
+	register(Migration{
+		Version: 5,
+		Name:    "create_storage_deletions",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasTable(&storageDeletion{}) {
+				return nil
+			}
+			return tx.Migrator().CreateTable(&storageDeletion{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&storageDeletion{})
+		},
+	})
+}
+
+// the table as it was created.
+type storageDeletion struct {
+	ID            uint `gorm:"primarykey;autoIncrement"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	PublicId      string    `gorm:"not null;size:256"`
+	Attempts      int       `gorm:"not null;default:0"`
+	LastError     *string   `gorm:"size:512"`
+	NextAttemptAt time.Time `gorm:"not null;index"`
+}
+
+func (storageDeletion) TableName() string {
+	return "storage_deletions"
+}
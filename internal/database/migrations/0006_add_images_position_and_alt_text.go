@@ -0,0 +1,65 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// the existing images are ordered by product with the main image first then by their creation.
+func init() {
+	columns := []string{"Position", "AltText"}
+
+	register(Migration{
+		Version: 6,
+		Name:    "add_images_position_and_alt_text",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&imagePosition{}, "Position") {
+				return nil
+			}
+			for _, column := range columns {
+				if err := tx.Migrator().AddColumn(&imagePosition{}, column); err != nil {
+					return err
+				}
+			}
+
+			var images []imagePosition
+			err := tx.Unscoped().Select("id", "product_id").Order("product_id, is_main DESC, id").Find(&images).Error
+			if err != nil {
+				return err
+			}
+			position, productId := 0, uint(0)
+			for _, image := range images {
+				if image.ProductID != productId {
+					position, productId = 0, image.ProductID
+				}
+				if position > 0 {
+					err := tx.Unscoped().Model(&imagePosition{}).Where("id = ?", image.ID).Update("position", position).Error
+					if err != nil {
+						return err
+					}
+				}
+				position++
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, column := range columns {
+				if tx.Migrator().HasColumn(&imagePosition{}, column) {
+					if err := tx.Migrator().DropColumn(&imagePosition{}, column); err != nil {
+						return err
+					}
+				}
+			}
+			return createImagesIndexes(tx)
+		},
+	})
+}
+
+// the columns as they were added, with the columns the existing images are ordered by.
+type imagePosition struct {
+	ID        uint
+	ProductID uint
+	Position  int    `gorm:"not null;default:0"`
+	AltText   string `gorm:"not null;size:256;default:''"`
+}
+
+func (imagePosition) TableName() string {
+	return "images"
+}
@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// the direct uploads of the product images waiting for their confirmation.
+func init() {
+	register(Migration{
+		Version: 7,
+		Name:    "create_pending_uploads",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasTable(&pendingUpload{}) {
+				return nil
+			}
+			return tx.Migrator().CreateTable(&pendingUpload{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&pendingUpload{})
+		},
+	})
+}
+
+// the table as it was created.
+type pendingUpload struct {
+	ID            uint `gorm:"primarykey;autoIncrement"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	ProductID     uint      `gorm:"not null;index"`
+	PublicId      string    `gorm:"not null;size:256;uniqueIndex"`
+	ConfirmBefore time.Time `gorm:"not null;index"`
+}
+
+func (pendingUpload) TableName() string {
+	return "pending_uploads"
+}
@@ -0,0 +1,57 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// the sku matches the products of the csv import, the existing products have none until the migration 11 gives them
+// one and makes the column required.
+func init() {
+	register(Migration{
+		Version: 8,
+		Name:    "add_products_sku",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&productSKU{}, "SKU") {
+				return nil
+			}
+			if err := tx.Migrator().AddColumn(&productSKU{}, "SKU"); err != nil {
+				return err
+			}
+			return tx.Migrator().CreateIndex(&productSKU{}, "SKU")
+		},
+		Down: func(tx *gorm.DB) error {
+			if tx.Migrator().HasIndex(&productSKU{}, "SKU") {
+				if err := tx.Migrator().DropIndex(&productSKU{}, "SKU"); err != nil {
+					return err
+				}
+			}
+			if tx.Migrator().HasColumn(&productSKU{}, "SKU") {
+				if err := tx.Migrator().DropColumn(&productSKU{}, "SKU"); err != nil {
+					return err
+				}
+			}
+			return createProductsSKUIndexes(tx, "DeletedAt")
+		},
+	})
+}
+
+// the optional column as it was added, with the index of the deleted products sqlite drops with the table.
+type productSKU struct {
+	DeletedAt *gorm.DeletedAt `gorm:"index"`
+	SKU       *string         `gorm:"size:64;uniqueIndex"`
+}
+
+func (productSKU) TableName() string {
+	return "products"
+}
+
+// sqlite recreates the table to drop a column, which drops its indexes.
+func createProductsSKUIndexes(tx *gorm.DB, columns ...string) error {
+	for _, column := range columns {
+		if tx.Migrator().HasIndex(&productSKU{}, column) {
+			continue
+		}
+		if err := tx.Migrator().CreateIndex(&productSKU{}, column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package migrations
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"main.go/pkg/utils"
+)
+
+// the slugs of the existing products are derived from their names, a number is appended to the repeated names.
+func init() {
+	register(Migration{
+		Version: 9,
+		Name:    "add_products_barcode_and_slug",
+		Up: func(tx *gorm.DB) error {
+			if err := addProductsBarcodeAndSlug(tx); err != nil {
+				return err
+			}
+			return tx.Migrator().CreateTable(&productSlug{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&productSlug{}); err != nil {
+				return err
+			}
+			for _, column := range []string{"Barcode", "Slug"} {
+				if tx.Migrator().HasIndex(&productBarcodeAndSlug{}, column) {
+					if err := tx.Migrator().DropIndex(&productBarcodeAndSlug{}, column); err != nil {
+						return err
+					}
+				}
+				if tx.Migrator().HasColumn(&productBarcodeAndSlug{}, column) {
+					if err := tx.Migrator().DropColumn(&productBarcodeAndSlug{}, column); err != nil {
+						return err
+					}
+				}
+			}
+			return createProductsSKUIndexes(tx, "DeletedAt", "SKU")
+		},
+	})
+}
+
+// the slugs are unique once every product has one, so the index is created after them.
+func addProductsBarcodeAndSlug(tx *gorm.DB) error {
+	for _, column := range []string{"Barcode", "Slug"} {
+		if err := tx.Migrator().AddColumn(&productBarcodeAndSlug{}, column); err != nil {
+			return err
+		}
+	}
+
+	var products []productBarcodeAndSlug
+	err := tx.Unscoped().Select("id", "name").Order("id").Find(&products).Error
+	if err != nil {
+		return err
+	}
+	taken := make(map[string]bool, len(products))
+	for _, product := range products {
+		base := utils.Slugify(product.Name)
+		if base == "" {
+			base = "product"
+		}
+		slug := base
+		for i := 2; taken[slug]; i++ {
+			slug = fmt.Sprintf("%s-%d", base, i)
+		}
+		taken[slug] = true
+
+		err := tx.Unscoped().Model(&productBarcodeAndSlug{}).Where("id = ?", product.ID).Update("slug", slug).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, column := range []string{"Barcode", "Slug"} {
+		if err := tx.Migrator().CreateIndex(&productBarcodeAndSlug{}, column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// the columns as they were added, with the name the slugs are derived from.
+type productBarcodeAndSlug struct {
+	ID      uint
+	Name    string
+	Barcode *string `gorm:"size:13;uniqueIndex"`
+	Slug    string  `gorm:"size:96;not null;default:'';uniqueIndex"`
+}
+
+func (productBarcodeAndSlug) TableName() string {
+	return "products"
+}
+
+// the table as it was created.
+type productSlug struct {
+	ID        uint `gorm:"primarykey;autoIncrement"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	ProductID uint                   `gorm:"not null;index"`
+	Product   *productBarcodeAndSlug `gorm:"constraint:OnDelete:CASCADE"`
+	Slug      string                 `gorm:"size:96;not null;uniqueIndex"`
+}
+
+func (productSlug) TableName() string {
+	return "product_slugs"
+}
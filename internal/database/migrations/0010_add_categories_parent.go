@@ -0,0 +1,84 @@
+package migrations
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// the existing categories become roots, their path is their own id.
+func init() {
+	register(Migration{
+		Version: 10,
+		Name:    "add_categories_parent",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range []string{"ParentID", "Path"} {
+				if err := tx.Migrator().AddColumn(&categoryParent{}, column); err != nil {
+					return err
+				}
+			}
+
+			var ids []uint
+			if err := tx.Unscoped().Model(&categoryParent{}).Order("id").Pluck("id", &ids).Error; err != nil {
+				return err
+			}
+			for _, id := range ids {
+				err := tx.Unscoped().Model(&categoryParent{}).Where("id = ?", id).Update("path", fmt.Sprintf("%d/", id)).Error
+				if err != nil {
+					return err
+				}
+			}
+
+			if err := tx.Migrator().CreateConstraint(&categoryParent{}, "Children"); err != nil {
+				return err
+			}
+			return createCategoriesIndexes(tx, "ParentID", "Path", "DeletedAt")
+		},
+		Down: func(tx *gorm.DB) error {
+			if tx.Migrator().HasConstraint(&categoryParent{}, "Children") {
+				if err := tx.Migrator().DropConstraint(&categoryParent{}, "Children"); err != nil {
+					return err
+				}
+			}
+			for _, column := range []string{"ParentID", "Path"} {
+				if tx.Migrator().HasIndex(&categoryParent{}, column) {
+					if err := tx.Migrator().DropIndex(&categoryParent{}, column); err != nil {
+						return err
+					}
+				}
+				if tx.Migrator().HasColumn(&categoryParent{}, column) {
+					if err := tx.Migrator().DropColumn(&categoryParent{}, column); err != nil {
+						return err
+					}
+				}
+			}
+			return createCategoriesIndexes(tx, "DeletedAt")
+		},
+	})
+}
+
+// sqlite recreates the table to add or drop a constraint or a column, which drops its indexes.
+func createCategoriesIndexes(tx *gorm.DB, columns ...string) error {
+	for _, column := range columns {
+		if tx.Migrator().HasIndex(&categoryParent{}, column) {
+			continue
+		}
+		if err := tx.Migrator().CreateIndex(&categoryParent{}, column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// the columns as they were added, with the index of the deleted categories sqlite drops with the table.
+type categoryParent struct {
+	ID        uint
+	DeletedAt *gorm.DeletedAt  `gorm:"index"`
+	ParentID  *uint            `gorm:"index"`
+	Path      string           `gorm:"not null;size:255;default:'';index"`
+	Children  []categoryParent `gorm:"foreignKey:ParentID;constraint:OnDelete:RESTRICT"`
+}
+
+func (categoryParent) TableName() string {
+	return "categories"
+}
@@ -0,0 +1,88 @@
+package migrations
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// the products without a sku get "PRODUCT-<id>" before the column becomes required, the down migration keeps them.
+func init() {
+	register(Migration{
+		Version: 11,
+		Name:    "require_products_sku",
+		Up: func(tx *gorm.DB) error {
+			if err := backfillProductsSKU(tx); err != nil {
+				return err
+			}
+			if err := tx.Migrator().AlterColumn(&productRequiredSKU{}, "SKU"); err != nil {
+				return err
+			}
+			return createProductsIndexes(tx)
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().AlterColumn(&productSKU{}, "SKU"); err != nil {
+				return err
+			}
+			return createProductsIndexes(tx)
+		},
+	})
+}
+
+func backfillProductsSKU(tx *gorm.DB) error {
+	var skus []string
+	err := tx.Unscoped().Model(&productRequiredSKU{}).Where("sku IS NOT NULL").Pluck("sku", &skus).Error
+	if err != nil {
+		return err
+	}
+	taken := make(map[string]bool, len(skus))
+	for _, sku := range skus {
+		taken[sku] = true
+	}
+
+	var ids []uint
+	err = tx.Unscoped().Model(&productRequiredSKU{}).Where("sku IS NULL").Order("id").Pluck("id", &ids).Error
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		base := fmt.Sprintf("PRODUCT-%d", id)
+		sku := base
+		for i := 2; taken[sku]; i++ {
+			sku = fmt.Sprintf("%s-%d", base, i)
+		}
+		taken[sku] = true
+
+		err := tx.Unscoped().Model(&productRequiredSKU{}).Where("id = ?", id).Update("sku", sku).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sqlite recreates the table to alter a column, which drops its indexes.
+func createProductsIndexes(tx *gorm.DB) error {
+	for _, index := range []string{"SKU", "Barcode", "Slug", "DeletedAt"} {
+		if tx.Migrator().HasIndex(&productRequiredSKU{}, index) {
+			continue
+		}
+		if err := tx.Migrator().CreateIndex(&productRequiredSKU{}, index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// the required column, with the indexes sqlite drops with the table.
+type productRequiredSKU struct {
+	ID        uint
+	DeletedAt *gorm.DeletedAt `gorm:"index"`
+	SKU       string          `gorm:"size:64;not null;uniqueIndex"`
+	Barcode   *string         `gorm:"size:13;uniqueIndex"`
+	Slug      string          `gorm:"size:96;not null;default:'';uniqueIndex"`
+}
+
+func (productRequiredSKU) TableName() string {
+	return "products"
+}
@@ -0,0 +1,255 @@
+// Package baseline holds the copies of the models as they were when the versioned migrations were introduced, the
+// initial schema migration creates the tables from them. They must never be changed, the later changes of the models
+// are made by their own migrations.
+package baseline
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Category struct {
+	ID        uint `gorm:"primarykey;autoIncrement"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *gorm.DeletedAt `gorm:"index"`
+	Name      string          `gorm:"not null;size:32"`
+	Products  []Product       `gorm:"foreignKey:CategoryID;constraint:OnDelete:CASCADE"`
+}
+
+type Product struct {
+	ID          uint `gorm:"primarykey;autoIncrement"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   *gorm.DeletedAt `gorm:"index"`
+	Name        string          `gorm:"size:32;not null"`
+	Quantity    uint            `gorm:"check:quantity > 0"`
+	Images      []Image         `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+	Reviews     []Review        `gorm:"foreignKey:ProductID;OnDelete:CASCADE"`
+	Description *string         `gorm:"size:256"`
+	CategoryID  uint            `gorm:"not null"`
+	Price       float64         `gorm:"check:price > 0;type:decimal(7,2)"`
+}
+
+type User struct {
+	ID           uint `gorm:"primarykey;autoIncrement"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	DeletedAt    *gorm.DeletedAt `gorm:"index"`
+	Name         string          `gorm:"not null;size:32"`
+	Avatar       *string         `gorm:"default:NULL;size:256"`
+	Email        string          `gorm:"uniqueIndex;not null;size:64"`
+	Password     string          `gorm:"size:128;not null"`
+	MobileNumber *string         `gorm:"default:NULL;size:32"`
+	Roles        []Role          `gorm:"many2many:user_roles;foreignKey:ID;joinForeignKey:UserID;References:ID;joinReferences:RoleID;constraint:OnDelete:CASCADE;"`
+	CartItems    []CartItem      `gorm:"foreignkey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+type CartItem struct {
+	ID        uint `gorm:"primarykey;autoIncrement"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Product   *Product `gorm:"foreignkey:ProductID;constraint:OnDelete:CASCADE"`
+	ProductID uint     `gorm:"uniqueIndex:idx_cart_items_user_product,not null"`
+	Quantity  uint     `gorm:"not null;check:quantity > 0"`
+	UserID    uint     `gorm:"uniqueIndex:idx_cart_items_user_product,not null"`
+}
+
+type Review struct {
+	ID        uint `gorm:"primarykey;autoIncrement"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *gorm.DeletedAt `gorm:"index"`
+	User      *User           `gorm:"foreignKey:UserID;OnDelete:CASCADE"`
+	UserID    uint            `gorm:"uniqueIndex:idx_reviews_user_product,not null"`
+	Product   *Product
+	ProductID uint   `gorm:"uniqueIndex:idx_reviews_user_product,not null"`
+	Comment   string `gorm:"not null;size:256"`
+	Rate      uint8  `gorm:"not null;check:rate >=1 AND rate <= 5"`
+}
+
+type Order struct {
+	ID         uint `gorm:"primarykey;autoIncrement"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	User       *User       `gorm:"foreignkey:UserID;constraint:OnDelete:CASCADE"`
+	UserID     uint        `gorm:"index;not null"`
+	TotalPrice float64     `gorm:"check: total_price > 0;type:decimal(7,2)"`
+	Status     string      `gorm:"default:Pending;size:16;not null;index"`
+	OrderItems []OrderItem `gorm:"foreignkey:OrderID;constraint:OnDelete:CASCADE"`
+	AddressID  uint        `gorm:"not null"`
+	Address    *Address    `gorm:"foreignkey:AddressID"`
+}
+
+type OrderItem struct {
+	ID        uint     `gorm:"primarykey;autoIncrement"`
+	OrderID   uint     `gorm:"index;not null"`
+	Product   *Product `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+	ProductID uint     `gorm:"not null"`
+	UnitPrice float64  `gorm:"not null;check: quantity >= 0;type:decimal(7,2)"`
+	Quantity  uint     `gorm:"not null;size:8"`
+}
+
+type Image struct {
+	ID            uint `gorm:"primarykey;autoIncrement"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	DeletedAt     *gorm.DeletedAt `gorm:"index"`
+	ProductID     uint            `gorm:"index"`
+	ImageUrl      string          `gorm:"not null;size:256"`
+	IsMain        *bool           `gorm:"default:false;not null"`
+	ImagePublicId string          `gorm:"not null;size:128"`
+}
+
+type Role struct {
+	ID          uint         `gorm:"primarykey;autoIncrement"`
+	Name        string       `gorm:"size:32;not null;unique"`
+	Users       []User       `gorm:"many2many:user_roles;foreignKey:ID;joinForeignKey:RoleID;References:ID;joinReferences:UserID;constraint:OnDelete:CASCADE;"`
+	Permissions []Permission `gorm:"many2many:role_permissions;foreignKey:ID;joinForeignKey:RoleID;References:ID;joinReferences:PermissionID;constraint:OnDelete:CASCADE;"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type UserRoles struct {
+	UserID     uint `gorm:"primaryKey;not null"`
+	User       *User
+	RoleID     uint `gorm:"primaryKey;not null"`
+	Role       *Role
+	AssignedAt time.Time `gorm:"autoCreateTime"`
+}
+
+type Address struct {
+	ID            uint    `gorm:"primarykey;autoIncrement"`
+	FullName      string  `gorm:"not null;size:4;size:32"`
+	City          string  `gorm:"not null;size:4;size:32"`
+	StreetAddress string  `gorm:"not null;size:4;size:64"`
+	State         *string `gorm:"size:4;size:32"`
+	ZipCode       *string `gorm:"size:3;size:12"`
+	Country       string  `gorm:"not null;size:4;size:32"`
+	UserID        uint    `gorm:"not null"`
+	User          *User   `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	CreatedAt     time.Time
+	DeletedAt     *gorm.DeletedAt `gorm:"index"`
+}
+
+type Message struct {
+	ID        uint   `gorm:"primarykey;autoIncrement"`
+	From      uint   `gorm:"not null;index"`
+	FromUser  *User  `gorm:"foreignKey:From;OnDelete:CASCADE"`
+	To        uint   `gorm:"not null;index"`
+	Content   string `gorm:"not null;size:256"`
+	ToUser    *User  `gorm:"foreignKey:To;OnDelete:CASCADE"`
+	Status    string `gorm:"not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type ApiKey struct {
+	ID         uint `gorm:"primarykey;autoIncrement"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	Name       string   `gorm:"not null;size:64"`
+	Prefix     string   `gorm:"not null;size:16"`
+	KeyHash    string   `gorm:"not null;size:64;uniqueIndex"`
+	UserID     uint     `gorm:"not null;index"`
+	User       *User    `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Scopes     []string `gorm:"serializer:json;type:text;not null"`
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+type Permission struct {
+	ID          uint    `gorm:"primarykey;autoIncrement"`
+	Name        string  `gorm:"size:64;not null;unique"`
+	Description *string `gorm:"size:256"`
+	Roles       []Role  `gorm:"many2many:role_permissions;foreignKey:ID;joinForeignKey:PermissionID;References:ID;joinReferences:RoleID;constraint:OnDelete:CASCADE;"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type RolePermissions struct {
+	RoleID       uint  `gorm:"primaryKey;not null"`
+	Role         *Role `gorm:"foreignKey:RoleID"`
+	PermissionID uint  `gorm:"primaryKey;not null"`
+	Permission   *Permission
+	AssignedAt   time.Time `gorm:"autoCreateTime"`
+}
+
+type AccountLockout struct {
+	ID           uint `gorm:"primarykey;autoIncrement"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Email        string    `gorm:"not null;size:64;index"`
+	UserID       *uint     `gorm:"index"`
+	User         *User     `gorm:"foreignKey:UserID;constraint:OnDelete:SET NULL"`
+	IP           string    `gorm:"not null;size:64"`
+	Reason       string    `gorm:"not null;size:16"`
+	Failures     int       `gorm:"not null"`
+	LockedUntil  time.Time `gorm:"not null"`
+	UnlockedAt   *time.Time
+	UnlockedByID *uint
+}
+
+type Identity struct {
+	ID        uint `gorm:"primarykey;autoIncrement"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Provider  string  `gorm:"not null;size:32;uniqueIndex:idx_identity_provider_subject;uniqueIndex:idx_identity_provider_user"`
+	Subject   string  `gorm:"not null;size:255;uniqueIndex:idx_identity_provider_subject"`
+	UserID    uint    `gorm:"not null;uniqueIndex:idx_identity_provider_user"`
+	User      *User   `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Email     *string `gorm:"default:NULL;size:64"`
+}
+
+type AccountDeletion struct {
+	ID          uint `gorm:"primarykey;autoIncrement"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	UserID      uint      `gorm:"not null;uniqueIndex"`
+	User        *User     `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	RequestedAt time.Time `gorm:"not null"`
+	PurgeAfter  time.Time `gorm:"not null;index"`
+	CompletedAt *time.Time
+}
+
+type ImpersonationSession struct {
+	ID             uint `gorm:"primarykey;autoIncrement"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	ImpersonatorID uint      `gorm:"not null;index"`
+	Impersonator   *User     `gorm:"foreignKey:ImpersonatorID;constraint:OnDelete:CASCADE"`
+	TargetUserID   uint      `gorm:"not null;index"`
+	TargetUser     *User     `gorm:"foreignKey:TargetUserID;constraint:OnDelete:CASCADE"`
+	Reason         string    `gorm:"not null;size:256"`
+	ExpiresAt      time.Time `gorm:"not null"`
+	EndedAt        *time.Time
+}
+
+type ImpersonationRequest struct {
+	ID         uint                  `gorm:"primarykey;autoIncrement"`
+	SessionID  uint                  `gorm:"not null;index"`
+	Session    *ImpersonationSession `gorm:"foreignKey:SessionID;constraint:OnDelete:CASCADE"`
+	Method     string                `gorm:"not null;size:8"`
+	Path       string                `gorm:"not null;size:256"`
+	StatusCode int                   `gorm:"not null"`
+	CreatedAt  time.Time
+}
+
+type AuditLog struct {
+	ID             uint  `gorm:"primarykey;autoIncrement"`
+	ActorID        *uint `gorm:"index"`
+	ApiKeyID       *uint
+	ImpersonatorID *uint
+	Action         string         `gorm:"not null;size:32;index"`
+	ResourceType   string         `gorm:"not null;size:64;index:idx_audit_logs_resource"`
+	ResourceID     *uint          `gorm:"index:idx_audit_logs_resource"`
+	Changes        map[string]any `gorm:"serializer:json;type:text"`
+	Method         string         `gorm:"not null;size:8"`
+	Path           string         `gorm:"not null;size:256"`
+	StatusCode     int            `gorm:"not null"`
+	IP             string         `gorm:"not null;size:64"`
+	RequestID      string         `gorm:"size:64;index"`
+	CreatedAt      time.Time      `gorm:"index"`
+}
@@ -0,0 +1,214 @@
+// Package migrations holds the versioned schema migrations of the database and applies them, the applied versions
+// are recorded in the schema_migrations table. The migrations are run by "cmd/migrate", the api only checks that
+// none is pending on start.
+//
+// A migration must never be edited once it was released, the schema changes (a column, an index) are added as a
+// new migration with the next version. The baseline migration creates the tables from the frozen copies of the
+// baseline package, so every later migration finds the schema left by the previous ones, on a fresh database too.
+// The later migrations never use the models either, each one declares the frozen structs of the tables and columns
+// it changes so it does the same thing whenever it runs.
+package migrations
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Migration struct {
+	// strictly increasing, it is the order in which the migrations are applied.
+	Version uint
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+func (m Migration) String() string {
+	return fmt.Sprintf("%04d_%v", m.Version, m.Name)
+}
+
+// the row of an applied migration.
+type SchemaMigration struct {
+	Version   uint      `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"not null;size:255"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+var registered []Migration
+
+// called by the init of each migration file.
+func register(migration Migration) {
+	registered = append(registered, migration)
+}
+
+// returns the registered migrations sorted by version.
+func All() []Migration {
+	migrations := slices.Clone(registered)
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return int(a.Version) - int(b.Version)
+	})
+	return migrations
+}
+
+type Status struct {
+	Migration Migration
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+type Migrator struct {
+	DB         *gorm.DB
+	Migrations []Migration
+}
+
+// the migrations must be sorted by version, it fails on a duplicated version or on a migration without Up/Down.
+func NewMigrator(DB *gorm.DB, migrations []Migration) (*Migrator, error) {
+	for i, migration := range migrations {
+		if migration.Up == nil || migration.Down == nil {
+			return nil, fmt.Errorf("migration %v must define both up and down", migration)
+		}
+		if i > 0 && migration.Version <= migrations[i-1].Version {
+			return nil, fmt.Errorf("migration %v must have a version greater than %v", migration, migrations[i-1])
+		}
+	}
+
+	return &Migrator{DB: DB, Migrations: migrations}, nil
+}
+
+// creates the schema_migrations table when it does not exist.
+func (m *Migrator) init() error {
+	return m.DB.AutoMigrate(&SchemaMigration{})
+}
+
+func (m *Migrator) applied() (map[uint]SchemaMigration, error) {
+	if err := m.init(); err != nil {
+		return nil, err
+	}
+
+	var rows []SchemaMigration
+	if err := m.DB.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[uint]SchemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// returns every migration with whether it was applied, an applied version that is unknown to this build
+// (the database was migrated by a newer release) is an error.
+func (m *Migrator) Status() ([]Status, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.Migrations))
+	for _, migration := range m.Migrations {
+		status := Status{Migration: migration}
+		if row, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = &row.AppliedAt
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+
+	for version, row := range applied {
+		return nil, fmt.Errorf("the database has the migration %04d_%v applied which is unknown to this build", version, row.Name)
+	}
+
+	return statuses, nil
+}
+
+func (m *Migrator) Pending() ([]Migration, error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]Migration, 0)
+	for _, status := range statuses {
+		if !status.Applied {
+			pending = append(pending, status.Migration)
+		}
+	}
+	return pending, nil
+}
+
+// applies up to "steps" pending migrations (all of them when steps <= 0) in order and returns the applied ones,
+// when dryRun is true nothing is executed and the migrations that would be applied are returned.
+func (m *Migrator) Up(steps int, dryRun bool) ([]Migration, error) {
+	pending, err := m.Pending()
+	if err != nil {
+		return nil, err
+	}
+	if steps > 0 && steps < len(pending) {
+		pending = pending[:steps]
+	}
+	if dryRun {
+		return pending, nil
+	}
+
+	applied := make([]Migration, 0, len(pending))
+	for _, migration := range pending {
+		err := m.DB.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return applied, fmt.Errorf("failed to apply the migration %v: %w", migration, err)
+		}
+		applied = append(applied, migration)
+	}
+
+	return applied, nil
+}
+
+// rolls back the last "steps" applied migrations (at least one) in reverse order and returns the rolled back ones,
+// when dryRun is true nothing is executed and the migrations that would be rolled back are returned.
+func (m *Migrator) Down(steps int, dryRun bool) ([]Migration, error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+	if steps <= 0 {
+		steps = 1
+	}
+
+	toRollback := make([]Migration, 0, steps)
+	for i := len(statuses) - 1; i >= 0 && len(toRollback) < steps; i-- {
+		if statuses[i].Applied {
+			toRollback = append(toRollback, statuses[i].Migration)
+		}
+	}
+	if dryRun {
+		return toRollback, nil
+	}
+
+	rolledBack := make([]Migration, 0, len(toRollback))
+	for _, migration := range toRollback {
+		err := m.DB.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, migration.Version).Error
+		})
+		if err != nil {
+			return rolledBack, fmt.Errorf("failed to roll back the migration %v: %w", migration, err)
+		}
+		rolledBack = append(rolledBack, migration)
+	}
+
+	return rolledBack, nil
+}
@@ -0,0 +1,241 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"gorm.io/gorm"
+	"main.go/internal/database/migrations/baseline"
+	"main.go/pkg/test_utils/testdb"
+)
+
+type widget struct {
+	ID   uint
+	Name string
+}
+
+func testMigrations() []Migration {
+	return []Migration{
+		{
+			Version: 1,
+			Name:    "create_widgets",
+			Up:      func(tx *gorm.DB) error { return tx.Migrator().CreateTable(&widget{}) },
+			Down:    func(tx *gorm.DB) error { return tx.Migrator().DropTable(&widget{}) },
+		},
+		{
+			Version: 2,
+			Name:    "index_widgets_name",
+			Up:      func(tx *gorm.DB) error { return tx.Exec("CREATE INDEX idx_widgets_name ON widgets(name)").Error },
+			Down:    func(tx *gorm.DB) error { return tx.Exec("DROP INDEX idx_widgets_name").Error },
+		},
+	}
+}
+
+func TestNewMigratorRejectsUnorderedVersions(t *testing.T) {
+	migrations := testMigrations()
+	migrations[1].Version = 1
+
+	if _, err := NewMigrator(testdb.SQLite(t), migrations); err == nil {
+		t.Fatal("expected an error for the duplicated version")
+	}
+}
+
+func TestUpDownAndStatus(t *testing.T) {
+	DB := testdb.SQLite(t)
+	migrator, err := NewMigrator(DB, testMigrations())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	planned, err := migrator.Up(0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(planned) != 2 || DB.Migrator().HasTable(&widget{}) {
+		t.Fatalf("dry run must not apply anything, planned %v", planned)
+	}
+
+	applied, err := migrator.Up(1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 1 || !DB.Migrator().HasTable(&widget{}) {
+		t.Fatalf("expected only the first migration to be applied, got %v", applied)
+	}
+
+	pending, err := migrator.Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].Version != 2 {
+		t.Fatalf("expected the second migration to be pending, got %v", pending)
+	}
+
+	if _, err := migrator.Up(0, false); err != nil {
+		t.Fatal(err)
+	}
+	if !DB.Migrator().HasIndex(&widget{}, "idx_widgets_name") {
+		t.Fatal("expected the index to be created")
+	}
+
+	rolledBack, err := migrator.Down(2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rolledBack) != 2 || rolledBack[0].Version != 2 || DB.Migrator().HasTable(&widget{}) {
+		t.Fatalf("expected both migrations to be rolled back in reverse order, got %v", rolledBack)
+	}
+
+	statuses, err := migrator.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range statuses {
+		if status.Applied {
+			t.Fatalf("expected %v to be rolled back", status.Migration)
+		}
+	}
+}
+
+func TestFailedMigrationIsNotRecorded(t *testing.T) {
+	migrations := testMigrations()
+	migrations[1].Up = func(tx *gorm.DB) error { return errors.New("boom") }
+	migrator, err := NewMigrator(testdb.SQLite(t), migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	applied, err := migrator.Up(0, false)
+	if err == nil {
+		t.Fatal("expected the second migration to fail")
+	}
+	if len(applied) != 1 {
+		t.Fatalf("expected the first migration to stay applied, got %v", applied)
+	}
+
+	pending, err := migrator.Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].Version != 2 {
+		t.Fatalf("expected the failed migration to stay pending, got %v", pending)
+	}
+}
+
+func TestUnknownAppliedVersion(t *testing.T) {
+	DB := testdb.SQLite(t)
+	migrator, err := NewMigrator(DB, testMigrations())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(0, false); err != nil {
+		t.Fatal(err)
+	}
+
+	older, err := NewMigrator(DB, testMigrations()[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := older.Status(); err == nil {
+		t.Fatal("expected an error for the migration unknown to the build")
+	}
+}
+
+func TestRegisteredMigrationsAreValid(t *testing.T) {
+	migrator, err := NewMigrator(testdb.SQLite(t), All())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(0, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Down(len(All()), false); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpgradeFromTheBaseline(t *testing.T) {
+	DB := testdb.SQLite(t)
+	migrator, err := NewMigrator(DB, All())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(1, false); err != nil {
+		t.Fatal(err)
+	}
+
+	category := baseline.Category{Name: "Phones"}
+	if err := DB.Create(&category).Error; err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		product := baseline.Product{Name: "Phone", Quantity: 1, Price: 10, CategoryID: category.ID}
+		if err := DB.Create(&product).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := migrator.Up(0, false); err != nil {
+		t.Fatal(err)
+	}
+
+	var slugs []string
+	if err := DB.Table("products").Order("id").Pluck("slug", &slugs).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(slugs, []string{"phone", "phone-2"}) {
+		t.Fatalf("expected the slugs of the existing products to be backfilled, got %v", slugs)
+	}
+
+	var skus []string
+	if err := DB.Table("products").Order("id").Pluck("sku", &skus).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(skus, []string{"PRODUCT-1", "PRODUCT-2"}) {
+		t.Fatalf("expected the skus of the existing products to be backfilled, got %v", skus)
+	}
+
+	var path string
+	if err := DB.Table("categories").Where("id = ?", category.ID).Pluck("path", &path).Error; err != nil {
+		t.Fatal(err)
+	}
+	if path != fmt.Sprintf("%d/", category.ID) {
+		t.Fatalf("expected the existing category to become a root, got the path %q", path)
+	}
+}
+
+func TestRollBackToTheBaselineAndUpgradeAgain(t *testing.T) {
+	DB := testdb.SQLite(t)
+	migrator, err := NewMigrator(DB, All())
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexes := func() []string {
+		var names []string
+		if err := DB.Raw("SELECT name FROM sqlite_master WHERE type = 'index' AND sql IS NOT NULL ORDER BY name").Scan(&names).Error; err != nil {
+			t.Fatal(err)
+		}
+		return names
+	}
+
+	if _, err := migrator.Up(0, false); err != nil {
+		t.Fatal(err)
+	}
+	upgraded := indexes()
+
+	if _, err := migrator.Down(len(All())-1, false); err != nil {
+		t.Fatal(err)
+	}
+	if DB.Migrator().HasColumn("user_roles", "assigned_at") {
+		t.Fatal("expected the rollback to drop the assigned_at column")
+	}
+
+	if _, err := migrator.Up(0, false); err != nil {
+		t.Fatal(err)
+	}
+	if again := indexes(); !slices.Equal(again, upgraded) {
+		t.Fatalf("expected the same indexes after upgrading again, got %v instead of %v", again, upgraded)
+	}
+}
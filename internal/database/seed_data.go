@@ -3,6 +3,7 @@ package database
 import (
 	"gorm.io/gorm"
 	"main.go/pkg/models"
+	"main.go/types"
 )
 
 func SeedData(db *gorm.DB) error {
@@ -26,5 +27,46 @@ func SeedData(db *gorm.DB) error {
 		}
 	}
 
+	return seedPermissions(db)
+}
+
+// the default roles are assigned only when the permission is created for the first time,
+// this way the assignments changed later by SuperAdmins are not overridden on every start.
+func seedPermissions(db *gorm.DB) error {
+	for _, permission := range types.AllPermissions() {
+		var existingPermission models.Permission
+		err := db.Where("name = ?", string(permission)).First(&existingPermission).Error
+		if err == nil {
+			continue
+		}
+		if err != gorm.ErrRecordNotFound {
+			return err
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			newPermission := models.Permission{Name: string(permission)}
+			if err := tx.Create(&newPermission).Error; err != nil {
+				return err
+			}
+
+			for _, roleName := range types.DefaultRolesOfPermission(permission) {
+				var role models.Role
+				if err := tx.Where("name = ?", string(roleName)).First(&role).Error; err != nil {
+					return err
+				}
+
+				rolePermission := models.RolePermissions{RoleID: role.ID, PermissionID: newPermission.ID}
+				if err := tx.Create(&rolePermission).Error; err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
-}
\ No newline at end of file
+}
@@ -2,7 +2,7 @@ package websocket
 
 import (
 	"encoding/json"
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -43,7 +43,7 @@ func (c *Client) readMessage() {
 
 	err := c.conn.SetReadDeadline(time.Now().Add(pongWait))
 	if err != nil {
-		log.Println(err)
+		slog.Warn("websocket client error", "userId", c.userIdAttr(), "error", err)
 		return
 	}
 
@@ -57,14 +57,14 @@ func (c *Client) readMessage() {
 		err := c.conn.ReadJSON(&event)
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Println("error reading message: ", err)
+				slog.Warn("failed to read the websocket message", "userId", c.userIdAttr(), "error", err)
 			}
 			break
 		}
 
 		err = c.manager.routeHandler(event, c)
 		if err != nil {
-			log.Println(err)
+			slog.Warn("websocket client error", "userId", c.userIdAttr(), "error", err)
 			break
 		}
 	}
@@ -84,27 +84,27 @@ func (c *Client) writeMessage() {
 		case message, ok := <-c.eventsChan:
 			if !ok {
 				if err := c.conn.WriteMessage(websocket.CloseMessage, nil); err != nil {
-					log.Println("connection closed: ", err)
+					slog.Warn("failed to close the websocket connection", "userId", c.userIdAttr(), "error", err)
 				}
 				return
 			}
 
 			messageBytes, err := json.Marshal(message)
 			if err != nil {
-				log.Println(err)
+				slog.Warn("websocket client error", "userId", c.userIdAttr(), "error", err)
 				break
 			}
 			if err := c.conn.WriteMessage(websocket.TextMessage, messageBytes); err != nil {
-				log.Println("an error has occurred during sending message: ", err)
+				slog.Warn("failed to send the websocket message", "userId", c.userIdAttr(), "error", err)
 				return
 			}
-			log.Println("message sent")
+			slog.Debug("websocket message sent", "userId", c.userIdAttr())
 
 			case <-ticker.C:
-				log.Println("ping...")
+				slog.Debug("websocket ping", "userId", c.userIdAttr())
 				err := c.conn.WriteMessage(websocket.PingMessage, []byte{})
 				if err != nil {
-					log.Println("connection has failed to respond, err: ", err)
+					slog.Warn("websocket client failed to respond to the ping", "userId", c.userIdAttr(), "error", err)
 					return
 				}
 		}
@@ -122,17 +122,17 @@ func (c *Client) runPing() {
 	}()
 	err := c.conn.SetReadDeadline(time.Now().Add(pongWait))
 	if err != nil {
-		log.Println(err)
+		slog.Warn("websocket client error", "userId", c.userIdAttr(), "error", err)
 		return
 	}
 
 	ticker := time.NewTicker(pingInterval)
 	for range ticker.C {
-		log.Println("ping...")
+		slog.Debug("websocket ping", "userId", c.userIdAttr())
 
 		err := c.conn.WriteMessage(websocket.PingMessage, []byte{})
 		if err != nil {
-			log.Println("connection has failed to respond, err: ", err)
+			slog.Warn("websocket client failed to respond to the ping", "userId", c.userIdAttr(), "error", err)
 			return
 		}
 	}
@@ -145,7 +145,7 @@ func (c *Client) runPongAndDisallowWrites() {
 	for {
 		_,_,err := c.conn.ReadMessage()
 		if err != nil {
-			log.Println(err)
+			slog.Warn("websocket client error", "userId", c.userIdAttr(), "error", err)
 			break
 		}
 
@@ -169,17 +169,17 @@ func (c *Client) writeMessageGuest() {
 			}
 
 			if message.Type != ProductsStockUpdate {
-				log.Println("Not allowed to receive events except for ProductsStockUpdate")
+				slog.Warn("guest websocket clients are only allowed to receive the products stock updates", "eventType", message.Type)
 				return
 			}
 
 			messageBytes, err := json.Marshal(message)
 			if err != nil {
-				log.Println(err)
+				slog.Warn("websocket client error", "userId", c.userIdAttr(), "error", err)
 				break
 			}
 			if err := c.conn.WriteMessage(websocket.TextMessage, messageBytes); err != nil {
-				log.Println("an error has occurred during sending message: ", err)
+				slog.Warn("failed to send the websocket message", "userId", c.userIdAttr(), "error", err)
 				return
 			}
 		}
@@ -187,7 +187,15 @@ func (c *Client) writeMessageGuest() {
 }
 
 func (c *Client) pongHandler(pongMsg string) error {
-	log.Println("pong")
+	slog.Debug("websocket pong", "userId", c.userIdAttr())
 	err := c.conn.SetReadDeadline(time.Now().Add(pongWait))
 	return err
 }
+
+// guests have no user id, they are logged as 0.
+func (c *Client) userIdAttr() uint {
+	if c.id == nil {
+		return 0
+	}
+	return *c.id
+}
@@ -4,13 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"slices"
 	"sync"
 	"time"
 
-	"main.go/internal/database"
+	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
 	"main.go/pkg/models"
+	"main.go/pkg/tracing"
 	"main.go/pkg/utils"
 	"main.go/types"
 )
@@ -28,14 +32,14 @@ type Manager struct {
 var GlobalManager *Manager
 var retentionPeriod = 5 * time.Second
 
-func NewManager(ctx context.Context) *Manager {
+func NewManager(ctx context.Context, DB *gorm.DB) *Manager {
 	if GlobalManager == nil {
 		GlobalManager = &Manager{
 			clients:  make(Clients),
 			handlers: make(map[string]EventHandler),
 			registedClients: make(map[uint][]*Client),
 			Otps:     NewRetentionMap(ctx, retentionPeriod),
-			store: NewStore(database.DB),
+			store: NewStore(DB),
 		}
 
 		GlobalManager.setupEventHandlers()
@@ -46,24 +50,25 @@ func NewManager(ctx context.Context) *Manager {
 
 func (m *Manager) serveWS(w http.ResponseWriter, r *http.Request) {
 	otp := r.URL.Query().Get("otp")
-	fmt.Println("otp key: ", otp)
 	isCorrectOTP := otp != "" && m.Otps.ValidateOTP(otp)
-	if !isCorrectOTP {
-		log.Println("invalid OTP")
+	if otp != "" && !isCorrectOTP {
+		slog.WarnContext(r.Context(), "invalid websocket otp, the client is connected as a guest")
 	}
 
+	// the upgrader already responded with the error
 	ws, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.Fatal(err)
+		slog.WarnContext(r.Context(), "failed to upgrade the websocket connection", "error", err)
+		return
 	}
 
 	var client *Client
 	userId, err := utils.GetUserIdCtx(r)
 	if err != nil || !isCorrectOTP {
-		log.Println("Guest client")
+		slog.DebugContext(r.Context(), "websocket guest client connected")
 		client = NewClient(ws, m, nil)
 	} else {
-		log.Println("RegularUser client")
+		slog.DebugContext(r.Context(), "websocket user client connected", "userId", *userId)
 		client = NewClient(ws, m, userId)
 	}
 	m.addClient(client)
@@ -87,16 +92,26 @@ func (m *Manager) setupEventHandlers() {
 	m.handlers[string(MessageStatusUpdate)] = HandleMessageUpdateStatus
 }
 
+// every event is traced in its own root span, the connection outlives the upgrade request so it is not a parent.
 func (m *Manager) routeHandler(event Event, client *Client) error {
+	_, span := tracing.Start(context.Background(), "websocket."+string(event.Type),
+		attribute.String("websocket.event_type", string(event.Type)),
+		attribute.Int64("enduser.id", int64(client.userIdAttr())),
+	)
+	defer span.End()
+
 	handler, ok := m.handlers[string(event.Type)]
 	if ok {
 		if err := handler(event, client); err != nil {
+			tracing.RecordError(span, err)
 			return err
 		}
 
 		return nil
 	} else {
-		return fmt.Errorf("received unknown event type: %v", event.Type)
+		err := fmt.Errorf("received unknown event type: %v", event.Type)
+		tracing.RecordError(span, err)
+		return err
 	}
 }
 
@@ -110,11 +125,43 @@ func (m *Manager) deleteClient(client *Client) {
 
 	if client.id != nil {
 		m.registedClientsLock.Lock()
-		m.registedClients[*client.id] = append(m.registedClients[*client.id], client)
+		m.registedClients[*client.id] = slices.DeleteFunc(m.registedClients[*client.id], func(c *Client) bool {
+			return c == client
+		})
+		if len(m.registedClients[*client.id]) == 0 {
+			delete(m.registedClients, *client.id)
+		}
 		m.registedClientsLock.Unlock()
 	}
 }
 
+// sends a "going away" close frame to every client then closes their connections, the http server does not track
+// the websocket connections after the upgrade so they must be closed here on shutdown.
+func (m *Manager) Shutdown() {
+	m.clientsLock.RLock()
+	clients := make([]*Client, 0, len(m.clients))
+	for client := range m.clients {
+		clients = append(clients, client)
+	}
+	m.clientsLock.RUnlock()
+
+	closeMessage := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server is shutting down")
+	for _, client := range clients {
+		err := client.conn.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(time.Second))
+		if err != nil {
+			slog.Warn("failed to send the websocket close frame", "userId", client.userIdAttr(), "error", err)
+		}
+		m.deleteClient(client)
+	}
+}
+
+// the count of the connected clients (guests included).
+func (m *Manager) ClientsCount() int {
+	m.clientsLock.RLock()
+	defer m.clientsLock.RUnlock()
+	return len(m.clients)
+}
+
 func (m *Manager) addClient(client *Client) {
 	m.clientsLock.Lock()
 	m.clients[client] = true
@@ -132,7 +179,7 @@ func (m *Manager) addClient(client *Client) {
 func (m *Manager) BroadcastCUMessage(message models.Message, userIds []uint, eventType EventType) {
 	productMsg, err := json.Marshal(message)
 	if err != nil {
-		log.Fatal(err)
+		slog.Error("failed to marshal the websocket event", "error", err)
 		return
 	}
 
@@ -153,7 +200,7 @@ func (m *Manager) BroadcastCUMessage(message models.Message, userIds []uint, eve
 func (m *Manager) BroadcastDMessage(payload DeleteMessagePayload, userIds []uint) {
 	deletePayload, err := json.Marshal(payload)
 	if err != nil {
-		log.Fatal(err)
+		slog.Error("failed to marshal the websocket event", "error", err)
 		return
 	}
 
@@ -172,7 +219,7 @@ func (m *Manager) BroadcastDMessage(payload DeleteMessagePayload, userIds []uint
 func (m *Manager) BroadcastProductQtyChange(products []types.ProductAmountDiscounter) {
 	productsMsg, err := json.Marshal(products)
 	if err != nil {
-		log.Fatal(err)
+		slog.Error("failed to marshal the websocket event", "error", err)
 		return
 	}
 
@@ -3,7 +3,7 @@ package websocket
 import (
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 
 	"main.go/pkg/utils"
 	"main.go/types"
@@ -13,7 +13,7 @@ func HandleMessageCreate(event Event, client *Client) error {
 	var userMessage MessagePayload
 	err := json.Unmarshal(event.Payload, &userMessage)
 	if err != nil {
-		log.Println("Error during converting from json to user message payload")
+		slog.Warn("failed converting from json to user message payload", "userId", client.userIdAttr(), "error", err)
 		return err
 	}
 
@@ -26,14 +26,14 @@ func HandleMessageCreate(event Event, client *Client) error {
 
 	err = utils.ValidateStruct(msg)
 	if err != nil {
-		log.Println("invalid message payload")
+		slog.Warn("invalid message payload", "userId", client.userIdAttr(), "error", err)
 		return err
 	}
 	
 	validMsg := msg.TrimStrs().ToModel()
 	err = GlobalManager.store.CreateMessage(*validMsg)
 	if err != nil {
-		log.Println("Error during creating message")
+		slog.Warn("failed creating message", "userId", client.userIdAttr(), "error", err)
 		return err
 	}
 
@@ -44,7 +44,7 @@ func HandleMessageUpdate(event Event, client *Client) error {
 	var userMessage MessagePayload
 	err := json.Unmarshal(event.Payload, &userMessage)
 	if err != nil {
-		log.Println("Error during converting from json to user message payload")
+		slog.Warn("failed converting from json to user message payload", "userId", client.userIdAttr(), "error", err)
 		return err
 	}
 
@@ -56,14 +56,14 @@ func HandleMessageUpdate(event Event, client *Client) error {
 
 	err = utils.ValidateStruct(msg)
 	if err != nil {
-		log.Println("invalid message payload")
+		slog.Warn("invalid message payload", "userId", client.userIdAttr(), "error", err)
 		return err
 	}
 	
 	changes := msg.TrimStrs().ToModel()
 	_,err = GlobalManager.store.UpdateMessage(userMessage.Id,*changes, &msg)
 	if err != nil {
-		log.Println("Error during creating message")
+		slog.Warn("failed creating message", "userId", client.userIdAttr(), "error", err)
 		return err
 	}
 
@@ -74,20 +74,20 @@ func HandleMessageDelete(event Event, client *Client) error {
 	var delPayload DeleteMessagePayload
 	err := json.Unmarshal(event.Payload, &delPayload)
 	if err != nil {
-		log.Println("Error during converting from json to user message payload")
+		slog.Warn("failed converting from json to user message payload", "userId", client.userIdAttr(), "error", err)
 		return err
 	}
 
 	payload := delPayload.ToDeletePayload()
 	err = utils.ValidateStruct(payload)
 	if err != nil {
-		log.Println("invalid message payload")
+		slog.Warn("invalid message payload", "userId", client.userIdAttr(), "error", err)
 		return err
 	}
 	
 	err = GlobalManager.store.DeleteMessage(payload.Id)
 	if err != nil {
-		log.Println("Error during creating message")
+		slog.Warn("failed creating message", "userId", client.userIdAttr(), "error", err)
 		return err
 	}
 
@@ -98,19 +98,19 @@ func HandleMessageUpdateStatus(event Event, client *Client) error {
 	var updateStatusPayload MessageStatusPayload
 	err := json.Unmarshal(event.Payload, &updateStatusPayload)
 	if err != nil {
-		log.Println("Error during converting from json to user message status updating payload")
+		slog.Warn("failed converting from json to user message status updating payload", "userId", client.userIdAttr(), "error", err)
 		return err
 	}
 
 	err = utils.ValidateStruct(updateStatusPayload)
 	if err != nil {
-		log.Println("invalid message update status payload")
+		slog.Warn("invalid message update status payload", "userId", client.userIdAttr(), "error", err)
 		return err
 	}
 	
 	err = GlobalManager.store.UpdateMessageStatus(updateStatusPayload.Id, updateStatusPayload.Status)
 	if err != nil {
-		log.Println("Error during updating message status")
+		slog.Warn("failed updating message status", "userId", client.userIdAttr(), "error", err)
 		return err
 	}
 
@@ -30,7 +30,9 @@ func (r RetentionMap) NewOTP() OTP {
 		CreatedAt: time.Now(),
 	}
 
+	mu.Lock()
 	r[otp.Key] = otp
+	mu.Unlock()
 	return otp
 }
 
@@ -38,7 +40,9 @@ func (r RetentionMap) ValidateOTP(OTPkey string) bool {
 	if OTPkey == "passkey" {
 		return true
 	}
+	mu.Lock()
 	_, ok := r[OTPkey]
+	mu.Unlock()
 	if !ok {
 		return false
 	}
@@ -70,3 +74,10 @@ func (r RetentionMap) deleteOTP(OTPkey string) {
 	delete(r, OTPkey)
 	mu.Unlock()
 }
+
+// the count of the otps that were not used yet.
+func (r RetentionMap) Size() int {
+	mu.Lock()
+	defer mu.Unlock()
+	return len(r)
+}
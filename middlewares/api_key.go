@@ -0,0 +1,85 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"main.go/constants"
+	"main.go/pkg/logger"
+	"main.go/services/auth"
+	"main.go/types"
+)
+
+const ApiKeyHeader = "X-API-Key"
+
+var apiKeyLookup *ApiKeyLookup
+
+// this must be used only on the routes that machine clients are allowed to call, if the "X-API-Key" header is not sent
+// it falls back to the normal user authentication.
+//
+// the key owner is set as the user of the request, while the authorization is done by the scopes of the key.
+func AuthenticateWithApiKey(next http.HandlerFunc) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		plainKey := r.Header.Get(ApiKeyHeader)
+		if plainKey == "" {
+			Authenticate(next).ServeHTTP(w, r)
+			return
+		}
+
+		apiKey, err := apiKeyLookup.GetApiKeyByHash(r.Context(), auth.HashApiKey(plainKey))
+		if err != nil {
+			auth.Unauthorized(w)
+			return
+		}
+
+		now := time.Now()
+		if !apiKey.IsActive(now) {
+			auth.Unauthorized(w)
+			return
+		}
+
+		user, err := userLookup.GetUserById(r.Context(), apiKey.UserID)
+		if err != nil {
+			auth.Unauthorized(w)
+			return
+		}
+
+		apiKeyLookup.TouchLastUsed(r.Context(), apiKey, now)
+		logger.SetUserID(r.Context(), apiKey.UserID)
+
+		ctx := r.Context()
+		ctx = context.WithValue(ctx, constants.UserKey, user)
+		ctx = context.WithValue(ctx, constants.PrincipalKey, &types.Principal{
+			UserID:   apiKey.UserID,
+			ApiKeyID: &apiKey.ID,
+			Scopes:   apiKey.Scopes,
+		})
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// user authenticated requests pass through, api key authenticated requests must have the given scope.
+func RequireScope(scope types.Scope) func(next http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal := GetPrincipal(r)
+			if principal.IsApiKey() && !principal.HasScope(scope) {
+				auth.DenyPermission(w)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func GetPrincipal(r *http.Request) *types.Principal {
+	principal, ok := r.Context().Value(constants.PrincipalKey).(*types.Principal)
+	if !ok {
+		return nil
+	}
+
+	return principal
+}
@@ -0,0 +1,37 @@
+package middlewares
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"main.go/pkg/models"
+)
+
+// last used is only written when the saved value is older than this, to avoid a write on every request.
+const lastUsedResolution = time.Minute
+
+type ApiKeyLookup struct {
+	DB *gorm.DB
+}
+
+func (a *ApiKeyLookup) GetApiKeyByHash(ctx context.Context, keyHash string) (*models.ApiKey, error) {
+	var apiKey models.ApiKey
+	err := a.DB.WithContext(ctx).Where("key_hash = ?", keyHash).First(&apiKey).Error
+	if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+func (a *ApiKeyLookup) TouchLastUsed(ctx context.Context, apiKey *models.ApiKey, now time.Time) {
+	if apiKey.LastUsedAt != nil && now.Sub(*apiKey.LastUsedAt) < lastUsedResolution {
+		return
+	}
+
+	a.DB.WithContext(ctx).Model(&models.ApiKey{}).Where("id = ?", apiKey.ID).UpdateColumn("last_used_at", now)
+}
+
+func NewApiKeyLookup(DB *gorm.DB) *ApiKeyLookup {
+	return &ApiKeyLookup{DB: DB}
+}
@@ -0,0 +1,175 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+	"main.go/pkg/audit"
+	"main.go/pkg/logger"
+	"main.go/pkg/models"
+	"main.go/pkg/utils"
+	"main.go/types"
+)
+
+// the response body is only kept to read the id of created resources, larger bodies are not buffered.
+const maxAuditedBodySize = 1 << 20
+
+// returns the snapshot of the resource that is saved in the audit log, it must find soft deleted resources too.
+type AuditLoader func(ctx context.Context, id uint) (any, error)
+
+type AuditResource struct {
+	Type string
+	// the path key of the resource id, when the route has no id (e.g create) the id is read from the response body.
+	IdKey string
+	// the key of the created resource in the response body ({"<BodyKey>": {"id": ...}}), required by the audited
+	// routes without an id.
+	BodyKey string
+	// the routes changing many resources at once (e.g an import) list their ids in the response body instead of
+	// loading them, {"<BodyKey>": {"<key>": [ids]}}, each list is recorded as a change of the key.
+	IdsKeys []string
+	Load    AuditLoader
+}
+
+type AuditLookup struct {
+	DB *gorm.DB
+}
+
+func (a *AuditLookup) CreateAuditLog(ctx context.Context, auditLog *models.AuditLog) error {
+	return a.DB.WithContext(ctx).Create(auditLog).Error
+}
+
+func NewAuditLookup(DB *gorm.DB) *AuditLookup {
+	return &AuditLookup{DB: DB}
+}
+
+var auditLookup *AuditLookup
+
+type auditResponse struct {
+	*types.AppResponse
+	body bytes.Buffer
+}
+
+func (ar *auditResponse) Write(data []byte) (int, error) {
+	if ar.body.Len()+len(data) <= maxAuditedBodySize {
+		ar.body.Write(data)
+	}
+	return ar.AppResponse.Write(data)
+}
+
+// records the successful mutations of the resource, it must be placed after the authorization middleware
+// so only the requests that reached the handler are recorded.
+func Audit(action types.AuditAction, resource AuditResource) func(next http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var resourceId *uint
+			var before any
+			if resource.IdKey != "" {
+				id, err := utils.ConvertStrToUint(r.PathValue(resource.IdKey))
+				if err == nil {
+					resourceId = id
+					before, _ = resource.Load(r.Context(), *id)
+				}
+			}
+
+			response := &auditResponse{AppResponse: &types.AppResponse{ResponseWriter: w}}
+			next.ServeHTTP(response, r)
+
+			statusCode := response.StatusCode
+			if statusCode == 0 {
+				statusCode = http.StatusOK
+			}
+			if statusCode < 200 || statusCode >= 300 {
+				return
+			}
+
+			var changes map[string]models.AuditChange
+			var err error
+			if len(resource.IdsKeys) != 0 {
+				changes = changedResourcesIds(response.body.Bytes(), resource.BodyKey, resource.IdsKeys)
+			} else {
+				if resourceId == nil && resource.BodyKey != "" {
+					resourceId = createdResourceId(response.body.Bytes(), resource.BodyKey)
+				}
+
+				var after any
+				if resourceId != nil {
+					after, _ = resource.Load(r.Context(), *resourceId)
+				}
+
+				changes, err = audit.Diff(before, after)
+				if err != nil {
+					slog.ErrorContext(r.Context(), "failed to compute the audit log changes", "error", err)
+				}
+			}
+
+			principal := GetPrincipal(r)
+			auditLog := &models.AuditLog{
+				Action:       string(action),
+				ResourceType: resource.Type,
+				ResourceID:   resourceId,
+				Changes:      changes,
+				Method:       r.Method,
+				Path:         truncate(r.URL.RequestURI(), 256),
+				StatusCode:   statusCode,
+				IP:           truncate(utils.GetClientIP(r), 64),
+				RequestID:    logger.GetRequestID(r.Context()),
+				CreatedAt:    time.Now(),
+			}
+			if principal != nil {
+				auditLog.ActorID = &principal.UserID
+				auditLog.ApiKeyID = principal.ApiKeyID
+				auditLog.ImpersonatorID = principal.ImpersonatorID
+			}
+
+			err = auditLookup.CreateAuditLog(r.Context(), auditLog)
+			if err != nil {
+				slog.ErrorContext(r.Context(), "failed to save the audit log", "error", err)
+			}
+		})
+	}
+}
+
+// the created resources are returned as {"<resource name>": {"id": ...}}.
+func createdResourceId(body []byte, key string) *uint {
+	var response map[string]json.RawMessage
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil
+	}
+
+	value, ok := response[key]
+	if !ok {
+		return nil
+	}
+
+	var resource struct {
+		ID uint `json:"id"`
+	}
+	if err := json.Unmarshal(value, &resource); err != nil || resource.ID == 0 {
+		return nil
+	}
+
+	return &resource.ID
+}
+
+// the ids of the resources changed by a route that changes many of them, the empty lists are left out.
+func changedResourcesIds(body []byte, bodyKey string, idsKeys []string) map[string]models.AuditChange {
+	var response map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil
+	}
+
+	changes := map[string]models.AuditChange{}
+	for _, key := range idsKeys {
+		var ids []uint
+		if err := json.Unmarshal(response[bodyKey][key], &ids); err != nil || len(ids) == 0 {
+			continue
+		}
+		changes[key] = models.AuditChange{After: ids}
+	}
+	return changes
+}
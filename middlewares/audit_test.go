@@ -0,0 +1,70 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"main.go/constants"
+	"main.go/pkg/models"
+	"main.go/pkg/utils"
+	"main.go/types"
+)
+
+type auditTestKey string
+
+func TestAudit(t *testing.T) {
+	DB := setupTestLookups(t)
+
+	t.Run("Should load the snapshots with the context of the request", func(t *testing.T) {
+		var contexts []any
+		load := func(ctx context.Context, id uint) (any, error) {
+			contexts = append(contexts, ctx.Value(auditTestKey("request")))
+			return map[string]any{"id": id, "name": "after"}, nil
+		}
+		handler := Audit(types.AuditRevoke, AuditResource{Type: "things", IdKey: constants.IdUrlPathKey, Load: load})(noContent)
+
+		r := httptest.NewRequest(http.MethodDelete, "/things/7", nil)
+		r.SetPathValue(constants.IdUrlPathKey, "7")
+		r = r.WithContext(context.WithValue(r.Context(), auditTestKey("request"), "value"))
+		assert.Equal(t, http.StatusNoContent, serve(handler, r).Code)
+		assert.Equal(t, []any{"value", "value"}, contexts)
+
+		var auditLog models.AuditLog
+		assert.NoError(t, DB.Where("resource_type = ?", "things").First(&auditLog).Error)
+		assert.Equal(t, string(types.AuditRevoke), auditLog.Action)
+		assert.Equal(t, uint(7), *auditLog.ResourceID)
+	})
+
+	t.Run("Should read the id of the created resource from the response", func(t *testing.T) {
+		load := func(ctx context.Context, id uint) (any, error) {
+			return map[string]any{"id": id}, nil
+		}
+		handler := Audit(types.AuditCreate, AuditResource{Type: "created-things", BodyKey: "thing", Load: load})(
+			func(w http.ResponseWriter, r *http.Request) {
+				utils.WriteJSON(w, http.StatusCreated, map[string]any{"thing": map[string]any{"id": 9}})
+			})
+
+		assert.Equal(t, http.StatusCreated, serve(handler, httptest.NewRequest(http.MethodPost, "/things", nil)).Code)
+
+		var auditLog models.AuditLog
+		assert.NoError(t, DB.Where("resource_type = ?", "created-things").First(&auditLog).Error)
+		assert.Equal(t, uint(9), *auditLog.ResourceID)
+		assert.Contains(t, auditLog.Changes, "id")
+	})
+
+	t.Run("Should not record the failed requests", func(t *testing.T) {
+		load := func(ctx context.Context, id uint) (any, error) { return nil, nil }
+		handler := Audit(types.AuditCreate, AuditResource{Type: "failed-things", BodyKey: "thing", Load: load})(
+			func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+			})
+
+		serve(handler, httptest.NewRequest(http.MethodPost, "/things", nil))
+		var count int64
+		DB.Model(&models.AuditLog{}).Where("resource_type = ?", "failed-things").Count(&count)
+		assert.Zero(t, count)
+	})
+}
@@ -3,46 +3,34 @@ package middlewares
 import (
 	"context"
 	"net/http"
+	"strconv"
+	"time"
 
 	"main.go/constants"
+	"main.go/pkg/logger"
 	"main.go/services/auth"
+	"main.go/types"
 )
 
 
-var userLookup = NewUserLookup()
+var userLookup *UserLookup
 
+// accepts both the session cookie and the "Authorization: Bearer <jwt>" header,
+// cookie authenticated requests with state-changing methods must send a valid csrf token.
 func Authenticate(next http.HandlerFunc) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		token, err := auth.GetAccessToken(r)
+		token, source, err := auth.GetAccessToken(r)
 		if err != nil {
 			auth.Unauthorized(w)
 			return
 		}
 
-		jwtToken, err := auth.ValidateToken(token)
-		if err != nil {
-			auth.Unauthorized(w)
+		r, ok := authenticateToken(w, r, token, source)
+		if !ok {
 			return
 		}
 
-		userId, claims, err := auth.GetUserIdFromJWT(jwtToken)
-		if err != nil {
-			auth.Unauthorized(w)
-			return
-		}
-		
-		user, err := userLookup.GetUserById(*userId)
-		if err != nil {
-			auth.Unauthorized(w)
-			return
-		}
-
-		ctx := r.Context()
-		ctx = context.WithValue(ctx, constants.UserKey, user)
-		ctx = context.WithValue(ctx, constants.TokenPayload, claims)
-
-		r = r.WithContext(ctx)
-		next.ServeHTTP(w, r)
+		serveAuthenticated(next, w, r)
 	})
 
 }
@@ -50,36 +38,81 @@ func Authenticate(next http.HandlerFunc) http.HandlerFunc {
 // it skips the authentication if no token found, this meant to be used with websocket handler to allow public clients and non public clients.
 func AuthenticateIfCookieExist(next http.HandlerFunc) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		token, err := auth.GetAccessToken(r)
+		token, source, err := auth.GetAccessToken(r)
 		if err != nil {
 			next.ServeHTTP(w, r)
 			return
 		}
 
-		jwtToken, err := auth.ValidateToken(token)
-		if err != nil {
-			auth.Unauthorized(w)
+		r, ok := authenticateToken(w, r, token, source)
+		if !ok {
 			return
 		}
 
-		userId, claims, err := auth.GetUserIdFromJWT(jwtToken)
-		if err != nil {
-			auth.Unauthorized(w)
-			return
+		next.ServeHTTP(w, r)
+	})
+
+}
+
+// validates the token and sets the user and the token claims inside the context, when it returns false the response was already written.
+func authenticateToken(w http.ResponseWriter, r *http.Request, token *string, source auth.TokenSource) (*http.Request, bool) {
+	// the refresh tokens are only accepted by the refresh route
+	jwtToken, err := auth.ValidateToken(token, auth.AccessTokenType)
+	if err != nil {
+		auth.Unauthorized(w)
+		return r, false
+	}
+
+	userId, claims, err := auth.GetUserIdFromJWT(jwtToken)
+	if err != nil {
+		auth.Unauthorized(w)
+		return r, false
+	}
+
+	if source == auth.CookieTokenSource && auth.IsStateChangingMethod(r.Method) {
+		if err := auth.ValidateCSRF(r); err != nil {
+			auth.DenyPermission(w)
+			return r, false
 		}
-		
-		user, err := userLookup.GetUserById(*userId)
-		if err != nil {
+	}
+
+	user, err := userLookup.GetUserById(r.Context(), *userId)
+	if err != nil {
+		auth.Unauthorized(w)
+		return r, false
+	}
+
+	logger.SetUserID(r.Context(), user.ID)
+	principal := &types.Principal{UserID: user.ID}
+	// impersonation tokens are only returned in the body, so they are only accepted as bearer tokens
+	if impersonation, isImpersonation := auth.GetImpersonationFromClaims(claims); isImpersonation {
+		session, err := impersonationLookup.GetSessionById(r.Context(), impersonation.SessionID)
+		if source != auth.BearerTokenSource || err != nil || !session.IsActive(time.Now()) ||
+			session.ImpersonatorID != impersonation.ImpersonatorID || session.TargetUserID != user.ID {
 			auth.Unauthorized(w)
-			return
+			return r, false
 		}
 
-		ctx := r.Context()
-		ctx = context.WithValue(ctx, constants.UserKey, user)
-		ctx = context.WithValue(ctx, constants.TokenPayload, claims)
+		principal.ImpersonatorID = &session.ImpersonatorID
+		principal.ImpersonationSessionID = &session.ID
+		w.Header().Set(auth.ImpersonatedByHeader, strconv.FormatUint(uint64(session.ImpersonatorID), 10))
+	}
 
-		r = r.WithContext(ctx)
-		next.ServeHTTP(w, r)
-	})
+	ctx := r.Context()
+	ctx = context.WithValue(ctx, constants.UserKey, user)
+	ctx = context.WithValue(ctx, constants.TokenPayload, claims)
+	ctx = context.WithValue(ctx, constants.AuthSourceKey, source)
+	ctx = context.WithValue(ctx, constants.PrincipalKey, principal)
+
+	return r.WithContext(ctx), true
+}
+
+// returns the source of the access token of the authenticated request, the default is the cookie.
+func GetAuthSource(r *http.Request) auth.TokenSource {
+	source, ok := r.Context().Value(constants.AuthSourceKey).(auth.TokenSource)
+	if !ok {
+		return auth.CookieTokenSource
+	}
 
+	return source
 }
@@ -0,0 +1,149 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"main.go/config"
+	"main.go/pkg/models"
+	"main.go/pkg/test_utils/testdb"
+	"main.go/services/auth"
+	"main.go/types"
+)
+
+// builds the lookups on an in-memory database, the secret is read once by the cookies store so every test of the
+// package must use the same one.
+func setupTestLookups(t *testing.T) *gorm.DB {
+	t.Helper()
+	envs := config.Envs
+	config.Envs.JWT_SECRET = "test-secret"
+	config.Envs.ACCESS_JWT_EXPIRATION = time.Minute
+	config.Envs.REFRESH_JWT_EXPIRATION = time.Hour
+	t.Cleanup(func() { config.Envs = envs })
+
+	DB := testdb.SQLite(t,
+		&models.User{}, &models.Role{}, &models.Permission{}, &models.UserRoles{}, &models.RolePermissions{},
+		&models.ImpersonationSession{}, &models.ImpersonationRequest{}, &models.ApiKey{}, &models.AuditLog{},
+	)
+	if err := Setup(DB, config.Envs); err != nil {
+		t.Fatal(err)
+	}
+	return DB
+}
+
+// creates a user with a role holding the given permissions.
+func createTestUser(t *testing.T, DB *gorm.DB, permissions ...types.Permission) *models.User {
+	t.Helper()
+	var count int64
+	DB.Model(&models.User{}).Count(&count)
+	user := &models.User{Name: "user", Email: fmt.Sprintf("user%v@example.com", count+1), Password: "-"}
+	role := &models.Role{Name: fmt.Sprintf("role %v", count+1)}
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(role).Error; err != nil {
+			return err
+		}
+		for _, name := range permissions {
+			permission := models.Permission{Name: string(name)}
+			if err := tx.Where(&permission).FirstOrCreate(&permission).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&models.RolePermissions{RoleID: role.ID, PermissionID: permission.ID}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(&models.UserRoles{UserID: user.ID, RoleID: role.ID}).Error
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func bearerRequest(t *testing.T, method, token string) *http.Request {
+	t.Helper()
+	r := httptest.NewRequest(method, "/", nil)
+	r.Header.Set(auth.AuthorizationHeader, "Bearer "+token)
+	return r
+}
+
+func accessToken(t *testing.T, user *models.User) string {
+	t.Helper()
+	token, err := auth.GenerateAccessToken(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func serve(handler http.HandlerFunc, r *http.Request) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, r)
+	return recorder
+}
+
+func noContent(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TestAuthenticate(t *testing.T) {
+	DB := setupTestLookups(t)
+	user := createTestUser(t, DB)
+
+	t.Run("Should accept a bearer token", func(t *testing.T) {
+		res := serve(Authenticate(noContent), bearerRequest(t, http.MethodPost, accessToken(t, user)))
+		assert.Equal(t, http.StatusNoContent, res.Code)
+	})
+
+	t.Run("Should refuse an expired token", func(t *testing.T) {
+		config.Envs.ACCESS_JWT_EXPIRATION = -time.Minute
+		token := accessToken(t, user)
+		config.Envs.ACCESS_JWT_EXPIRATION = time.Minute
+
+		res := serve(Authenticate(noContent), bearerRequest(t, http.MethodGet, token))
+		assert.Equal(t, http.StatusUnauthorized, res.Code)
+	})
+
+	t.Run("Should check the csrf token of the cookie requests with a state-changing method only", func(t *testing.T) {
+		login := httptest.NewRecorder()
+		_, _, err := auth.GenerateAndSetTokens(*user, login, httptest.NewRequest(http.MethodPost, "/login", nil))
+		assert.NoError(t, err)
+
+		newRequest := func(method, csrfToken string) *http.Request {
+			r := httptest.NewRequest(method, "/", nil)
+			for _, cookie := range login.Result().Cookies() {
+				r.AddCookie(cookie)
+				if cookie.Name == auth.CSRFCookieName && csrfToken != "" {
+					r.Header.Set(auth.CSRFHeader, cookie.Value+csrfToken)
+				}
+			}
+			return r
+		}
+
+		assert.Equal(t, http.StatusNoContent, serve(Authenticate(noContent), newRequest(http.MethodGet, "")).Code)
+		assert.Equal(t, http.StatusForbidden, serve(Authenticate(noContent), newRequest(http.MethodPost, "")).Code)
+		assert.Equal(t, http.StatusForbidden, serve(Authenticate(noContent), newRequest(http.MethodPost, "x")).Code)
+	})
+
+	t.Run("Should pass the csrf check with the token of the session", func(t *testing.T) {
+		login := httptest.NewRecorder()
+		_, _, err := auth.GenerateAndSetTokens(*user, login, httptest.NewRequest(http.MethodPost, "/login", nil))
+		assert.NoError(t, err)
+
+		r := httptest.NewRequest(http.MethodDelete, "/", nil)
+		for _, cookie := range login.Result().Cookies() {
+			r.AddCookie(cookie)
+			if cookie.Name == auth.CSRFCookieName {
+				r.Header.Set(auth.CSRFHeader, cookie.Value)
+			}
+		}
+		assert.Equal(t, http.StatusNoContent, serve(Authenticate(noContent), r).Code)
+	})
+}
@@ -8,43 +8,75 @@ import (
 	"slices"
 
 	"main.go/constants"
+	appErrors "main.go/errors"
 	"main.go/pkg/utils"
 	"main.go/services/auth"
 	"main.go/types"
 )
 
-var AuthorizeAdmin = CreateAuthorizationMiddleware([]types.UserRole{types.SuperAdmin, types.Admin}, userLookup)
-var AuthorizeSuperAdmin = CreateAuthorizationMiddleware([]types.UserRole{types.SuperAdmin}, userLookup)
+// AuthorizePermission allows the request only when one of the roles of the user is assigned the given permission.
+func AuthorizePermission(permission types.Permission) func(next http.HandlerFunc) http.HandlerFunc {
+	if userLookup == nil {
+		panic("middlewares.Setup must be called before the routes are registered")
+	}
+	return CreatePermissionMiddleware(permission, userLookup)
+}
 
-func CreateAuthorizationMiddleware(allowedRoles []types.UserRole, userFetcher types.IUserFetcher) func(next http.HandlerFunc) http.HandlerFunc {
+// requests authenticated by an api key are authorized by the scopes of the key only, the roles of the key owner are not checked.
+func CreatePermissionMiddleware(permission types.Permission, userFetcher types.IUserFetcher) func(next http.HandlerFunc) http.HandlerFunc {
 	return func(next http.HandlerFunc) http.HandlerFunc {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal := GetPrincipal(r)
+			if principal.IsApiKey() {
+				if !principal.CanUsePermission(permission) {
+					auth.DenyPermission(w)
+					return
+				}
+
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			userId, err := utils.GetUserIdFromToken(r)
 			if err != nil {
 				auth.DenyPermission(w)
 				return
 			}
 
-			userRoles, err := userFetcher.GetUserRolesByUserId(*userId)
+			userPermissions, err := userFetcher.GetUserPermissions(r.Context(), *userId)
 			if err != nil {
 				auth.Unauthorized(w)
 				return
 			}
 
-			hasAllowedRole := false
-			for _, userRole := range userRoles {
-				isContaining := slices.Contains(allowedRoles, types.UserRole(userRole.Role.Name))
-				if isContaining {
-					hasAllowedRole = true
-				}
+			if !slices.Contains(userPermissions, permission) {
+				auth.DenyPermission(w)
+				return
 			}
 
-			if !hasAllowedRole {
-				auth.Unauthorized(w)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// allows the users to act on their own account (the id inside the path) and the users that have the permission to act on any account.
+func AuthorizeSelfOrPermission(param string, permission types.Permission) func(next http.HandlerFunc) http.HandlerFunc {
+	authorizePermission := AuthorizePermission(permission)
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			targetUserId, receivedStr, err := utils.GetValidateId(r, param)
+			if err != nil {
+				utils.WriteError(w, http.StatusBadRequest, appErrors.NewInvalidIDError("user", receivedStr))
 				return
 			}
 
-			next.ServeHTTP(w, r)
+			userId, err := utils.GetUserIdFromToken(r)
+			if err == nil && !GetPrincipal(r).IsApiKey() && *userId == *targetUserId {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			authorizePermission(next).ServeHTTP(w, r)
 		})
 	}
 }
@@ -0,0 +1,108 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"main.go/constants"
+	"main.go/pkg/models"
+	"main.go/types"
+)
+
+// the permissions of every user, the calls are counted to check the api keys do not use the roles of their owner.
+type fakeUserFetcher struct {
+	permissions []types.Permission
+	calls       int
+}
+
+func (f *fakeUserFetcher) GetUserById(ctx context.Context, Id uint) (*models.User, error) {
+	return &models.User{}, nil
+}
+
+func (f *fakeUserFetcher) GetUserRolesByUserId(ctx context.Context, Id uint) ([]models.UserRoles, error) {
+	return nil, nil
+}
+
+func (f *fakeUserFetcher) GetUserPermissions(ctx context.Context, Id uint) ([]types.Permission, error) {
+	f.calls++
+	return f.permissions, nil
+}
+
+// a request authenticated as the user, by an api key with the given scopes when scopes is not nil.
+func authenticatedRequest(userId uint, scopes []string, pathId string) *http.Request {
+	r := httptest.NewRequest(http.MethodDelete, "/", nil)
+	r.SetPathValue(constants.IdUrlPathKey, pathId)
+
+	principal := &types.Principal{UserID: userId}
+	if scopes != nil {
+		principal.ApiKeyID = new(uint)
+		principal.Scopes = scopes
+	}
+	ctx := context.WithValue(r.Context(), constants.TokenPayload, jwt.MapClaims{"userId": float64(userId)})
+	ctx = context.WithValue(ctx, constants.PrincipalKey, principal)
+	return r.WithContext(ctx)
+}
+
+func TestCreatePermissionMiddleware(t *testing.T) {
+	tests := []struct {
+		name        string
+		scopes      []string
+		permissions []types.Permission
+		expected    int
+		usesRoles   bool
+	}{
+		{name: "Should allow an api key with the scope", scopes: []string{"products:write"}, expected: http.StatusNoContent},
+		{name: "Should refuse an api key without the scope even if its owner has the permission", scopes: []string{"products:read"}, permissions: []types.Permission{types.PermProductsDelete}, expected: http.StatusForbidden},
+		{name: "Should allow a user with the permission", permissions: []types.Permission{types.PermProductsDelete}, expected: http.StatusNoContent, usesRoles: true},
+		{name: "Should refuse a user without the permission", permissions: []types.Permission{types.PermProductsUpdate}, expected: http.StatusForbidden, usesRoles: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fetcher := &fakeUserFetcher{permissions: test.permissions}
+			handler := CreatePermissionMiddleware(types.PermProductsDelete, fetcher)(noContent)
+
+			res := serve(handler, authenticatedRequest(1, test.scopes, "1"))
+			assert.Equal(t, test.expected, res.Code)
+			assert.Equal(t, test.usesRoles, fetcher.calls > 0)
+		})
+	}
+
+	t.Run("Should refuse a request without token claims", func(t *testing.T) {
+		handler := CreatePermissionMiddleware(types.PermProductsDelete, &fakeUserFetcher{})(noContent)
+		assert.Equal(t, http.StatusForbidden, serve(handler, httptest.NewRequest(http.MethodDelete, "/", nil)).Code)
+	})
+}
+
+func TestAuthorizeSelfOrPermission(t *testing.T) {
+	DB := setupTestLookups(t)
+	admin := createTestUser(t, DB, types.PermUsersDelete)
+	user := createTestUser(t, DB)
+	other := createTestUser(t, DB)
+
+	tests := []struct {
+		name     string
+		userId   uint
+		scopes   []string
+		pathId   string
+		expected int
+	}{
+		{name: "Should allow the user on its own account", userId: user.ID, pathId: fmt.Sprint(user.ID), expected: http.StatusNoContent},
+		{name: "Should refuse the user on another account", userId: user.ID, pathId: fmt.Sprint(other.ID), expected: http.StatusForbidden},
+		{name: "Should allow the user with the permission on another account", userId: admin.ID, pathId: fmt.Sprint(other.ID), expected: http.StatusNoContent},
+		{name: "Should refuse an api key without the scope on the account of its owner", userId: user.ID, scopes: []string{"users:read"}, pathId: fmt.Sprint(user.ID), expected: http.StatusForbidden},
+		{name: "Should allow an api key with the scope", userId: user.ID, scopes: []string{"users:delete"}, pathId: fmt.Sprint(other.ID), expected: http.StatusNoContent},
+		{name: "Should refuse an invalid id", userId: user.ID, pathId: "abc", expected: http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := AuthorizeSelfOrPermission(constants.IdUrlPathKey, types.PermUsersDelete)(noContent)
+			res := serve(handler, authenticatedRequest(test.userId, test.scopes, test.pathId))
+			assert.Equal(t, test.expected, res.Code)
+		})
+	}
+}
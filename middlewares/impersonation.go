@@ -0,0 +1,84 @@
+package middlewares
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+	appErrors "main.go/errors"
+	"main.go/pkg/models"
+	"main.go/pkg/utils"
+	"main.go/types"
+)
+
+type ImpersonationLookup struct {
+	DB *gorm.DB
+}
+
+func (i *ImpersonationLookup) GetSessionById(ctx context.Context, id uint) (*models.ImpersonationSession, error) {
+	var session models.ImpersonationSession
+	err := i.DB.WithContext(ctx).First(&session, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (i *ImpersonationLookup) LogRequest(ctx context.Context, request *models.ImpersonationRequest) error {
+	return i.DB.WithContext(ctx).Create(request).Error
+}
+
+func NewImpersonationLookup(DB *gorm.DB) *ImpersonationLookup {
+	return &ImpersonationLookup{DB: DB}
+}
+
+var impersonationLookup *ImpersonationLookup
+
+// serves the request and logs it when it was made with an impersonation token.
+func serveAuthenticated(next http.HandlerFunc, w http.ResponseWriter, r *http.Request) {
+	principal := GetPrincipal(r)
+	if !principal.IsImpersonated() {
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	appResponse := &types.AppResponse{ResponseWriter: w}
+	next.ServeHTTP(appResponse, r)
+
+	statusCode := appResponse.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+
+	err := impersonationLookup.LogRequest(r.Context(), &models.ImpersonationRequest{
+		SessionID:  *principal.ImpersonationSessionID,
+		Method:     r.Method,
+		Path:       truncate(r.URL.RequestURI(), 256),
+		StatusCode: statusCode,
+		CreatedAt:  time.Now(),
+	})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to log the impersonated request", "error", err)
+	}
+}
+
+func truncate(str string, maxLen int) string {
+	if len(str) > maxLen {
+		return str[:maxLen]
+	}
+	return str
+}
+
+// sensitive actions (e.g changing the password, paying, deleting the account) can only be done by the user itself.
+func BlockImpersonation(next http.HandlerFunc) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if GetPrincipal(r).IsImpersonated() {
+			utils.WriteError(w, http.StatusForbidden, appErrors.ErrNotAllowedWhileImpersonating)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,93 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"main.go/constants"
+	"main.go/pkg/models"
+	"main.go/services/auth"
+	"main.go/types"
+)
+
+func TestBlockImpersonation(t *testing.T) {
+	impersonatorId, sessionId := uint(1), uint(3)
+	tests := []struct {
+		name      string
+		principal *types.Principal
+		expected  int
+	}{
+		{name: "Should allow the user itself", principal: &types.Principal{UserID: 2}, expected: http.StatusNoContent},
+		{name: "Should refuse an impersonated request", principal: &types.Principal{UserID: 2, ImpersonatorID: &impersonatorId, ImpersonationSessionID: &sessionId}, expected: http.StatusForbidden},
+		{name: "Should allow a request without principal", expected: http.StatusNoContent},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodDelete, "/", nil)
+			if test.principal != nil {
+				r = r.WithContext(context.WithValue(r.Context(), constants.PrincipalKey, test.principal))
+			}
+			assert.Equal(t, test.expected, serve(BlockImpersonation(noContent), r).Code)
+		})
+	}
+}
+
+func TestAuthenticateImpersonation(t *testing.T) {
+	DB := setupTestLookups(t)
+	admin := createTestUser(t, DB, types.PermUsersImpersonate)
+	target := createTestUser(t, DB)
+	other := createTestUser(t, DB)
+
+	now := time.Now()
+	ended := now.Add(-time.Minute)
+	tests := []struct {
+		name     string
+		session  models.ImpersonationSession
+		target   *models.User
+		expected int
+	}{
+		{name: "Should accept an active session", session: models.ImpersonationSession{ExpiresAt: now.Add(time.Hour)}, target: target, expected: http.StatusNoContent},
+		{name: "Should refuse an expired session", session: models.ImpersonationSession{ExpiresAt: now.Add(-time.Second)}, target: target, expected: http.StatusUnauthorized},
+		{name: "Should refuse an ended session", session: models.ImpersonationSession{ExpiresAt: now.Add(time.Hour), EndedAt: &ended}, target: target, expected: http.StatusUnauthorized},
+		{name: "Should refuse the token of another target", session: models.ImpersonationSession{ExpiresAt: now.Add(time.Hour)}, target: other, expected: http.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			session := test.session
+			session.ImpersonatorID = admin.ID
+			session.TargetUserID = target.ID
+			session.Reason = "support"
+			assert.NoError(t, DB.Create(&session).Error)
+
+			token, err := auth.GenerateImpersonationToken(test.target, admin.ID, session.ID, now.Add(time.Hour))
+			assert.NoError(t, err)
+
+			res := serve(Authenticate(noContent), bearerRequest(t, http.MethodGet, token))
+			assert.Equal(t, test.expected, res.Code)
+			if test.expected != http.StatusNoContent {
+				return
+			}
+			assert.Equal(t, fmt.Sprint(admin.ID), res.Header().Get(auth.ImpersonatedByHeader))
+
+			var logged int64
+			DB.Model(&models.ImpersonationRequest{}).Where("session_id = ?", session.ID).Count(&logged)
+			assert.Equal(t, int64(1), logged)
+		})
+	}
+
+	t.Run("Should refuse the session after it is ended", func(t *testing.T) {
+		session := models.ImpersonationSession{ImpersonatorID: admin.ID, TargetUserID: target.ID, Reason: "support", ExpiresAt: now.Add(time.Hour)}
+		assert.NoError(t, DB.Create(&session).Error)
+		token, err := auth.GenerateImpersonationToken(target, admin.ID, session.ID, now.Add(time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, serve(Authenticate(noContent), bearerRequest(t, http.MethodGet, token)).Code)
+
+		assert.NoError(t, DB.Model(&session).Update("ended_at", time.Now()).Error)
+		assert.Equal(t, http.StatusUnauthorized, serve(Authenticate(noContent), bearerRequest(t, http.MethodGet, token)).Code)
+	})
+}
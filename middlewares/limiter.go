@@ -0,0 +1,221 @@
+package middlewares
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"main.go/pkg/utils"
+)
+
+// the failures state of one key (e.g an account or an ip).
+type AttemptState struct {
+	Failures     int
+	LastFailure  time.Time
+	BlockedUntil time.Time
+	LockedOut    bool
+	// the reserved attempts that did not fail or succeed yet.
+	Pending      int
+	LastReserved time.Time
+}
+
+// LimiterBackend stores the attempts states, the in-memory backend is used by default
+// and a shared backend can be plugged in when the api runs on multiple instances.
+type LimiterBackend interface {
+	Get(key string) (AttemptState, bool)
+	Set(key string, state AttemptState)
+	Delete(key string)
+}
+
+type MemoryLimiterBackend struct {
+	mu     sync.Mutex
+	states map[string]AttemptState
+	// entries not touched for this duration are removed during the cleanup.
+	staleAfter time.Duration
+	lastClean  time.Time
+}
+
+func NewMemoryLimiterBackend(staleAfter time.Duration) *MemoryLimiterBackend {
+	return &MemoryLimiterBackend{
+		states:     make(map[string]AttemptState),
+		staleAfter: staleAfter,
+		lastClean:  time.Now(),
+	}
+}
+
+func (b *MemoryLimiterBackend) Get(key string) (AttemptState, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state, ok := b.states[key]
+	return state, ok
+}
+
+func (b *MemoryLimiterBackend) Set(key string, state AttemptState) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.states[key] = state
+	b.cleanStale()
+}
+
+func (b *MemoryLimiterBackend) Delete(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.states, key)
+}
+
+// must be called while holding the lock.
+func (b *MemoryLimiterBackend) cleanStale() {
+	now := time.Now()
+	if now.Sub(b.lastClean) < b.staleAfter {
+		return
+	}
+
+	for key, state := range b.states {
+		if now.Sub(state.LastFailure) > b.staleAfter && now.Sub(state.LastReserved) > b.staleAfter && now.After(state.BlockedUntil) {
+			delete(b.states, key)
+		}
+	}
+	b.lastClean = now
+}
+
+type LimiterOptions struct {
+	// failures allowed without any delay.
+	FreeAttempts int
+	// the key is locked out for LockoutDuration when it reaches this number of failures.
+	MaxFailures     int
+	BaseDelay       time.Duration
+	MaxDelay        time.Duration
+	LockoutDuration time.Duration
+	// the failures are forgotten when no failure happens during this window.
+	Window time.Duration
+}
+
+// Limiter tracks the failed attempts per key, after the free attempts each failure blocks the key
+// for an exponentially growing delay until the max failures is reached, then the key is locked out.
+type Limiter struct {
+	backend LimiterBackend
+	options LimiterOptions
+	mu      sync.Mutex
+	now     func() time.Time
+}
+
+func NewLimiter(backend LimiterBackend, options LimiterOptions) *Limiter {
+	return &Limiter{
+		backend: backend,
+		options: options,
+		now:     time.Now,
+	}
+}
+
+// returns how long the key must wait before its next attempt, zero means the attempt is allowed and reserved. The
+// check and the reservation are done at once and the pending attempts count as failures, so the concurrent attempts
+// can not all pass the check before the first of them fails. The reservation is released by RegisterFailure or
+// Release.
+func (l *Limiter) Reserve(key string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	state := l.current(key, now)
+	if now.Before(state.BlockedUntil) {
+		return state.BlockedUntil.Sub(now)
+	}
+	if state.Pending > 0 {
+		presumed := state.Failures + state.Pending
+		if presumed >= l.options.MaxFailures {
+			return l.options.LockoutDuration
+		}
+		if presumed > l.options.FreeAttempts {
+			return l.backoffDelay(presumed - l.options.FreeAttempts)
+		}
+	}
+
+	state.Pending++
+	state.LastReserved = now
+	l.backend.Set(key, state)
+	return 0
+}
+
+// releases the reservation of an attempt that did not fail.
+func (l *Limiter) Release(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state, ok := l.backend.Get(key)
+	if !ok || state.Pending == 0 {
+		return
+	}
+	state.Pending--
+	l.backend.Set(key, state)
+}
+
+// registers a failed attempt and releases its reservation, lockedOut is true only for the failure that caused the
+// lockout.
+func (l *Limiter) RegisterFailure(key string) (state AttemptState, lockedOut bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	state = l.current(key, now)
+	if state.Pending > 0 {
+		state.Pending--
+	}
+
+	state.Failures++
+	state.LastFailure = now
+
+	if state.Failures >= l.options.MaxFailures {
+		lockedOut = !state.LockedOut
+		state.LockedOut = true
+		state.BlockedUntil = now.Add(l.options.LockoutDuration)
+	} else if state.Failures > l.options.FreeAttempts {
+		state.BlockedUntil = now.Add(l.backoffDelay(state.Failures - l.options.FreeAttempts))
+	}
+
+	l.backend.Set(key, state)
+	return state, lockedOut
+}
+
+func (l *Limiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.backend.Delete(key)
+}
+
+// the state of the key, the failures are forgotten once the lockout ended or no failure happened during the window.
+// It must be called while holding the lock.
+func (l *Limiter) current(key string, now time.Time) AttemptState {
+	state, ok := l.backend.Get(key)
+	if !ok {
+		return AttemptState{}
+	}
+
+	isExpired := now.After(state.BlockedUntil) && (state.LockedOut || now.Sub(state.LastFailure) > l.options.Window)
+	if isExpired {
+		return AttemptState{Pending: state.Pending, LastReserved: state.LastReserved}
+	}
+	return state
+}
+
+func (l *Limiter) backoffDelay(exceededAttempts int) time.Duration {
+	delay := float64(l.options.BaseDelay) * math.Pow(2, float64(exceededAttempts-1))
+	if delay > float64(l.options.MaxDelay) {
+		return l.options.MaxDelay
+	}
+
+	return time.Duration(delay)
+}
+
+// responds with 429 and the "Retry-After" header in seconds.
+func WriteTooManyRequests(w http.ResponseWriter, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	utils.WriteError(w, http.StatusTooManyRequests, fmt.Errorf("too many failed attempts, try again after %v seconds", seconds))
+}
@@ -0,0 +1,130 @@
+package middlewares
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testLimiterOptions = LimiterOptions{
+	FreeAttempts:    2,
+	MaxFailures:     6,
+	BaseDelay:       time.Second,
+	MaxDelay:        3 * time.Second,
+	LockoutDuration: time.Minute,
+	Window:          10 * time.Minute,
+}
+
+func newTestLimiter(now *time.Time) *Limiter {
+	limiter := NewLimiter(NewMemoryLimiterBackend(time.Hour), testLimiterOptions)
+	limiter.now = func() time.Time { return *now }
+	return limiter
+}
+
+// reserves an attempt and registers its failure, the attempt must be allowed.
+func failAttempt(t *testing.T, limiter *Limiter, key string) (AttemptState, bool) {
+	t.Helper()
+	assert.Zero(t, limiter.Reserve(key))
+	return limiter.RegisterFailure(key)
+}
+
+// returns how long the key must wait without keeping the reservation.
+func retryAfter(limiter *Limiter, key string) time.Duration {
+	wait := limiter.Reserve(key)
+	if wait == 0 {
+		limiter.Release(key)
+	}
+	return wait
+}
+
+func TestLimiter(t *testing.T) {
+	t.Run("Should grow the delay exponentially after the free attempts up to the max delay", func(t *testing.T) {
+		now := time.Now()
+		limiter := newTestLimiter(&now)
+
+		for _, expected := range []time.Duration{0, 0, time.Second, 2 * time.Second, 3 * time.Second} {
+			failAttempt(t, limiter, "a")
+			assert.Equal(t, expected, retryAfter(limiter, "a"))
+			now = now.Add(expected)
+		}
+	})
+
+	t.Run("Should forget the failures after the window", func(t *testing.T) {
+		now := time.Now()
+		limiter := newTestLimiter(&now)
+		for range 3 {
+			failAttempt(t, limiter, "a")
+			now = now.Add(time.Second)
+		}
+
+		now = now.Add(testLimiterOptions.Window + time.Second)
+		state, _ := failAttempt(t, limiter, "a")
+		assert.Equal(t, 1, state.Failures)
+		assert.Zero(t, retryAfter(limiter, "a"))
+	})
+
+	t.Run("Should lock the key out once at the max failures", func(t *testing.T) {
+		now := time.Now()
+		limiter := newTestLimiter(&now)
+
+		var lockouts int
+		for range testLimiterOptions.MaxFailures {
+			state, lockedOut := failAttempt(t, limiter, "a")
+			if lockedOut {
+				lockouts++
+			}
+			now = state.BlockedUntil
+		}
+		assert.Equal(t, 1, lockouts)
+
+		now = now.Add(-time.Second)
+		assert.Equal(t, time.Second, retryAfter(limiter, "a"))
+
+		now = now.Add(2 * time.Second)
+		state, lockedOut := failAttempt(t, limiter, "a")
+		assert.False(t, lockedOut)
+		assert.Equal(t, 1, state.Failures)
+	})
+
+	t.Run("Should count the pending attempts as failures", func(t *testing.T) {
+		now := time.Now()
+		limiter := newTestLimiter(&now)
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		allowed := 0
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if limiter.Reserve("a") == 0 {
+					mu.Lock()
+					allowed++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, testLimiterOptions.FreeAttempts+1, allowed)
+
+		for range allowed {
+			limiter.RegisterFailure("a")
+		}
+		assert.Equal(t, time.Second, limiter.Reserve("a"))
+	})
+
+	t.Run("Should release the reservation of an attempt that did not fail", func(t *testing.T) {
+		now := time.Now()
+		limiter := newTestLimiter(&now)
+		for range testLimiterOptions.FreeAttempts + 1 {
+			assert.Zero(t, limiter.Reserve("a"))
+		}
+		assert.NotZero(t, limiter.Reserve("a"))
+
+		limiter.Release("a")
+		assert.Zero(t, limiter.Reserve("a"))
+		assert.Zero(t, limiter.Reserve("b"), "the keys are independent")
+	})
+}
@@ -1,13 +1,15 @@
 package middlewares
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 
+	"main.go/pkg/utils"
 	"main.go/types"
 )
 
+// writes one access log record per request, the request id and the user id are added from the request context.
 func Logger(next http.Handler) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
@@ -16,7 +18,27 @@ func Logger(next http.Handler) http.HandlerFunc {
 
 		next.ServeHTTP(AppResponse, r)
 
-		duration := time.Since(start)
-		log.Printf("%s %s %v %v", r.Method, r.RequestURI, duration, AppResponse.StatusCode)
+		statusCode := AppResponse.StatusCode
+		if statusCode == 0 {
+			statusCode = http.StatusOK
+		}
+
+		level := slog.LevelInfo
+		if statusCode >= 500 {
+			level = slog.LevelError
+		} else if statusCode >= 400 {
+			level = slog.LevelWarn
+		}
+
+		// the pattern is set on the request by the router, so it is empty for the requests that matched no route
+		slog.LogAttrs(r.Context(), level, "request",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("route", r.Pattern),
+			slog.Int("status", statusCode),
+			slog.Int("bytes", AppResponse.BytesWritten),
+			slog.Float64("latencyMs", float64(time.Since(start).Microseconds())/1000),
+			slog.String("ip", utils.GetClientIP(r)),
+		)
 	})
-}
\ No newline at end of file
+}
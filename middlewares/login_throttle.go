@@ -0,0 +1,107 @@
+package middlewares
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"main.go/pkg/utils"
+	"main.go/types"
+)
+
+// the ip limiter is more tolerant than the account limiter because many users can share the same ip.
+var accountLimiterOptions = LimiterOptions{
+	FreeAttempts:    3,
+	MaxFailures:     10,
+	BaseDelay:       time.Second,
+	MaxDelay:        time.Minute,
+	LockoutDuration: 15 * time.Minute,
+	Window:          15 * time.Minute,
+}
+
+var ipLimiterOptions = LimiterOptions{
+	FreeAttempts:    10,
+	MaxFailures:     50,
+	BaseDelay:       time.Second,
+	MaxDelay:        time.Minute,
+	LockoutDuration: 30 * time.Minute,
+	Window:          15 * time.Minute,
+}
+
+// a lockout that happened because of the last failed attempt.
+type Lockout struct {
+	Reason      string
+	IP          string
+	Email       string
+	Failures    int
+	LockedUntil time.Time
+}
+
+// LoginThrottler tracks the failed logins per account and per ip.
+type LoginThrottler struct {
+	accounts *Limiter
+	ips      *Limiter
+}
+
+func NewLoginThrottler(backend LimiterBackend) *LoginThrottler {
+	return &LoginThrottler{
+		accounts: NewLimiter(backend, accountLimiterOptions),
+		ips:      NewLimiter(backend, ipLimiterOptions),
+	}
+}
+
+var loginThrottler = NewLoginThrottler(NewMemoryLimiterBackend(time.Hour))
+
+func GetLoginThrottler() *LoginThrottler {
+	return loginThrottler
+}
+
+func accountKey(email string) string {
+	return "login:account:" + strings.ToLower(email)
+}
+
+func ipKey(ip string) string {
+	return "login:ip:" + ip
+}
+
+// returns how long the client must wait before trying to login again, zero means it can try now and the attempt is
+// reserved until RegisterFailure or RegisterSuccess is called.
+func (t *LoginThrottler) Reserve(r *http.Request, email string) time.Duration {
+	if retryAfter := t.accounts.Reserve(accountKey(email)); retryAfter > 0 {
+		return retryAfter
+	}
+	if retryAfter := t.ips.Reserve(ipKey(utils.GetClientIP(r))); retryAfter > 0 {
+		t.accounts.Release(accountKey(email))
+		return retryAfter
+	}
+	return 0
+}
+
+// registers a failed login and returns the lockouts caused by it.
+func (t *LoginThrottler) RegisterFailure(r *http.Request, email string) []Lockout {
+	ip := utils.GetClientIP(r)
+	lockouts := []Lockout{}
+
+	accountState, lockedOut := t.accounts.RegisterFailure(accountKey(email))
+	if lockedOut {
+		lockouts = append(lockouts, Lockout{Reason: types.LockoutReasonAccount, IP: ip, Email: email, Failures: accountState.Failures, LockedUntil: accountState.BlockedUntil})
+	}
+
+	ipState, lockedOut := t.ips.RegisterFailure(ipKey(ip))
+	if lockedOut {
+		lockouts = append(lockouts, Lockout{Reason: types.LockoutReasonIP, IP: ip, Email: email, Failures: ipState.Failures, LockedUntil: ipState.BlockedUntil})
+	}
+
+	return lockouts
+}
+
+// the failures of the account are forgotten after a successful login, the ip failures are kept
+// so one valid account can not be used to reset the ip failures.
+func (t *LoginThrottler) RegisterSuccess(r *http.Request, email string) {
+	t.accounts.Reset(accountKey(email))
+	t.ips.Release(ipKey(utils.GetClientIP(r)))
+}
+
+func (t *LoginThrottler) UnlockAccount(email string) {
+	t.accounts.Reset(accountKey(email))
+}
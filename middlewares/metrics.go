@@ -0,0 +1,43 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"time"
+
+	"main.go/pkg/metrics"
+	"main.go/services/auth"
+	"main.go/types"
+)
+
+// records the count and the latency of the requests by route pattern, it must wrap the router directly
+// so the pattern set by the router is visible after the request is handled.
+func Metrics(next http.Handler) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		appResponse := &types.AppResponse{ResponseWriter: w}
+		next.ServeHTTP(appResponse, r)
+
+		statusCode := appResponse.StatusCode
+		if statusCode == 0 {
+			statusCode = http.StatusOK
+		}
+
+		metrics.ObserveRequest(r.Pattern, r.Method, statusCode, time.Since(start))
+	})
+}
+
+// the scrapers must send "Authorization: Bearer <token>" when a token is configured, otherwise the endpoint is public
+// and must be hidden by the proxy.
+func MetricsHandler(token string) http.HandlerFunc {
+	handler := metrics.Handler()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+token)) != 1 {
+			auth.Unauthorized(w)
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,43 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"main.go/pkg/logger"
+)
+
+const RequestIDHeader = "X-Request-Id"
+
+const maxRequestIDLength = 64
+
+// the id sent by the proxy (or the client) is kept so the logs can be correlated across services, otherwise a new one is generated.
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, char := range id {
+		isAlphaNumeric := (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9')
+		if !isAlphaNumeric && char != '-' && char != '_' && char != '.' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sets the "X-Request-Id" of the request and the response and adds it to the request context,
+// it must wrap the logger middleware so the access log includes the id.
+func RequestID(next http.Handler) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestId := r.Header.Get(RequestIDHeader)
+		if !isValidRequestID(requestId) {
+			requestId = uuid.NewString()
+		}
+		w.Header().Set(RequestIDHeader, requestId)
+
+		ctx := logger.WithRequestInfo(r.Context(), &logger.RequestInfo{ID: requestId})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,16 @@
+package middlewares
+
+import (
+	"gorm.io/gorm"
+	"main.go/config"
+)
+
+// builds the lookups the middlewares read the database with, it must be called before the routes are registered
+// since the authorization middlewares are bound to the user lookup when they are created.
+func Setup(DB *gorm.DB, cfg config.Config) error {
+	userLookup = NewUserLookup(DB, userCacheOptions(cfg))
+	apiKeyLookup = NewApiKeyLookup(DB)
+	impersonationLookup = NewImpersonationLookup(DB)
+	auditLookup = NewAuditLookup(DB)
+	return nil
+}
@@ -0,0 +1,49 @@
+package middlewares
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"main.go/pkg/tracing"
+)
+
+// the probes and the scrapes are called every few seconds and would flood the traces.
+var untracedPaths = map[string]bool{"/livez": true, "/healthz": true, "/readyz": true, "/metrics": true}
+
+// starts a span per request (continuing the trace of the caller when it sends a traceparent header) and
+// returns its id in the X-Trace-Id header, it must wrap the router like Metrics so the span can be named
+// after the matched route pattern once the request is handled.
+func Tracing(next http.Handler) http.Handler {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if traceId := tracing.TraceID(r.Context()); traceId != "" {
+			w.Header().Set(tracing.TraceIDHeader, traceId)
+		}
+
+		next.ServeHTTP(w, r)
+
+		if r.Pattern == "" {
+			return
+		}
+
+		route := r.Pattern
+		if _, path, found := strings.Cut(r.Pattern, " "); found {
+			route = path
+		}
+
+		span := trace.SpanFromContext(r.Context())
+		span.SetName(r.Method + " " + route)
+		span.SetAttributes(semconv.HTTPRoute(route))
+	})
+
+	return otelhttp.NewHandler(handler, "http.request",
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method
+		}),
+		otelhttp.WithFilter(func(r *http.Request) bool {
+			return !untracedPaths[r.URL.Path]
+		}),
+	)
+}
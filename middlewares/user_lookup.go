@@ -1,31 +1,134 @@
 package middlewares
 
 import (
+	"context"
+	"fmt"
 
-	"main.go/internal/database"
+	"gorm.io/gorm"
+	"main.go/config"
+	"main.go/pkg/cache"
 	"main.go/pkg/models"
+	"main.go/types"
 )
 
-type UserLookup struct {}
+// UserLookup caches the users, their roles and their permissions because they are looked up on every authenticated request,
+// the entries must be invalidated when any of them is changed.
+type UserLookup struct {
+	DB          *gorm.DB
+	users       cache.Cache[models.User]
+	roles       cache.Cache[[]models.UserRoles]
+	permissions cache.Cache[[]types.Permission]
+}
+
+func userCacheKey(Id uint) string {
+	return fmt.Sprintf("user:%v", Id)
+}
+
+func (u *UserLookup) GetUserById(ctx context.Context, Id uint) (*models.User, error) {
+	// a copy is returned each time so the cached user is not changed by the handlers
+	if user, ok := u.users.Get(userCacheKey(Id)); ok {
+		return &user, nil
+	}
 
-func (u *UserLookup) GetUserById(Id uint) (*models.User, error) {
 	var user models.User
-	err := database.DB.Where("id = ?", Id).First(&user).Error
+	err := u.DB.WithContext(ctx).Where("id = ?", Id).First(&user).Error
 	if err != nil {
 		return nil, err
 	}
+	u.users.Set(userCacheKey(Id), user)
+
 	return &user, nil
 }
 
-func (u *UserLookup) GetUserRolesByUserId(Id uint) ([]models.UserRoles, error) {
+func (u *UserLookup) GetUserRolesByUserId(ctx context.Context, Id uint) ([]models.UserRoles, error) {
+	if roles, ok := u.roles.Get(userCacheKey(Id)); ok {
+		return roles, nil
+	}
+
 	var roles []models.UserRoles
-	err := database.DB.Where("user_id = ?", Id).Preload("Role").Find(&roles).Error
+	err := u.DB.WithContext(ctx).Where("user_id = ?", Id).Preload("Role").Find(&roles).Error
 	if err != nil {
 		return nil, err
 	}
+	u.roles.Set(userCacheKey(Id), roles)
+
 	return roles, nil
 }
 
-func NewUserLookup() *UserLookup {
-	return &UserLookup{}
-}
\ No newline at end of file
+// returns the distinct permissions of all the roles assigned to the user.
+func (u *UserLookup) GetUserPermissions(ctx context.Context, Id uint) ([]types.Permission, error) {
+	if permissions, ok := u.permissions.Get(userCacheKey(Id)); ok {
+		return permissions, nil
+	}
+
+	var permissions []types.Permission
+	err := u.DB.WithContext(ctx).Model(&models.Permission{}).
+		Distinct("permissions.name").
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Joins("JOIN user_roles ON user_roles.role_id = role_permissions.role_id").
+		Where("user_roles.user_id = ?", Id).
+		Pluck("permissions.name", &permissions).Error
+	if err != nil {
+		return nil, err
+	}
+	u.permissions.Set(userCacheKey(Id), permissions)
+
+	return permissions, nil
+}
+
+// removes the cached user, roles and permissions of the given user.
+func (u *UserLookup) InvalidateUser(Id uint) {
+	u.users.Delete(userCacheKey(Id))
+	u.roles.Delete(userCacheKey(Id))
+	u.permissions.Delete(userCacheKey(Id))
+}
+
+// changing a role affects all the users assigned to it, so the roles and permissions of all the users are removed.
+func (u *UserLookup) InvalidateRoles() {
+	u.roles.Purge()
+	u.permissions.Purge()
+}
+
+func (u *UserLookup) Stats() map[string]cache.Stats {
+	return map[string]cache.Stats{
+		"users":       u.users.Stats(),
+		"roles":       u.roles.Stats(),
+		"permissions": u.permissions.Stats(),
+	}
+}
+
+func NewUserLookup(DB *gorm.DB, options cache.Options) *UserLookup {
+	return &UserLookup{
+		DB:          DB,
+		users:       cache.NewLRU[models.User](options),
+		roles:       cache.NewLRU[[]models.UserRoles](options),
+		permissions: cache.NewLRU[[]types.Permission](options),
+	}
+}
+
+func userCacheOptions(cfg config.Config) cache.Options {
+	return cache.Options{
+		TTL:     cfg.USER_CACHE_TTL,
+		MaxSize: cfg.USER_CACHE_SIZE,
+	}
+}
+
+// removes the cached lookups of the user, must be called after changing the user or its roles.
+func InvalidateUserCache(Id uint) {
+	userLookup.InvalidateUser(Id)
+}
+
+// returns the cached permissions of the user, for the handlers that compare the permissions of two users.
+func UserPermissions(ctx context.Context, Id uint) ([]types.Permission, error) {
+	return userLookup.GetUserPermissions(ctx, Id)
+}
+
+// must be called after changing a role or its permissions.
+func InvalidateRolesCache() {
+	userLookup.InvalidateRoles()
+}
+
+// returns the hits and misses of the user lookup caches.
+func UserCacheStats() map[string]cache.Stats {
+	return userLookup.Stats()
+}
@@ -0,0 +1,89 @@
+// Package audit computes the changes saved in the audit logs.
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"main.go/pkg/models"
+)
+
+// fields that change on every write, keeping them would make every entry noisy.
+var ignoredFields = map[string]bool{
+	"updatedAt": true,
+}
+
+// returns the changed fields between two snapshots, the snapshots are compared by their json form so the keys are
+// the same keys returned by the api. A nil snapshot means the resource did not exist (created) or no longer exists (deleted).
+//
+// snapshots that are not json objects (e.g a list of images) are compared as a whole and saved under the "value" key.
+func Diff(before, after any) (map[string]models.AuditChange, error) {
+	beforeValue, err := normalize(before)
+	if err != nil {
+		return nil, err
+	}
+	afterValue, err := normalize(after)
+	if err != nil {
+		return nil, err
+	}
+
+	beforeFields, beforeIsObject := asObject(beforeValue)
+	afterFields, afterIsObject := asObject(afterValue)
+	if !beforeIsObject || !afterIsObject {
+		changes := map[string]models.AuditChange{}
+		if !reflect.DeepEqual(beforeValue, afterValue) {
+			changes["value"] = models.AuditChange{Before: beforeValue, After: afterValue}
+		}
+		return changes, nil
+	}
+
+	changes := map[string]models.AuditChange{}
+	for field, beforeField := range beforeFields {
+		afterField := afterFields[field]
+		if ignoredFields[field] || reflect.DeepEqual(beforeField, afterField) {
+			continue
+		}
+		changes[field] = models.AuditChange{Before: beforeField, After: afterField}
+	}
+	for field, afterField := range afterFields {
+		if _, ok := beforeFields[field]; ok || ignoredFields[field] {
+			continue
+		}
+		changes[field] = models.AuditChange{Before: nil, After: afterField}
+	}
+
+	return changes, nil
+}
+
+// converts the snapshot to the generic json values (maps, slices, strings, float64, bool and nil).
+func normalize(snapshot any) (any, error) {
+	if snapshot == nil {
+		return nil, nil
+	}
+	if value := reflect.ValueOf(snapshot); value.Kind() == reflect.Pointer && value.IsNil() {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	var value any
+	err = json.Unmarshal(data, &value)
+	if err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// a missing snapshot is treated as an empty object so creations and deletions list every field.
+func asObject(value any) (map[string]any, bool) {
+	if value == nil {
+		return map[string]any{}, true
+	}
+
+	fields, ok := value.(map[string]any)
+	return fields, ok
+}
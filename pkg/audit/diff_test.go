@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"main.go/pkg/models"
+)
+
+type snapshot struct {
+	Name      string  `json:"name"`
+	Price     float64 `json:"price"`
+	UpdatedAt string  `json:"updatedAt"`
+}
+
+func TestDiff(t *testing.T) {
+	t.Run("Should return only the changed fields", func(t *testing.T) {
+		changes, err := Diff(
+			&snapshot{Name: "Phone", Price: 10, UpdatedAt: "2024-01-01"},
+			&snapshot{Name: "Phone", Price: 12.5, UpdatedAt: "2024-01-02"},
+		)
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]models.AuditChange{
+			"price": {Before: float64(10), After: 12.5},
+		}, changes)
+	})
+
+	t.Run("Should list every field of a created or deleted resource", func(t *testing.T) {
+		var missing *snapshot
+		created, err := Diff(missing, &snapshot{Name: "Phone", Price: 10})
+		assert.Nil(t, err)
+		assert.Equal(t, models.AuditChange{Before: nil, After: "Phone"}, created["name"])
+		assert.Len(t, created, 2)
+
+		deleted, err := Diff(&snapshot{Name: "Phone", Price: 10}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, models.AuditChange{Before: "Phone", After: nil}, deleted["name"])
+		assert.Len(t, deleted, 2)
+	})
+
+	t.Run("Should compare the snapshots that are not objects as a whole", func(t *testing.T) {
+		changes, err := Diff([]string{"a"}, []string{"a", "b"})
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]models.AuditChange{
+			"value": {Before: []any{"a"}, After: []any{"a", "b"}},
+		}, changes)
+
+		changes, err = Diff([]string{"a"}, []string{"a"})
+		assert.Nil(t, err)
+		assert.Empty(t, changes)
+	})
+}
@@ -0,0 +1,36 @@
+package cache
+
+import "time"
+
+// Cache is implemented by the in-process LRU, a shared cache (e.g redis) can be plugged in by implementing it.
+type Cache[V any] interface {
+	Get(key string) (V, bool)
+	Set(key string, value V)
+	Delete(key string)
+	// removes all the entries.
+	Purge()
+	Stats() Stats
+}
+
+type Stats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Size      int    `json:"size"`
+}
+
+// the ratio of the hits to all the lookups, returns 0 when the cache was never looked up.
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+
+	return float64(s.Hits) / float64(total)
+}
+
+// a zero ttl keeps the entries until they are evicted or deleted.
+type Options struct {
+	TTL     time.Duration
+	MaxSize int
+}
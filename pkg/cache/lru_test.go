@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU(t *testing.T) {
+	t.Run("Should return the cached value and count hits and misses", func(t *testing.T) {
+		lru := NewLRU[int](Options{TTL: time.Minute, MaxSize: 2})
+
+		_, ok := lru.Get("a")
+		assert.False(t, ok)
+
+		lru.Set("a", 1)
+		value, ok := lru.Get("a")
+		assert.True(t, ok)
+		assert.Equal(t, 1, value)
+
+		stats := lru.Stats()
+		assert.Equal(t, uint64(1), stats.Hits)
+		assert.Equal(t, uint64(1), stats.Misses)
+		assert.Equal(t, 1, stats.Size)
+	})
+
+	t.Run("Should evict the least recently used entry", func(t *testing.T) {
+		lru := NewLRU[int](Options{TTL: time.Minute, MaxSize: 2})
+		lru.Set("a", 1)
+		lru.Set("b", 2)
+		lru.Get("a")
+		lru.Set("c", 3)
+
+		_, ok := lru.Get("b")
+		assert.False(t, ok)
+		_, ok = lru.Get("a")
+		assert.True(t, ok)
+		assert.Equal(t, uint64(1), lru.Stats().Evictions)
+	})
+
+	t.Run("Should treat expired entries as misses", func(t *testing.T) {
+		now := time.Now()
+		lru := NewLRU[int](Options{TTL: time.Minute, MaxSize: 2})
+		lru.now = func() time.Time { return now }
+		lru.Set("a", 1)
+
+		lru.now = func() time.Time { return now.Add(2 * time.Minute) }
+		_, ok := lru.Get("a")
+		assert.False(t, ok)
+		assert.Equal(t, 0, lru.Stats().Size)
+	})
+
+	t.Run("Should remove deleted and purged entries", func(t *testing.T) {
+		lru := NewLRU[int](Options{TTL: time.Minute, MaxSize: 3})
+		lru.Set("a", 1)
+		lru.Set("b", 2)
+		lru.Delete("a")
+
+		_, ok := lru.Get("a")
+		assert.False(t, ok)
+
+		lru.Purge()
+		_, ok = lru.Get("b")
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,179 @@
+// Package imaging checks the uploaded images and prepares them for the storage: the format is sniffed from the
+// content, the size is limited, the metadata (exif, gps) is dropped by encoding the pixels again and the smaller
+// variants are generated.
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+	"main.go/pkg/tracing"
+)
+
+var (
+	// every error returned for an image the client sent, the handlers answer them with a bad request.
+	ErrInvalidImage      = errors.New("invalid image")
+	ErrUnsupportedFormat = fmt.Errorf("%w: the format is not supported, expected one of: jpeg, png, gif, webp", ErrInvalidImage)
+	ErrTooLarge          = fmt.Errorf("%w: the image is too large", ErrInvalidImage)
+)
+
+// the variants fit in a square of MaxSize pixels, the smaller images are not enlarged.
+type Variant struct {
+	Name    string
+	MaxSize int
+}
+
+var Variants = []Variant{
+	{Name: "thumbnail", MaxSize: 200},
+	{Name: "medium", MaxSize: 800},
+	{Name: "large", MaxSize: 1600},
+}
+
+type Options struct {
+	// the images wider or taller than this are refused before they are decoded.
+	MaxDimension int
+}
+
+// Image is an encoded image, Format is its extension without the dot (jpeg or png).
+type Image struct {
+	Content []byte
+	Format  string
+	Width   int
+	Height  int
+}
+
+type Processed struct {
+	Original Image
+	// keyed by the name of the variant.
+	Variants map[string]Image
+}
+
+// the decoders are chosen from the sniffed content type, not from the extension or the header sent by the client.
+var decoders = map[string]func(r *bytes.Reader) (image.Image, error){
+	"image/jpeg": func(r *bytes.Reader) (image.Image, error) { return jpeg.Decode(r) },
+	"image/png":  func(r *bytes.Reader) (image.Image, error) { return png.Decode(r) },
+	"image/gif":  func(r *bytes.Reader) (image.Image, error) { return gif.Decode(r) },
+	"image/webp": func(r *bytes.Reader) (image.Image, error) { return webp.Decode(r) },
+}
+
+var configDecoders = map[string]func(r *bytes.Reader) (image.Config, error){
+	"image/jpeg": func(r *bytes.Reader) (image.Config, error) { return jpeg.DecodeConfig(r) },
+	"image/png":  func(r *bytes.Reader) (image.Config, error) { return png.DecodeConfig(r) },
+	"image/gif":  func(r *bytes.Reader) (image.Config, error) { return gif.DecodeConfig(r) },
+	"image/webp": func(r *bytes.Reader) (image.Config, error) { return webp.DecodeConfig(r) },
+}
+
+// returns the sniffed content type of the image, the content types without a decoder are refused.
+func Sniff(content []byte) (string, error) {
+	contentType := http.DetectContentType(content)
+	if _, ok := decoders[contentType]; !ok {
+		return "", fmt.Errorf("%w, got '%v'", ErrUnsupportedFormat, contentType)
+	}
+	return contentType, nil
+}
+
+// Process validates the image and encodes it again with its variants: the jpegs stay jpegs and the other formats are
+// encoded as png (only the first frame of a gif is kept).
+func Process(ctx context.Context, content []byte, options Options) (*Processed, error) {
+	_, span := tracing.Start(ctx, "imaging.process", attribute.Int("imaging.size_bytes", len(content)))
+	defer span.End()
+
+	processed, err := process(content, options)
+	tracing.RecordError(span, err)
+	return processed, err
+}
+
+func process(content []byte, options Options) (*Processed, error) {
+	contentType, err := Sniff(content)
+	if err != nil {
+		return nil, err
+	}
+
+	// the size is read from the header so a small file declaring a huge image is refused before it is decoded
+	imageConfig, err := configDecoders[contentType](bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if options.MaxDimension > 0 && (imageConfig.Width > options.MaxDimension || imageConfig.Height > options.MaxDimension) {
+		return nil, fmt.Errorf("%w, it is %vx%v and the maximum is %vx%v pixels", ErrTooLarge, imageConfig.Width, imageConfig.Height, options.MaxDimension, options.MaxDimension)
+	}
+
+	img, err := decoders[contentType](bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	format := "png"
+	if contentType == "image/jpeg" {
+		format = "jpeg"
+		// the orientation is lost with the exif so it is applied to the pixels
+		img = orient(img, jpegOrientation(content))
+	}
+
+	original, err := encode(img, format)
+	if err != nil {
+		return nil, err
+	}
+	processed := &Processed{
+		Original: *original,
+		Variants: make(map[string]Image, len(Variants)),
+	}
+	for _, variant := range Variants {
+		resized, err := encode(resize(img, variant.MaxSize), format)
+		if err != nil {
+			return nil, err
+		}
+		processed.Variants[variant.Name] = *resized
+	}
+
+	return processed, nil
+}
+
+func encode(img image.Image, format string) (*Image, error) {
+	var buf bytes.Buffer
+	var err error
+	if format == "jpeg" {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	return &Image{
+		Content: buf.Bytes(),
+		Format:  format,
+		Width:   bounds.Dx(),
+		Height:  bounds.Dy(),
+	}, nil
+}
+
+// scales the image down to fit in a square of maxSize pixels, keeping its aspect ratio.
+func resize(img image.Image, maxSize int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxSize && height <= maxSize {
+		return img
+	}
+
+	if width >= height {
+		width, height = maxSize, max(1, height*maxSize/width)
+	} else {
+		width, height = max(1, width*maxSize/height), maxSize
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
@@ -0,0 +1,150 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func newImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// a jpeg with an exif segment holding only the orientation, written right after the start of image.
+func encodeJPEGWithOrientation(t *testing.T, img image.Image, orientation uint16) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientationTag)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3) // short
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+
+	content := []byte{0xFF, 0xD8, 0xFF, 0xE1}
+	content = binary.BigEndian.AppendUint16(content, uint16(len(segment)+2))
+	content = append(content, segment...)
+	return append(content, buf.Bytes()[2:]...)
+}
+
+func TestProcessRefusesUnsupportedContents(t *testing.T) {
+	contents := map[string][]byte{
+		"text":      []byte("<html><body>not an image</body></html>"),
+		"empty":     {},
+		"truncated": encodePNG(t, newImage(10, 10))[:40],
+	}
+	for name, content := range contents {
+		_, err := Process(context.Background(), content, Options{})
+		if !errors.Is(err, ErrInvalidImage) {
+			t.Errorf("expected the %v content to be refused, got %v", name, err)
+		}
+	}
+}
+
+func TestProcessRefusesImagesLargerThanTheMaximum(t *testing.T) {
+	_, err := Process(context.Background(), encodePNG(t, newImage(30, 10)), Options{MaxDimension: 20})
+	if !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("expected the image to be too large, got %v", err)
+	}
+
+	if _, err := Process(context.Background(), encodePNG(t, newImage(20, 20)), Options{MaxDimension: 20}); err != nil {
+		t.Fatalf("expected an image of the maximum size to be accepted, got %v", err)
+	}
+}
+
+func TestProcessGeneratesTheVariants(t *testing.T) {
+	processed, err := Process(context.Background(), encodePNG(t, newImage(2000, 1000)), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][2]int{"thumbnail": {200, 100}, "medium": {800, 400}, "large": {1600, 800}}
+	for name, size := range expected {
+		variant, ok := processed.Variants[name]
+		if !ok {
+			t.Fatalf("expected the %v variant", name)
+		}
+		decoded, err := png.DecodeConfig(bytes.NewReader(variant.Content))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Width != size[0] || decoded.Height != size[1] || variant.Width != size[0] || variant.Height != size[1] {
+			t.Errorf("expected the %v variant to be %vx%v, got %vx%v", name, size[0], size[1], decoded.Width, decoded.Height)
+		}
+	}
+}
+
+func TestProcessDoesNotEnlargeSmallImages(t *testing.T) {
+	processed, err := Process(context.Background(), encodePNG(t, newImage(120, 60)), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, variant := range processed.Variants {
+		if variant.Width != 120 || variant.Height != 60 {
+			t.Errorf("expected the %v variant to keep the size of the image, got %vx%v", name, variant.Width, variant.Height)
+		}
+	}
+}
+
+func TestProcessAppliesAndStripsTheExifOrientation(t *testing.T) {
+	// rotated 90 degrees clockwise to be displayed
+	content := encodeJPEGWithOrientation(t, newImage(40, 20), 6)
+	if orientation := jpegOrientation(content); orientation != 6 {
+		t.Fatalf("expected the orientation to be read, got %v", orientation)
+	}
+
+	processed, err := Process(context.Background(), content, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if processed.Original.Format != "jpeg" || processed.Original.Width != 20 || processed.Original.Height != 40 {
+		t.Fatalf("expected an upright 20x40 jpeg, got %v %vx%v", processed.Original.Format, processed.Original.Width, processed.Original.Height)
+	}
+	if bytes.Contains(processed.Original.Content, []byte("Exif")) {
+		t.Fatal("expected the exif to be stripped")
+	}
+}
+
+func TestProcessConvertsGifsToPng(t *testing.T) {
+	var buf bytes.Buffer
+	img := image.NewPaletted(image.Rect(0, 0, 4, 4), color.Palette{color.Black, color.White})
+	if err := gif.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	processed, err := Process(context.Background(), buf.Bytes(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if processed.Original.Format != "png" {
+		t.Fatalf("expected the gif to be encoded as png, got %v", processed.Original.Format)
+	}
+}
@@ -0,0 +1,113 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+)
+
+const orientationTag = 0x0112
+
+// reads the exif orientation of a jpeg (1 to 8), 1 (as stored) is returned when it is missing or can not be read.
+func jpegOrientation(content []byte) int {
+	if len(content) < 4 || content[0] != 0xFF || content[1] != 0xD8 {
+		return 1
+	}
+
+	for offset := 2; offset+4 <= len(content); {
+		if content[offset] != 0xFF {
+			return 1
+		}
+		marker := content[offset+1]
+		// the image data starts after the start of scan, the exif is before it
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(content[offset+2:]))
+		if length < 2 || offset+2+length > len(content) {
+			return 1
+		}
+		segment := content[offset+4 : offset+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		offset += 2 + length
+	}
+	return 1
+}
+
+// only the first directory (IFD0) of the tiff structure is read, it holds the orientation.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	directory := int(order.Uint32(tiff[4:]))
+	if directory+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[directory:]))
+	for i := 0; i < entries; i++ {
+		entry := directory + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == orientationTag {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+			return orientation
+		}
+	}
+	return 1
+}
+
+// turns the pixels so the image is displayed upright without its exif orientation.
+func orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	dstWidth, dstHeight := width, height
+	// the orientations from 5 to 8 swap the width and the height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dstX, dstY int
+			switch orientation {
+			case 2: // mirrored horizontally
+				dstX, dstY = width-1-x, y
+			case 3: // rotated 180
+				dstX, dstY = width-1-x, height-1-y
+			case 4: // mirrored vertically
+				dstX, dstY = x, height-1-y
+			case 5: // transposed
+				dstX, dstY = y, x
+			case 6: // rotated 90 clockwise
+				dstX, dstY = height-1-y, x
+			case 7: // transversed
+				dstX, dstY = height-1-y, width-1-x
+			case 8: // rotated 90 counterclockwise
+				dstX, dstY = y, width-1-x
+			}
+			dst.Set(dstX, dstY, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,98 @@
+// Package logger builds the structured (log/slog) logger of the application and carries the request fields
+// (request id, user id) inside the context so every record logged with the request context includes them.
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type requestInfoKey struct{}
+
+// the fields of the request that are added to every record logged with its context, UserID is set by the
+// authentication middleware after the request was created, so it is kept behind a pointer.
+type RequestInfo struct {
+	ID     string
+	UserID uint
+}
+
+func WithRequestInfo(ctx context.Context, info *RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+func GetRequestInfo(ctx context.Context) *RequestInfo {
+	info, _ := ctx.Value(requestInfoKey{}).(*RequestInfo)
+	return info
+}
+
+// returns an empty string when the context does not belong to a request.
+func GetRequestID(ctx context.Context) string {
+	if info := GetRequestInfo(ctx); info != nil {
+		return info.ID
+	}
+	return ""
+}
+
+func SetUserID(ctx context.Context, userId uint) {
+	if info := GetRequestInfo(ctx); info != nil {
+		info.UserID = userId
+	}
+}
+
+// unknown levels fallback to info.
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// format is either "json" or "text", unknown formats fallback to json.
+func New(w io.Writer, level, format string) *slog.Logger {
+	options := &slog.HandlerOptions{Level: ParseLevel(level)}
+
+	var handler slog.Handler
+	if strings.ToLower(strings.TrimSpace(format)) == "text" {
+		handler = slog.NewTextHandler(w, options)
+	} else {
+		handler = slog.NewJSONHandler(w, options)
+	}
+
+	return slog.New(&contextHandler{Handler: handler})
+}
+
+// adds the request fields found in the context of the record.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if info := GetRequestInfo(ctx); info != nil {
+		record.AddAttrs(slog.String("requestId", info.ID))
+		if info.UserID != 0 {
+			record.AddAttrs(slog.Uint64("userId", uint64(info.UserID)))
+		}
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		record.AddAttrs(slog.String("traceId", spanContext.TraceID().String()))
+	}
+
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
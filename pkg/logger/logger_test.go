@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger(t *testing.T) {
+	t.Run("Should add the request fields of the context", func(t *testing.T) {
+		var buf bytes.Buffer
+		log := New(&buf, "info", "json")
+
+		ctx := WithRequestInfo(context.Background(), &RequestInfo{ID: "request-1"})
+		SetUserID(ctx, 7)
+		log.InfoContext(ctx, "hello")
+
+		var record map[string]any
+		assert.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+		assert.Equal(t, "hello", record["msg"])
+		assert.Equal(t, "request-1", record["requestId"])
+		assert.Equal(t, float64(7), record["userId"])
+	})
+
+	t.Run("Should drop the records under the configured level", func(t *testing.T) {
+		var buf bytes.Buffer
+		log := New(&buf, "warn", "text")
+
+		log.Info("hidden")
+		assert.Empty(t, buf.String())
+
+		log.Warn("shown")
+		assert.Contains(t, buf.String(), "msg=shown")
+	})
+
+	t.Run("Should fallback to info for unknown levels", func(t *testing.T) {
+		assert.Equal(t, slog.LevelInfo, ParseLevel("verbose"))
+		assert.Equal(t, slog.LevelDebug, ParseLevel("DEBUG"))
+	})
+}
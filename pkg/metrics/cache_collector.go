@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"main.go/pkg/cache"
+)
+
+// reads the stats of the caches on each scrape, the stats are labeled by the cache name.
+type cacheCollector struct {
+	stats     func() map[string]cache.Stats
+	hits      *prometheus.Desc
+	misses    *prometheus.Desc
+	evictions *prometheus.Desc
+	size      *prometheus.Desc
+}
+
+func newCacheDesc(name, help string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, "cache", name), help, []string{"cache"}, nil)
+}
+
+func (c *cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.evictions
+	ch <- c.size
+}
+
+func (c *cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	for name, stats := range c.stats() {
+		ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits), name)
+		ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses), name)
+		ch <- prometheus.MustNewConstMetric(c.evictions, prometheus.CounterValue, float64(stats.Evictions), name)
+		ch <- prometheus.MustNewConstMetric(c.size, prometheus.GaugeValue, float64(stats.Size), name)
+	}
+}
+
+func RegisterCacheStats(stats func() map[string]cache.Stats) {
+	Registry.MustRegister(&cacheCollector{
+		stats:     stats,
+		hits:      newCacheDesc("hits_total", "Count of the cache lookups that found the key."),
+		misses:    newCacheDesc("misses_total", "Count of the cache lookups that did not find the key."),
+		evictions: newCacheDesc("evictions_total", "Count of the entries evicted to respect the max size."),
+		size:      newCacheDesc("entries", "Count of the entries in the cache."),
+	})
+}
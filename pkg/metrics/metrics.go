@@ -0,0 +1,93 @@
+// Package metrics holds the prometheus collectors of the application, the collectors that need a dependency
+// (e.g the database pool, the websocket manager) are registered by the api on start.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "shop"
+
+// the route of the requests that matched no route, the raw path is not used as a label to keep the series count bounded.
+const UnmatchedRoute = "unmatched"
+
+var Registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Count of the handled http requests by route pattern, method and status.",
+	}, []string{"route", "method", "status"})
+
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of the handled http requests by route pattern, method and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	OrdersCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_created_total",
+		Help:      "Count of the created orders.",
+	})
+
+	ImageUploadFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "image_upload_failures_total",
+		Help:      "Count of the images that failed to be uploaded to the images provider.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests, HTTPRequestDuration, OrdersCreated, ImageUploadFailures,
+	)
+}
+
+func ObserveRequest(route, method string, status int, duration time.Duration) {
+	if route == "" {
+		route = UnmatchedRoute
+	}
+	statusStr := strconv.Itoa(status)
+
+	HTTPRequests.WithLabelValues(route, method, statusStr).Inc()
+	HTTPRequestDuration.WithLabelValues(route, method, statusStr).Observe(duration.Seconds())
+}
+
+// exposes the open, in use and idle connections and the wait counts of the pool.
+func RegisterDBStats(db *sql.DB, dbName string) {
+	Registry.MustRegister(collectors.NewDBStatsCollector(db, dbName))
+}
+
+// registers a gauge that is read on each scrape, used for the sizes owned by other packages (e.g the websocket clients).
+func RegisterGaugeFunc(name, help string, value func() float64) {
+	Registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      name,
+		Help:      help,
+	}, value))
+}
+
+// registers a counter that is read on each scrape, the value must only increase.
+func RegisterCounterFunc(name, help string, value func() float64) {
+	Registry.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      name,
+		Help:      help,
+	}, value))
+}
+
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
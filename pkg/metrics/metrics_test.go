@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"main.go/pkg/cache"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Run("Should label the requests by route pattern and use a fixed label for unmatched routes", func(t *testing.T) {
+		ObserveRequest("GET /api/v1/products/{id}", "GET", 200, 10*time.Millisecond)
+		ObserveRequest("", "GET", 404, time.Millisecond)
+
+		assert.Equal(t, float64(1), testutil.ToFloat64(HTTPRequests.WithLabelValues("GET /api/v1/products/{id}", "GET", "200")))
+		assert.Equal(t, float64(1), testutil.ToFloat64(HTTPRequests.WithLabelValues(UnmatchedRoute, "GET", "404")))
+	})
+
+	t.Run("Should expose the registered gauges and cache stats", func(t *testing.T) {
+		RegisterGaugeFunc("test_gauge", "Test gauge.", func() float64 { return 3 })
+		RegisterCacheStats(func() map[string]cache.Stats {
+			return map[string]cache.Stats{"users": {Hits: 4, Misses: 1, Size: 2}}
+		})
+
+		recorder := httptest.NewRecorder()
+		Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+		body := recorder.Body.String()
+
+		assert.True(t, strings.Contains(body, "shop_test_gauge 3"))
+		assert.True(t, strings.Contains(body, `shop_cache_hits_total{cache="users"} 4`))
+		assert.True(t, strings.Contains(body, `shop_cache_entries{cache="users"} 2`))
+	})
+}
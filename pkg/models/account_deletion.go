@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// a deletion requested by the user, the user is soft deleted right away and purged after PurgeAfter,
+// restoring the user during the grace period cancels the deletion.
+type AccountDeletion struct {
+	ModelBasics
+	UserID      uint       `json:"userId" gorm:"not null;uniqueIndex"`
+	User        *User      `json:"user,omitempty" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	RequestedAt time.Time  `json:"requestedAt" gorm:"not null"`
+	PurgeAfter  time.Time  `json:"purgeAfter" gorm:"not null;index"`
+	CompletedAt *time.Time `json:"completedAt"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// audit entry created each time an account or an ip is locked out after too many failed logins.
+type AccountLockout struct {
+	ModelBasics
+	Email        string     `json:"email" gorm:"not null;size:64;index"`
+	UserID       *uint      `json:"userId" gorm:"index"`
+	User         *User      `json:"user,omitempty" gorm:"foreignKey:UserID;constraint:OnDelete:SET NULL"`
+	IP           string     `json:"ip" gorm:"not null;size:64"`
+	Reason       string     `json:"reason" gorm:"not null;size:16"`
+	Failures     int        `json:"failures" gorm:"not null"`
+	LockedUntil  time.Time  `json:"lockedUntil" gorm:"not null"`
+	UnlockedAt   *time.Time `json:"unlockedAt"`
+	UnlockedByID *uint      `json:"unlockedById"`
+}
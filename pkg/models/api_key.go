@@ -0,0 +1,36 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// the plain key is never stored, only its sha256 hash, the prefix is kept to help admins to recognize the key.
+type ApiKey struct {
+	ModelBasics
+	Name       string     `json:"name" gorm:"not null;size:64"`
+	Prefix     string     `json:"prefix" gorm:"not null;size:16"`
+	KeyHash    string     `json:"-" gorm:"not null;size:64;uniqueIndex"`
+	UserID     uint       `json:"userId" gorm:"not null;index"`
+	User       *User      `json:"user,omitempty" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Scopes     []string   `json:"scopes" gorm:"serializer:json;type:text;not null"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	RevokedAt  *time.Time `json:"revokedAt"`
+}
+
+func (k *ApiKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+func (k *ApiKey) IsActive(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+
+	return k.ExpiresAt == nil || k.ExpiresAt.After(now)
+}
+
+func (k *ApiKey) GetUserId() uint {
+	return k.UserID
+}
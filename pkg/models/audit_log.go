@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// the value of a field before and after the change, Before is nil for created resources and After is nil for deleted ones.
+type AuditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// one entry is written for each successful admin mutation, the rows are never updated.
+type AuditLog struct {
+	ID             uint                   `json:"id" gorm:"primarykey;autoIncrement"`
+	ActorID        *uint                  `json:"actorId" gorm:"index"`
+	ApiKeyID       *uint                  `json:"apiKeyId"`
+	ImpersonatorID *uint                  `json:"impersonatorId"`
+	Action         string                 `json:"action" gorm:"not null;size:32;index"`
+	ResourceType   string                 `json:"resourceType" gorm:"not null;size:64;index:idx_audit_logs_resource"`
+	ResourceID     *uint                  `json:"resourceId" gorm:"index:idx_audit_logs_resource"`
+	Changes        map[string]AuditChange `json:"changes" gorm:"serializer:json;type:text"`
+	Method         string                 `json:"method" gorm:"not null;size:8"`
+	Path           string                 `json:"path" gorm:"not null;size:256"`
+	StatusCode     int                    `json:"statusCode" gorm:"not null"`
+	IP             string                 `json:"ip" gorm:"not null;size:64"`
+	RequestID      string                 `json:"requestId" gorm:"size:64;index"`
+	CreatedAt      time.Time              `json:"createdAt" gorm:"index"`
+}
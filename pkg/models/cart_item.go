@@ -3,7 +3,7 @@ package models
 type CartItem struct {
 	ModelBasics
 	Product   *Product `json:"product,omitempty" gorm:"foreignkey:ProductID;constraint:OnDelete:CASCADE"`
-	ProductID uint `json:"productId" gorm:"uniqueIndex:idx_user_product,not null"`
+	ProductID uint `json:"productId" gorm:"uniqueIndex:idx_cart_items_user_product,not null"`
 	Quantity uint `json:"quantity" gorm:"not null;check:quantity > 0"`
-	UserID uint `json:"userId" gorm:"uniqueIndex:idx_user_product,not null"`
+	UserID uint `json:"userId" gorm:"uniqueIndex:idx_cart_items_user_product,not null"`
 }
\ No newline at end of file
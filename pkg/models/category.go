@@ -3,5 +3,9 @@ package models
 type Category struct {
 	ModelBasicsTrackedDel
 	Name     string `json:"name" gorm:"not null;size:32"`
+	ParentID *uint `json:"parentId" gorm:"index"`
+	// the ids of the ancestors then of the category, e.g "1/4/9/", the descendants are the categories whose path starts with it
+	Path     string `json:"-" gorm:"not null;size:255;default:'';index"`
+	Children []Category `json:"children,omitempty" gorm:"foreignKey:ParentID;constraint:OnDelete:RESTRICT"`
 	Products []Product `json:"products,omitempty" gorm:"foreignKey:CategoryID;constraint:OnDelete:CASCADE"`
 }
\ No newline at end of file
@@ -0,0 +1,15 @@
+package models
+
+// links the subject of an external OIDC provider to a user, a user can have one identity per provider.
+type Identity struct {
+	ModelBasics
+	Provider string  `json:"provider" gorm:"not null;size:32;uniqueIndex:idx_identity_provider_subject;uniqueIndex:idx_identity_provider_user"`
+	Subject  string  `json:"-" gorm:"not null;size:255;uniqueIndex:idx_identity_provider_subject"`
+	UserID   uint    `json:"userId" gorm:"not null;uniqueIndex:idx_identity_provider_user"`
+	User     *User   `json:"user,omitempty" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Email    *string `json:"email" gorm:"default:NULL;size:64"`
+}
+
+func (i *Identity) GetUserId() uint {
+	return i.UserID
+}
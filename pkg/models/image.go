@@ -6,4 +6,19 @@ type Image struct {
 	ImageUrl string `json:"imageUrl" gorm:"not null;size:256"`
 	IsMain *bool `json:"isMain" gorm:"default:false;not null"`
 	ImagePublicId string `json:"imagePublicId" gorm:"not null;size:128"`
-}
\ No newline at end of file
+	// the display order of the images of the product, from 0.
+	Position int `json:"position" gorm:"not null;default:0"`
+	AltText string `json:"altText" gorm:"not null;size:256;default:''"`
+	Variants ImageVariants `json:"variants" gorm:"serializer:json;type:text"`
+}
+
+// the smaller sizes of an image keyed by their name (thumbnail, medium, large), the images uploaded before the
+// variants were generated have none.
+type ImageVariants map[string]ImageVariant
+
+type ImageVariant struct {
+	URL      string `json:"url"`
+	PublicId string `json:"publicId"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+}
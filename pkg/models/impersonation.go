@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// a SuperAdmin acting as another user, the tokens of the session are rejected once it is ended or expired.
+type ImpersonationSession struct {
+	ModelBasics
+	ImpersonatorID uint       `json:"impersonatorId" gorm:"not null;index"`
+	Impersonator   *User      `json:"impersonator,omitempty" gorm:"foreignKey:ImpersonatorID;constraint:OnDelete:CASCADE"`
+	TargetUserID   uint       `json:"targetUserId" gorm:"not null;index"`
+	TargetUser     *User      `json:"targetUser,omitempty" gorm:"foreignKey:TargetUserID;constraint:OnDelete:CASCADE"`
+	Reason         string     `json:"reason" gorm:"not null;size:256"`
+	ExpiresAt      time.Time  `json:"expiresAt" gorm:"not null"`
+	EndedAt        *time.Time `json:"endedAt"`
+}
+
+func (s *ImpersonationSession) IsActive(now time.Time) bool {
+	return s.EndedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// every request made with an impersonation token.
+type ImpersonationRequest struct {
+	Identifier
+	SessionID  uint                  `json:"sessionId" gorm:"not null;index"`
+	Session    *ImpersonationSession `json:"session,omitempty" gorm:"foreignKey:SessionID;constraint:OnDelete:CASCADE"`
+	Method     string                `json:"method" gorm:"not null;size:8"`
+	Path       string                `json:"path" gorm:"not null;size:256"`
+	StatusCode int                   `json:"statusCode" gorm:"not null"`
+	CreatedAt  time.Time             `json:"createdAt"`
+}
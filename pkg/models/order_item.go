@@ -6,5 +6,5 @@ type OrderItem struct {
 	Product *Product `json:"product,omitempty" gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
 	ProductID uint `json:"productId" gorm:"not null"`
 	UnitPrice float64 `json:"unitPrice" gorm:"not null;check: quantity >= 0;type:decimal(7,2)"`
-	Quantity uint `json:"quantity" gorm:"not null;type:TINYINT"`
+	Quantity uint `json:"quantity" gorm:"not null;size:8"`
 }
\ No newline at end of file
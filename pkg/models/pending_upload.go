@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// a file uploaded straight to the image storage for a product, it becomes an image once the upload is confirmed and
+// the files of the uploads that are not confirmed before ConfirmBefore are deleted.
+type PendingUpload struct {
+	ModelBasics
+	ProductID     uint      `json:"productId" gorm:"not null;index"`
+	PublicId      string    `json:"publicId" gorm:"not null;size:256;uniqueIndex"`
+	ConfirmBefore time.Time `json:"confirmBefore" gorm:"not null;index"`
+}
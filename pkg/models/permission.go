@@ -0,0 +1,9 @@
+package models
+
+type Permission struct {
+	Identifier
+	Name        string  `json:"name" gorm:"size:64;not null;unique"`
+	Description *string `json:"description,omitempty" gorm:"size:256"`
+	Roles       []Role  `json:"roles,omitempty" gorm:"many2many:role_permissions;foreignKey:ID;joinForeignKey:PermissionID;References:ID;joinReferences:RoleID;constraint:OnDelete:CASCADE;"`
+	TimeSpans
+}
@@ -4,6 +4,10 @@ package models
 type Product struct {
 	ModelBasicsTrackedDel
 	Name         string    `json:"name" gorm:"size:32;not null"`
+	SKU          string    `json:"sku" gorm:"size:64;not null;uniqueIndex"`
+	Barcode      *string   `json:"barcode,omitempty" gorm:"size:13;uniqueIndex"`
+	// derived from the name, the previous slugs are kept in ProductSlug
+	Slug         string    `json:"slug" gorm:"size:96;not null;default:'';uniqueIndex"`
 	Quantity     uint      `json:"quantity" gorm:"check:quantity > 0"`
 	Image        *Image    `json:"mainImage,omitempty" gorm:"-"`
 	Images       []Image   `json:"images,omitempty" gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
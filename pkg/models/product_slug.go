@@ -0,0 +1,9 @@
+package models
+
+// a previous slug of a product, the links using it are redirected to the current slug of the product.
+type ProductSlug struct {
+	ModelBasics
+	ProductID uint     `json:"productId" gorm:"not null;index"`
+	Product   *Product `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	Slug      string   `json:"slug" gorm:"size:96;not null;uniqueIndex"`
+}
@@ -4,5 +4,6 @@ type Role struct {
 	Identifier
 	Name      string      `json:"role" gorm:"size:32;not null;unique"`
 	Users     []User `json:"users,omitempty" gorm:"many2many:user_roles;foreignKey:ID;joinForeignKey:RoleID;References:ID;joinReferences:UserID;constraint:OnDelete:CASCADE;"`
+	Permissions []Permission `json:"permissions,omitempty" gorm:"many2many:role_permissions;foreignKey:ID;joinForeignKey:RoleID;References:ID;joinReferences:PermissionID;constraint:OnDelete:CASCADE;"`
 	TimeSpans
 }
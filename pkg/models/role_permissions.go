@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+type RolePermissions struct {
+	RoleID       uint        `json:"roleId" gorm:"primaryKey;not null"`
+	Role         *Role       `json:"role,omitempty"`
+	PermissionID uint        `json:"permissionId" gorm:"primaryKey;not null"`
+	Permission   *Permission `json:"permission,omitempty"`
+	AssignedAt   time.Time   `json:"assignedAt,omitempty" gorm:"autoCreateTime"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// a file of the image storage waiting to be deleted (the outbox of the storage), the rows are written in the
+// transaction removing the file's row and deleted once the storage deleted the file.
+type StorageDeletion struct {
+	ModelBasics
+	PublicId      string    `json:"publicId" gorm:"not null;size:256"`
+	Attempts      int       `json:"attempts" gorm:"not null;default:0"`
+	LastError     *string   `json:"lastError" gorm:"size:512"`
+	NextAttemptAt time.Time `json:"nextAttemptAt" gorm:"not null;index"`
+}
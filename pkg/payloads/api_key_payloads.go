@@ -0,0 +1,44 @@
+package payloads
+
+import (
+	"strings"
+	"time"
+
+	"main.go/pkg/models"
+)
+
+// UserId is the owner of the key, when its not sent the key is owned by the admin creating it.
+type CreateApiKey struct {
+	Name          string   `json:"name" validate:"required,min=3,max=64,alphanumWithSpaces"`
+	Scopes        []string `json:"scopes" validate:"required,min=1,dive,oneof=products:read products:write orders:read orders:write"`
+	UserId        uint     `json:"userId" validate:"omitempty,min=1"`
+	ExpiresInDays uint     `json:"expiresInDays" validate:"omitempty,min=1,max=365"`
+}
+
+func (c *CreateApiKey) TrimStrs() *CreateApiKey {
+	if c != nil {
+		c.Name = strings.Trim(c.Name, " ")
+		for i, scope := range c.Scopes {
+			c.Scopes[i] = strings.Trim(scope, " ")
+		}
+	}
+
+	return c
+}
+
+func (c *CreateApiKey) ToModel(ownerId uint, prefix, keyHash string) *models.ApiKey {
+	var expiresAt *time.Time
+	if c.ExpiresInDays != 0 {
+		expiration := time.Now().AddDate(0, 0, int(c.ExpiresInDays))
+		expiresAt = &expiration
+	}
+
+	return &models.ApiKey{
+		Name:      c.Name,
+		Prefix:    prefix,
+		KeyHash:   keyHash,
+		UserID:    ownerId,
+		Scopes:    c.Scopes,
+		ExpiresAt: expiresAt,
+	}
+}
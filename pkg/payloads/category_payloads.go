@@ -10,12 +10,18 @@ import (
 
 type CreateCategory struct {
 	Name string `json:"name" validate:"required,min=3,max=32,alphanumWithSpaces"`
+	ParentID *uint `json:"parentId" validate:"omitempty,min=1"`
 }
 
 type UpdateCategory struct {
 	Name string `json:"name" validate:"required,min=3,max=32,alphanumWithSpaces"`
 }
 
+// a null parentId moves the category to the roots.
+type MoveCategory struct {
+	ParentID *uint `json:"parentId" validate:"omitempty,min=1"`
+}
+
 func (uc *UpdateCategory) TrimStrs() *UpdateCategory {
 	if uc != nil {
 		uc.Name = strings.Trim(uc.Name, " ")
@@ -46,6 +52,7 @@ func (cc *CreateCategory) ToModel() *models.Category {
 	if cc != nil {
 		return &models.Category{
 			Name:cc.Name,
+			ParentID:cc.ParentID,
 		}
 	}
 	
@@ -1,6 +1,9 @@
 package payloads
 
-import "slices"
+import (
+	"slices"
+	"strings"
+)
 
 type CreateImage struct {
 	ProductID     uint   `json:"productId" validate:"required"`
@@ -9,6 +12,37 @@ type CreateImage struct {
 	ImagePublicId string `json:"imagePublicId" validate:"required"`
 }
 
+// the ids of every image of the product, in their display order.
+type ReorderImages struct {
+	ImageIds []uint `json:"imageIds" validate:"required,min=1,max=10,unique,dive,min=1"`
+}
+
+type UpdateImageAltText struct {
+	AltText string `json:"altText" validate:"max=256"`
+}
+
+func (ua *UpdateImageAltText) TrimStrs() *UpdateImageAltText {
+	if ua != nil {
+		ua.AltText = strings.TrimSpace(ua.AltText)
+	}
+
+	return ua
+}
+
+// the names of the files to upload straight to the storage, one upload is signed per file.
+type CreateUploadURLs struct {
+	FileNames []string `json:"fileNames" validate:"required,min=1,max=10,dive,required,max=128"`
+}
+
+type ConfirmUploads struct {
+	Uploads []ConfirmUpload `json:"uploads" validate:"required,min=1,max=10,unique=PublicId,dive"`
+}
+
+type ConfirmUpload struct {
+	PublicId string `json:"publicId" validate:"required,max=256"`
+	AltText  string `json:"altText" validate:"max=256"`
+}
+
 type UpdateImage struct {
 	ProductID     uint   `json:"productId"`
 	ImageUrl      string `json:"imageUrl"`
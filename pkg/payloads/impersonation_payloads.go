@@ -0,0 +1,36 @@
+package payloads
+
+import (
+	"strings"
+	"time"
+
+	"main.go/pkg/models"
+)
+
+// the session lasts 15 minutes when DurationInMinutes is not sent.
+type ImpersonateUser struct {
+	Reason            string `json:"reason" validate:"required,min=8,max=256"`
+	DurationInMinutes uint   `json:"durationInMinutes" validate:"omitempty,min=1,max=60"`
+}
+
+func (i *ImpersonateUser) TrimStrs() *ImpersonateUser {
+	if i != nil {
+		i.Reason = strings.Trim(i.Reason, " ")
+	}
+
+	return i
+}
+
+func (i *ImpersonateUser) ToModel(impersonatorId, targetUserId uint) *models.ImpersonationSession {
+	duration := 15 * time.Minute
+	if i.DurationInMinutes != 0 {
+		duration = time.Duration(i.DurationInMinutes) * time.Minute
+	}
+
+	return &models.ImpersonationSession{
+		ImpersonatorID: impersonatorId,
+		TargetUserID:   targetUserId,
+		Reason:         i.Reason,
+		ExpiresAt:      time.Now().Add(duration),
+	}
+}
@@ -12,4 +12,13 @@ func TrimStrPtr(strPtr *string) *string {
 	}
     
     return trimmedStr
-}
\ No newline at end of file
+}
+
+// the optional values of the payloads are stored as NULL when they are empty.
+func optionalStr(str string) *string {
+	if str == "" {
+		return nil
+	}
+
+	return &str
+}
@@ -16,7 +16,9 @@ type CreateProduct struct {
 	Image       multipart.File `json:"image"`
 	Description string        `json:"description" validate:"omitempty,max=256,min=4"`
 	CategoryID  uint           `json:"categoryId" validate:"required,min=1"`
-	Price       float64        `json:"price" validate:"gt=0.0"`
+	Price       float64        `json:"price" validate:"gt=0.0,lte=99999.99"`
+	SKU         string         `json:"sku" validate:"required,max=64,sku"`
+	Barcode     string         `json:"barcode" validate:"omitempty,barcode"`
 }
 
 
@@ -25,7 +27,9 @@ type UpdateProduct struct {
 	Quantity    uint           `json:"quantity" validate:"omitempty,min=0,max=10000"`
 	Description string        `json:"description" validate:"omitempty,max=256,min=4"`
 	CategoryID  uint           `json:"categoryId" validate:"omitempty,min=1"`
-	Price       float64        `json:"price" validate:"omitempty,gt=0.0"`
+	Price       float64        `json:"price" validate:"omitempty,gt=0.0,lte=99999.99"`
+	SKU         string         `json:"sku" validate:"omitempty,max=64,sku"`
+	Barcode     string         `json:"barcode" validate:"omitempty,barcode"`
 }
 
 func (cp *CreateProduct) ToModelWithImage(url string) *models.Product {
@@ -36,6 +40,8 @@ func (cp *CreateProduct) ToModelWithImage(url string) *models.Product {
 			Description: &cp.Description,
 			CategoryID:  cp.CategoryID,
 			Price:       cp.Price,
+			SKU:         cp.SKU,
+			Barcode:     optionalStr(cp.Barcode),
 		}
 	}
 
@@ -46,6 +52,8 @@ func (cp *CreateProduct) TrimStrs() *CreateProduct {
 	if cp != nil {
 		cp.Name = strings.Trim(cp.Name, " ")
 		cp.Description = strings.Trim(cp.Description, " ")
+		cp.SKU = strings.TrimSpace(cp.SKU)
+		cp.Barcode = strings.TrimSpace(cp.Barcode)
 	}
 
 	return cp
@@ -55,6 +63,8 @@ func (up *UpdateProduct) TrimStrs() *UpdateProduct {
 	if up != nil {
 		up.Name = strings.Trim(up.Name, " ")
 		up.Description = strings.Trim(up.Description, " ")
+		up.SKU = strings.TrimSpace(up.SKU)
+		up.Barcode = strings.TrimSpace(up.Barcode)
 	}
 
 	return up
@@ -68,6 +78,8 @@ func (up *UpdateProduct) ToModel() *models.Product {
 			Description: &up.Description,
 			CategoryID:  up.CategoryID,
 			Price:       up.Price,
+			SKU:         up.SKU,
+			Barcode:     optionalStr(up.Barcode),
 		}
 	}
 	return nil
@@ -103,6 +115,12 @@ func (up *UpdateProduct) Exclude(selectedFields []string) []string {
 	if up.Description == "" {
 		removedCols["Description"] = 1
 	}
+	if up.SKU == "" {
+		removedCols["SKU"] = 1
+	}
+	if up.Barcode == "" {
+		removedCols["Barcode"] = 1
+	}
 
 	selectedFields = slices.DeleteFunc(selectedFields, func(element string) bool {
 		_, exists := removedCols[element]
@@ -133,11 +151,13 @@ func NewCreatePayload(r *http.Request, uIntConvertor func(s string) (*uint, erro
 		Description: r.FormValue("description"),
 		CategoryID: *categoryId,
 		Price: *price,
+		SKU: r.FormValue("sku"),
+		Barcode: r.FormValue("barcode"),
 	}
 	
 	return payload, nil
 }
 
 func (up *UpdateProduct) IsEmpty() bool {
-	return up.Name == "" && up.Quantity == 0 && up.Description == "" && up.Price == 0 && up.CategoryID == 0 
+	return up.Name == "" && up.Quantity == 0 && up.Description == "" && up.Price == 0 && up.CategoryID == 0 && up.SKU == "" && up.Barcode == "" 
 }
\ No newline at end of file
@@ -10,6 +10,10 @@ type CreateRole struct {
 	Name string `json:"name" validate:"required,min=2,max=32,alphanumWithSpaces"`
 }
 
+type AssignPermissionsPayload struct {
+	PermissionIds []uint `json:"permissionIds" validate:"required,min=1,max=50,unique,dive,min=1"`
+}
+
 type UpdateRole struct {
 	Name string `json:"name" validate:"required,min=2,max=32,alphanumWithSpaces"`
 }
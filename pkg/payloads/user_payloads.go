@@ -7,9 +7,11 @@ import (
 	"main.go/pkg/models"
 )
 
+// ReturnTokens is used by the mobile app and server-to-server clients that authenticate with the "Authorization: Bearer" header.
 type UserLogin struct {
 	Email    string `json:"email" validate:"required,email,max=64"`
 	Password string `json:"password" validate:"required,min=6,max=24"`
+	ReturnTokens bool `json:"returnTokens"`
 }
 
 type UserSignUp struct {
@@ -0,0 +1,49 @@
+package payloads_test
+
+import (
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"main.go/pkg/payloads"
+	"main.go/pkg/test_utils"
+	"main.go/pkg/utils"
+)
+
+func TestProductPayload_Create(t *testing.T) {
+	newProduct := func(sku, barcode string) payloads.CreateProduct {
+		return payloads.CreateProduct{Name: "Running shoe", Quantity: 1, CategoryID: 1, Price: 10, SKU: sku, Barcode: barcode}
+	}
+
+	t.Run("Should accept a product without a barcode", func(t *testing.T) {
+		err := utils.ValidateStruct(newProduct("SHOE-1", ""))
+		assert.Nil(t, err)
+	})
+
+	t.Run("Should accept the ean-8, upc-a and ean-13 barcodes with a valid check digit", func(t *testing.T) {
+		for _, barcode := range []string{"96385074", "036000291452", "4006381333931"} {
+			err := utils.ValidateStruct(newProduct("SHOE-1", barcode))
+			assert.Nil(t, err, barcode)
+		}
+	})
+
+	t.Run("Should return an error for each invalid sku and barcode", func(t *testing.T) {
+		invalid := map[string]payloads.CreateProduct{
+			"wrong check digit":  newProduct("SHOE-1", "4006381333932"),
+			"wrong length":       newProduct("SHOE-1", "400638133393"),
+			"not only digits":    newProduct("SHOE-1", "40063813339a1"),
+			"missing sku":        newProduct("", ""),
+			"sku with spaces":    newProduct("SHOE 1", ""),
+			"sku starting a dot": newProduct(".SHOE", ""),
+		}
+		for name, product := range invalid {
+			err := utils.ValidateStruct(product)
+			validationErrs, ok := err.(validator.ValidationErrors)
+			if !ok {
+				t.Fatalf("expected a validation error for the %v, got %v", name, err)
+			}
+			errsCount := test_utils.GetErrorsCount(validationErrs)
+			assert.Equal(t, 1, errsCount, test_utils.ExpectedErrsCountMsg(1, errsCount))
+		}
+	})
+}
@@ -0,0 +1,212 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/cloudinary/cloudinary-go/v2/api"
+	"github.com/cloudinary/cloudinary-go/v2/api/admin"
+	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+	"main.go/types"
+)
+
+// CloudinaryStorage converts the images to webp, the public ids include the folder.
+type CloudinaryStorage struct {
+	cld *cloudinary.Cloudinary
+}
+
+func NewCloudinaryStorage(cloudName, apiKey, apiSecret string) (*CloudinaryStorage, error) {
+	cld, err := cloudinary.NewFromParams(cloudName, apiKey, apiSecret)
+	if err != nil {
+		return nil, err
+	}
+	return &CloudinaryStorage{cld: cld}, nil
+}
+
+func (s *CloudinaryStorage) Name() string {
+	return Cloudinary
+}
+
+func (s *CloudinaryStorage) Upload(ctx context.Context, file io.Reader, fileName string, folder types.Folder) (*types.UploadResponse, error) {
+	useFileName := true
+	uniqueID := fmt.Sprintf("image_%s", time.Now().Format("20060102150405"))
+	resp, err := s.cld.Upload.Upload(ctx, file, uploader.UploadParams{
+		ResourceType:   "image",
+		Folder:         string(folder),
+		PublicID:       fileName + "_" + uniqueID,
+		UseFilename:    &useFileName,
+		Transformation: "f_webp",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return uploadResponse(resp)
+}
+
+func (s *CloudinaryStorage) Replace(ctx context.Context, publicId string, file io.Reader, fileName string) (*types.UploadResponse, error) {
+	overwrite, invalidate := true, true
+	resp, err := s.cld.Upload.Upload(ctx, file, uploader.UploadParams{
+		ResourceType:   "image",
+		PublicID:       publicId,
+		Overwrite:      &overwrite,
+		Invalidate:     &invalidate,
+		Transformation: "f_webp",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return uploadResponse(resp)
+}
+
+func (s *CloudinaryStorage) Delete(ctx context.Context, publicId string) error {
+	resp, err := s.cld.Upload.Destroy(ctx, uploader.DestroyParams{
+		PublicID: publicId,
+	})
+	if err != nil {
+		return err
+	}
+	return responseError(resp.Error)
+}
+
+// the admin api deletes up to 100 assets per call.
+func (s *CloudinaryStorage) DeleteMany(ctx context.Context, publicIds []string) error {
+	for chunk := range slices.Chunk(publicIds, 100) {
+		resp, err := s.cld.Admin.DeleteAssets(ctx, admin.DeleteAssetsParams{
+			AssetType: "image",
+			PublicIDs: chunk,
+		})
+		if err != nil {
+			return err
+		}
+		if err := responseError(resp.Error); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// the signed delivery urls of cloudinary do not expire, expiresIn is ignored.
+func (s *CloudinaryStorage) SignedURL(ctx context.Context, publicId string, expiresIn time.Duration) (string, error) {
+	asset, err := s.cld.Image(publicId)
+	if err != nil {
+		return "", err
+	}
+	asset.Config.URL.SignURL = true
+	asset.Config.URL.Secure = true
+	return asset.String()
+}
+
+// the assets are listed by pages of 500, the rate of the admin api is limited (500 calls per hour on the free plan).
+func (s *CloudinaryStorage) List(ctx context.Context, folder types.Folder) ([]StoredImage, error) {
+	var images []StoredImage
+	cursor := ""
+	for {
+		resp, err := s.cld.Admin.Assets(ctx, admin.AssetsParams{
+			AssetType:    "image",
+			DeliveryType: "upload",
+			Prefix:       string(folder) + "/",
+			MaxResults:   500,
+			NextCursor:   cursor,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := responseError(resp.Error); err != nil {
+			return nil, err
+		}
+		for _, asset := range resp.Assets {
+			images = append(images, StoredImage{PublicId: asset.PublicID, UpdatedAt: asset.CreatedAt})
+		}
+		if resp.NextCursor == "" {
+			return images, nil
+		}
+		cursor = resp.NextCursor
+	}
+}
+
+// the signature of a cloudinary upload is valid for an hour, expiresIn is ignored. The extension of the file name is
+// not kept in the public id, the format of the asset is detected by cloudinary.
+func (s *CloudinaryStorage) SignUpload(ctx context.Context, folder types.Folder, fileName string, maxSize int64, expiresIn time.Duration) (*types.SignedUpload, error) {
+	publicId, err := newPublicId(folder, fileName)
+	if err != nil {
+		return nil, err
+	}
+	publicId = strings.TrimSuffix(publicId, path.Ext(publicId))
+
+	now := time.Now()
+	params := url.Values{
+		"public_id": {publicId},
+		"timestamp": {strconv.FormatInt(now.Unix(), 10)},
+	}
+	signature, err := api.SignParameters(params, s.cld.Config.Cloud.APISecret)
+	if err != nil {
+		return nil, err
+	}
+	return &types.SignedUpload{
+		PublicId: publicId,
+		URL:      fmt.Sprintf("https://api.cloudinary.com/v1_1/%s/image/upload", s.cld.Config.Cloud.CloudName),
+		Fields: map[string]string{
+			"api_key":   s.cld.Config.Cloud.APIKey,
+			"public_id": publicId,
+			"timestamp": params.Get("timestamp"),
+			"signature": signature,
+		},
+		ExpiresAt: now.Add(time.Hour),
+	}, nil
+}
+
+// the asset is downloaded from its delivery url.
+func (s *CloudinaryStorage) Open(ctx context.Context, publicId string) (io.ReadCloser, error) {
+	asset, err := s.cld.Admin.Asset(ctx, admin.AssetParams{PublicID: publicId})
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(strings.ToLower(asset.Error.Message), "not found") {
+		return nil, fmt.Errorf("%w: '%v'", ErrNotFound, publicId)
+	}
+	if err := responseError(asset.Error); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, asset.SecureURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("cloudinary: failed to download '%v': %v", publicId, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// the errors of the api are returned in the response, not as an error.
+func uploadResponse(resp *uploader.UploadResult) (*types.UploadResponse, error) {
+	if err := responseError(resp.Error); err != nil {
+		return nil, err
+	}
+	return &types.UploadResponse{
+		Width: resp.Width, Height: resp.Height,
+		SecureUrl: resp.SecureURL, PublicID: resp.PublicID,
+		URL: resp.URL, Format: resp.Format,
+	}, nil
+}
+
+func responseError(err api.ErrorResp) error {
+	if err.Message == "" {
+		return nil
+	}
+	return fmt.Errorf("cloudinary: %v", err.Message)
+}
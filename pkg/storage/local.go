@@ -0,0 +1,299 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"main.go/types"
+)
+
+// the images of the local storage are served under this route.
+const LocalRoute = "/static/images/"
+
+var errInvalidPublicId = errors.New("invalid image public id")
+
+// LocalStorage writes the images under a directory of the api and serves them, it is meant for the development and
+// the tests: the images are not shared between the replicas.
+type LocalStorage struct {
+	dir        string
+	baseURL    string
+	signingKey []byte
+}
+
+// baseURL is the url the route is reached at, signingKey signs the urls returned by SignedURL.
+func NewLocalStorage(dir, baseURL string, signingKey []byte) (*LocalStorage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalStorage{
+		dir:        dir,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		signingKey: signingKey,
+	}, nil
+}
+
+func (s *LocalStorage) Name() string {
+	return Local
+}
+
+func (s *LocalStorage) Upload(ctx context.Context, file io.Reader, fileName string, folder types.Folder) (*types.UploadResponse, error) {
+	publicId, err := newPublicId(folder, fileName)
+	if err != nil {
+		return nil, err
+	}
+	return s.write(publicId, file, fileName)
+}
+
+// the replaced image keeps its public id and its url.
+func (s *LocalStorage) Replace(ctx context.Context, publicId string, file io.Reader, fileName string) (*types.UploadResponse, error) {
+	return s.write(publicId, file, fileName)
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, publicId string) error {
+	filePath, err := s.path(publicId)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *LocalStorage) DeleteMany(ctx context.Context, publicIds []string) error {
+	var errs []error
+	for _, publicId := range publicIds {
+		errs = append(errs, s.Delete(ctx, publicId))
+	}
+	return errors.Join(errs...)
+}
+
+func (s *LocalStorage) SignedURL(ctx context.Context, publicId string, expiresIn time.Duration) (string, error) {
+	if _, err := s.path(publicId); err != nil {
+		return "", err
+	}
+
+	expires := strconv.FormatInt(time.Now().Add(expiresIn).Unix(), 10)
+	query := url.Values{
+		"expires":   {expires},
+		"signature": {s.sign(publicId, expires)},
+	}
+	return s.url(publicId) + "?" + query.Encode(), nil
+}
+
+func (s *LocalStorage) List(ctx context.Context, folder types.Folder) ([]StoredImage, error) {
+	var images []StoredImage
+	root := filepath.Join(s.dir, filepath.FromSlash(string(folder)))
+	err := filepath.WalkDir(root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// the folder is created on the first upload
+			if errors.Is(err, fs.ErrNotExist) && filePath == root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		relative, err := filepath.Rel(s.dir, filePath)
+		if err != nil {
+			return err
+		}
+		images = append(images, StoredImage{PublicId: filepath.ToSlash(relative), UpdatedAt: info.ModTime()})
+		return nil
+	})
+	return images, err
+}
+
+// the file is posted to the route of the image, the signature of the query covers its public id, its expiration and
+// its max size.
+func (s *LocalStorage) SignUpload(ctx context.Context, folder types.Folder, fileName string, maxSize int64, expiresIn time.Duration) (*types.SignedUpload, error) {
+	publicId, err := newPublicId(folder, fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(expiresIn)
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	size := strconv.FormatInt(maxSize, 10)
+	query := url.Values{
+		"expires":   {expires},
+		"maxSize":   {size},
+		"signature": {s.sign("upload", publicId, expires, size)},
+	}
+	return &types.SignedUpload{
+		PublicId:  publicId,
+		URL:       s.url(publicId) + "?" + query.Encode(),
+		Fields:    map[string]string{},
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (s *LocalStorage) Open(ctx context.Context, publicId string) (io.ReadCloser, error) {
+	filePath, err := s.path(publicId)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: '%v'", ErrNotFound, publicId)
+	}
+	return file, err
+}
+
+func (s *LocalStorage) RegisterRoutes(router *http.ServeMux) {
+	router.HandleFunc("GET "+LocalRoute+"{publicId...}", s.serve)
+	router.HandleFunc("POST "+LocalRoute+"{publicId...}", s.receive)
+}
+
+// the images are public, the signature of a signed url is checked when it is given.
+func (s *LocalStorage) serve(w http.ResponseWriter, r *http.Request) {
+	publicId := r.PathValue("publicId")
+	filePath, err := s.path(publicId)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	query := r.URL.Query()
+	if query.Has("signature") || query.Has("expires") {
+		if !s.verify(query.Get("signature"), query.Get("expires"), publicId, query.Get("expires")) {
+			http.Error(w, "the url is expired or its signature is invalid", http.StatusForbidden)
+			return
+		}
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	http.ServeContent(w, r, info.Name(), info.ModTime(), file)
+}
+
+// receives the uploads signed by SignUpload, the file is stored as it is like the objects posted to a bucket.
+func (s *LocalStorage) receive(w http.ResponseWriter, r *http.Request) {
+	publicId := r.PathValue("publicId")
+	query := r.URL.Query()
+	expires, size := query.Get("expires"), query.Get("maxSize")
+	maxSize, err := strconv.ParseInt(size, 10, 64)
+	if err != nil || !s.verify(query.Get("signature"), expires, "upload", publicId, expires, size) {
+		http.Error(w, "the upload is expired or its signature is invalid", http.StatusForbidden)
+		return
+	}
+
+	// the file and the multipart headers
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+1<<20)
+	file, fileHeader, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "expected a file of at most "+size+" bytes in the 'file' field", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	if fileHeader.Size > maxSize {
+		http.Error(w, "expected a file of at most "+size+" bytes in the 'file' field", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if _, err := s.write(publicId, file, publicId); err != nil {
+		http.Error(w, "failed to store the file", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *LocalStorage) write(publicId string, file io.Reader, fileName string) (*types.UploadResponse, error) {
+	filePath, err := s.path(publicId)
+	if err != nil {
+		return nil, err
+	}
+	data, err := readImage(file, fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return nil, err
+	}
+	// written next to the image then renamed so the image is never served half written
+	tempFile, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.Copy(tempFile, bytes.NewReader(data.content))
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tempFile.Name(), filePath)
+	}
+	if err != nil {
+		os.Remove(tempFile.Name())
+		return nil, err
+	}
+
+	imageURL := s.url(publicId)
+	return &types.UploadResponse{
+		Width: data.width, Height: data.height,
+		SecureUrl: imageURL, PublicID: publicId,
+		URL: imageURL, Format: data.format,
+	}, nil
+}
+
+// the public ids are relative paths under the directory, the ones leaving it are rejected.
+func (s *LocalStorage) path(publicId string) (string, error) {
+	cleaned := path.Clean("/" + publicId)
+	if publicId == "" || cleaned != "/"+publicId || strings.Contains(publicId, "\\") {
+		return "", fmt.Errorf("%w: '%v'", errInvalidPublicId, publicId)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(cleaned)), nil
+}
+
+func (s *LocalStorage) url(publicId string) string {
+	return s.baseURL + "/" + publicId
+}
+
+// signs the parts joined by new lines, the signed urls sign the public id and the expiration, the uploads sign
+// "upload" first so their signature is not valid for a download.
+func (s *LocalStorage) sign(parts ...string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *LocalStorage) verify(signature, expires string, parts ...string) bool {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(s.sign(parts...)))
+}
@@ -0,0 +1,208 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"main.go/types"
+)
+
+func pngImage(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	img.Set(0, 0, color.White)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func newLocalStorage(t *testing.T) (*LocalStorage, *http.ServeMux) {
+	t.Helper()
+	local, err := NewLocalStorage(t.TempDir(), "http://localhost:8080"+LocalRoute, []byte("signing-key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := http.NewServeMux()
+	RegisterRoutes(&tracedStorage{backend: local}, router)
+	return local, router
+}
+
+func get(router *http.ServeMux, rawURL string) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", strings.TrimPrefix(rawURL, "http://localhost:8080"), nil))
+	return rr
+}
+
+func TestLocalStorageUploadAndServe(t *testing.T) {
+	local, router := newLocalStorage(t)
+	content := pngImage(t, 3, 2)
+
+	resp, err := local.Upload(context.Background(), bytes.NewReader(content), "my photo.png", types.ProductsFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(resp.PublicID, string(types.ProductsFolder)+"/my_photo_") || !strings.HasSuffix(resp.PublicID, ".png") {
+		t.Fatalf("unexpected public id %v", resp.PublicID)
+	}
+	if resp.Width != 3 || resp.Height != 2 || resp.Format != "png" {
+		t.Fatalf("expected the size and the format of the image, got %+v", resp)
+	}
+
+	rr := get(router, resp.URL)
+	if rr.Code != http.StatusOK || !bytes.Equal(rr.Body.Bytes(), content) {
+		t.Fatalf("expected the image to be served, got %v", rr.Code)
+	}
+}
+
+func TestLocalStorageReplaceAndDelete(t *testing.T) {
+	local, router := newLocalStorage(t)
+	ctx := context.Background()
+
+	resp, err := local.Upload(ctx, bytes.NewReader(pngImage(t, 1, 1)), "a.png", types.UsersFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replaced, err := local.Replace(ctx, resp.PublicID, bytes.NewReader(pngImage(t, 4, 4)), "b.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replaced.URL != resp.URL || replaced.Width != 4 {
+		t.Fatalf("expected the image to be replaced at the same url, got %+v", replaced)
+	}
+
+	other, err := local.Upload(ctx, bytes.NewReader(pngImage(t, 1, 1)), "c.png", types.UsersFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := local.DeleteMany(ctx, []string{resp.PublicID, other.PublicID, "golang-shop/users/missing.png"}); err != nil {
+		t.Fatal(err)
+	}
+	if rr := get(router, resp.URL); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected the deleted image not to be found, got %v", rr.Code)
+	}
+}
+
+func TestLocalStorageRejectsPathsOutsideTheDirectory(t *testing.T) {
+	local, _ := newLocalStorage(t)
+
+	for _, publicId := range []string{"../secret.png", "/etc/passwd", "a/../../b.png", ""} {
+		_, err := local.Replace(context.Background(), publicId, bytes.NewReader(pngImage(t, 1, 1)), "a.png")
+		if !errors.Is(err, errInvalidPublicId) {
+			t.Errorf("expected '%v' to be rejected, got %v", publicId, err)
+		}
+	}
+}
+
+func TestLocalStorageSignedURL(t *testing.T) {
+	local, router := newLocalStorage(t)
+	ctx := context.Background()
+	resp, err := local.Upload(ctx, bytes.NewReader(pngImage(t, 1, 1)), "a.png", types.ProductsFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signedURL, err := local.SignedURL(ctx, resp.PublicID, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rr := get(router, signedURL); rr.Code != http.StatusOK {
+		t.Fatalf("expected the signed url to be served, got %v", rr.Code)
+	}
+
+	if rr := get(router, strings.Replace(signedURL, "signature=", "signature=0", 1)); rr.Code != http.StatusForbidden {
+		t.Fatalf("expected a tampered signature to be refused, got %v", rr.Code)
+	}
+
+	expiredURL, err := local.SignedURL(ctx, resp.PublicID, -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rr := get(router, expiredURL); rr.Code != http.StatusForbidden {
+		t.Fatalf("expected an expired url to be refused, got %v", rr.Code)
+	}
+}
+
+func TestNewPublicIdIsUnique(t *testing.T) {
+	first, err := newPublicId(types.ProductsFolder, "same.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := newPublicId(types.ProductsFolder, "same.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Fatalf("expected different public ids, got %v twice", first)
+	}
+}
+
+func postFile(t *testing.T, router *http.ServeMux, upload *types.SignedUpload, content []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for name, value := range upload.Fields {
+		writer.WriteField(name, value)
+	}
+	part, err := writer.CreateFormFile("file", "a.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(content)
+	writer.Close()
+
+	req := httptest.NewRequest("POST", strings.TrimPrefix(upload.URL, "http://localhost:8080"), &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestLocalStorageSignedUpload(t *testing.T) {
+	local, router := newLocalStorage(t)
+	ctx := context.Background()
+	content := pngImage(t, 2, 2)
+
+	upload, err := local.SignUpload(ctx, types.ProductsFolder, "a.png", int64(len(content)), time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := local.Open(ctx, upload.PublicId); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected the file not to be uploaded yet, got %v", err)
+	}
+
+	tampered := *upload
+	tampered.URL = strings.Replace(upload.URL, "maxSize=", "maxSize=9", 1)
+	if rr := postFile(t, router, &tampered, content); rr.Code != http.StatusForbidden {
+		t.Fatalf("expected a tampered max size to be refused, got %v", rr.Code)
+	}
+	if rr := postFile(t, router, upload, append(content, 0)); rr.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected a larger file to be refused, got %v", rr.Code)
+	}
+	if rr := postFile(t, router, upload, content); rr.Code != http.StatusNoContent {
+		t.Fatalf("expected the file to be stored, got %v %v", rr.Code, rr.Body.String())
+	}
+
+	file, err := local.Open(ctx, upload.PublicId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if stored, _ := io.ReadAll(file); !bytes.Equal(stored, content) {
+		t.Fatal("expected the file to be stored as it was posted")
+	}
+}
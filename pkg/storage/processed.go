@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path"
+	"strings"
+
+	"main.go/pkg/imaging"
+	"main.go/pkg/models"
+	"main.go/types"
+)
+
+// processedStorage validates the uploaded images and stores them with their variants, a variant is stored next to its
+// image under "<public id>_<variant>" so it is replaced and deleted with it.
+type processedStorage struct {
+	ImageStorage
+	options imaging.Options
+}
+
+func (s *processedStorage) Upload(ctx context.Context, file io.Reader, fileName string, folder types.Folder) (*types.UploadResponse, error) {
+	processed, err := s.process(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.ImageStorage.Upload(ctx, bytes.NewReader(processed.Original.Content), withExtension(fileName, processed.Original.Format), folder)
+	if err != nil {
+		return nil, err
+	}
+	response.Variants, err = s.storeVariants(ctx, response.PublicID, processed)
+	if err != nil {
+		// the image is removed so it is not left without its variants
+		s.DeleteMany(ctx, []string{response.PublicID})
+		return nil, err
+	}
+	return response, nil
+}
+
+func (s *processedStorage) Replace(ctx context.Context, publicId string, file io.Reader, fileName string) (*types.UploadResponse, error) {
+	processed, err := s.process(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.ImageStorage.Replace(ctx, publicId, bytes.NewReader(processed.Original.Content), withExtension(fileName, processed.Original.Format))
+	if err != nil {
+		return nil, err
+	}
+	response.Variants, err = s.storeVariants(ctx, response.PublicID, processed)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// the variants are deleted with their image, the images uploaded before the variants were generated have none.
+func (s *processedStorage) Delete(ctx context.Context, publicId string) error {
+	return s.DeleteMany(ctx, []string{publicId})
+}
+
+func (s *processedStorage) DeleteMany(ctx context.Context, publicIds []string) error {
+	withVariants := make([]string, 0, len(publicIds)*(len(imaging.Variants)+1))
+	for _, publicId := range publicIds {
+		withVariants = append(withVariants, publicId)
+		withVariants = append(withVariants, VariantPublicIds(publicId)...)
+	}
+	return s.ImageStorage.DeleteMany(ctx, withVariants)
+}
+
+func (s *processedStorage) process(ctx context.Context, file io.Reader) (*imaging.Processed, error) {
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	return imaging.Process(ctx, content, s.options)
+}
+
+// the variants are written at their public id with Replace, which creates them when they do not exist yet.
+func (s *processedStorage) storeVariants(ctx context.Context, publicId string, processed *imaging.Processed) (models.ImageVariants, error) {
+	variants := make(models.ImageVariants, len(processed.Variants))
+	for _, variant := range imaging.Variants {
+		resized := processed.Variants[variant.Name]
+		response, err := s.ImageStorage.Replace(ctx, variantPublicId(publicId, variant.Name), bytes.NewReader(resized.Content), "."+resized.Format)
+		if err != nil {
+			return nil, err
+		}
+		variants[variant.Name] = models.ImageVariant{
+			URL:      response.SecureUrl,
+			PublicId: response.PublicID,
+			Width:    resized.Width,
+			Height:   resized.Height,
+		}
+	}
+	return variants, nil
+}
+
+// the public ids the variants of an image are stored at, whether they exist or not.
+func VariantPublicIds(publicId string) []string {
+	publicIds := make([]string, 0, len(imaging.Variants))
+	for _, variant := range imaging.Variants {
+		publicIds = append(publicIds, variantPublicId(publicId, variant.Name))
+	}
+	return publicIds
+}
+
+// "products/shoe_1a2b.jpg" gives "products/shoe_1a2b_thumbnail.jpg", the public ids of cloudinary have no extension
+// (their dots are not one).
+func variantPublicId(publicId, variant string) string {
+	extension := path.Ext(publicId)
+	switch strings.ToLower(extension) {
+	case ".jpeg", ".jpg", ".png", ".gif", ".webp":
+	default:
+		extension = ""
+	}
+	return strings.TrimSuffix(publicId, extension) + "_" + variant + extension
+}
+
+// the extension of the file name is replaced by the one of the encoded image.
+func withExtension(fileName, format string) string {
+	return strings.TrimSuffix(fileName, path.Ext(fileName)) + "." + format
+}
@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"main.go/pkg/imaging"
+	"main.go/types"
+)
+
+func newProcessedStorage(t *testing.T) (*processedStorage, *http.ServeMux) {
+	t.Helper()
+	local, _ := newLocalStorage(t)
+	images := &processedStorage{ImageStorage: &tracedStorage{backend: local}, options: imaging.Options{MaxDimension: 2000}}
+
+	router := http.NewServeMux()
+	RegisterRoutes(images, router)
+	return images, router
+}
+
+func TestProcessedStorageStoresAndDeletesTheVariants(t *testing.T) {
+	images, router := newProcessedStorage(t)
+	ctx := context.Background()
+
+	resp, err := images.Upload(ctx, bytes.NewReader(pngImage(t, 1000, 500)), "photo.webp", types.ProductsFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Format != "png" || len(resp.Variants) != len(imaging.Variants) {
+		t.Fatalf("expected a png with its variants, got %+v", resp)
+	}
+	thumbnail := resp.Variants["thumbnail"]
+	if thumbnail.Width != 200 || thumbnail.Height != 100 || thumbnail.PublicId != variantPublicId(resp.PublicID, "thumbnail") {
+		t.Fatalf("unexpected thumbnail %+v", thumbnail)
+	}
+	if rr := get(router, thumbnail.URL); rr.Code != http.StatusOK {
+		t.Fatalf("expected the thumbnail to be served, got %v", rr.Code)
+	}
+
+	if err := images.Delete(ctx, resp.PublicID); err != nil {
+		t.Fatal(err)
+	}
+	for _, url := range []string{resp.URL, thumbnail.URL} {
+		if rr := get(router, url); rr.Code != http.StatusNotFound {
+			t.Fatalf("expected '%v' to be deleted, got %v", url, rr.Code)
+		}
+	}
+}
+
+func TestProcessedStorageRefusesInvalidImages(t *testing.T) {
+	images, _ := newProcessedStorage(t)
+
+	_, err := images.Upload(context.Background(), bytes.NewReader([]byte("GIF89a but not really")), "a.gif", types.UsersFolder)
+	if !errors.Is(err, imaging.ErrInvalidImage) {
+		t.Fatalf("expected the content to be refused, got %v", err)
+	}
+	_, err = images.Upload(context.Background(), bytes.NewReader(pngImage(t, 2001, 1)), "a.png", types.UsersFolder)
+	if !errors.Is(err, imaging.ErrTooLarge) {
+		t.Fatalf("expected the image to be too large, got %v", err)
+	}
+}
+
+func TestVariantPublicId(t *testing.T) {
+	cases := map[string]string{
+		"golang-shop/products/shoe_1a2b.jpeg":          "golang-shop/products/shoe_1a2b_medium.jpeg",
+		"golang-shop/products/shoe.jpg_image_20240101": "golang-shop/products/shoe.jpg_image_20240101_medium",
+		"golang-shop/users/avatar":                     "golang-shop/users/avatar_medium",
+	}
+	for publicId, expected := range cases {
+		if got := variantPublicId(publicId, "medium"); got != expected {
+			t.Errorf("expected '%v' for '%v', got '%v'", expected, publicId, got)
+		}
+	}
+}
+
+func TestConfirmUploadProcessesTheUploadedFile(t *testing.T) {
+	images, _ := newProcessedStorage(t)
+	ctx := context.Background()
+
+	publicIds := make([]string, 0, 2)
+	for _, content := range [][]byte{pngImage(t, 400, 400), []byte("not an image")} {
+		resp, err := images.ImageStorage.Upload(ctx, bytes.NewReader(content), "a.png", types.ProductsFolder)
+		if err != nil {
+			t.Fatal(err)
+		}
+		publicIds = append(publicIds, resp.PublicID)
+	}
+
+	resp, err := ConfirmUpload(ctx, images, publicIds[0], 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.PublicID != publicIds[0] || resp.Variants["thumbnail"].Width != 200 {
+		t.Fatalf("expected the image to be stored again with its variants, got %+v", resp)
+	}
+
+	if _, err := ConfirmUpload(ctx, images, publicIds[0], 100); !errors.Is(err, imaging.ErrInvalidImage) {
+		t.Fatalf("expected a file larger than the max size to be refused, got %v", err)
+	}
+	if _, err := ConfirmUpload(ctx, images, publicIds[1], 1<<20); !errors.Is(err, imaging.ErrInvalidImage) {
+		t.Fatalf("expected a file that is not an image to be refused, got %v", err)
+	}
+	if _, err := ConfirmUpload(ctx, images, "golang-shop/products/missing.png", 1<<20); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected a missing file to be reported, got %v", err)
+	}
+}
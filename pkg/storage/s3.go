@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"main.go/types"
+)
+
+type S3Options struct {
+	// host[:port] of the service, without the scheme.
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+	// the base url of the objects (a cdn in front of the bucket), the endpoint is used when it is nil.
+	PublicURL *url.URL
+}
+
+// S3Storage stores the images in a bucket of any s3 compatible service, the urls of the images are public so the
+// bucket (or the cdn) must allow reading its objects, SignedURL gives access to the objects of a private bucket.
+type S3Storage struct {
+	client  *minio.Client
+	bucket  string
+	baseURL string
+}
+
+func NewS3Storage(options S3Options) (*S3Storage, error) {
+	client, err := minio.New(options.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(options.AccessKey, options.SecretKey, ""),
+		Secure: options.UseSSL,
+		Region: options.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL := ""
+	if options.PublicURL != nil {
+		baseURL = strings.TrimSuffix(options.PublicURL.String(), "/")
+	} else {
+		baseURL = fmt.Sprintf("%s/%s", strings.TrimSuffix(client.EndpointURL().String(), "/"), options.Bucket)
+	}
+
+	return &S3Storage{
+		client:  client,
+		bucket:  options.Bucket,
+		baseURL: baseURL,
+	}, nil
+}
+
+func (s *S3Storage) Name() string {
+	return S3
+}
+
+func (s *S3Storage) Upload(ctx context.Context, file io.Reader, fileName string, folder types.Folder) (*types.UploadResponse, error) {
+	publicId, err := newPublicId(folder, fileName)
+	if err != nil {
+		return nil, err
+	}
+	return s.put(ctx, publicId, file, fileName)
+}
+
+// the object is overwritten so the image keeps its url, a cdn may serve the old image until its cache expires.
+func (s *S3Storage) Replace(ctx context.Context, publicId string, file io.Reader, fileName string) (*types.UploadResponse, error) {
+	return s.put(ctx, publicId, file, fileName)
+}
+
+func (s *S3Storage) Delete(ctx context.Context, publicId string) error {
+	return s.client.RemoveObject(ctx, s.bucket, publicId, minio.RemoveObjectOptions{})
+}
+
+func (s *S3Storage) DeleteMany(ctx context.Context, publicIds []string) error {
+	objects := make(chan minio.ObjectInfo, len(publicIds))
+	for _, publicId := range publicIds {
+		objects <- minio.ObjectInfo{Key: publicId}
+	}
+	close(objects)
+
+	var errs []error
+	for removeErr := range s.client.RemoveObjects(ctx, s.bucket, objects, minio.RemoveObjectsOptions{}) {
+		errs = append(errs, fmt.Errorf("failed to delete '%v': %w", removeErr.ObjectName, removeErr.Err))
+	}
+	return errors.Join(errs...)
+}
+
+func (s *S3Storage) SignedURL(ctx context.Context, publicId string, expiresIn time.Duration) (string, error) {
+	signedURL, err := s.client.PresignedGetObject(ctx, s.bucket, publicId, expiresIn, nil)
+	if err != nil {
+		return "", err
+	}
+	return signedURL.String(), nil
+}
+
+func (s *S3Storage) List(ctx context.Context, folder types.Folder) ([]StoredImage, error) {
+	var images []StoredImage
+	for object := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: string(folder) + "/", Recursive: true}) {
+		if object.Err != nil {
+			return nil, object.Err
+		}
+		images = append(images, StoredImage{PublicId: object.Key, UpdatedAt: object.LastModified})
+	}
+	return images, nil
+}
+
+// a post policy of the bucket, the service refuses the files larger than maxSize. The bucket must allow the cross
+// origin posts of the shop.
+func (s *S3Storage) SignUpload(ctx context.Context, folder types.Folder, fileName string, maxSize int64, expiresIn time.Duration) (*types.SignedUpload, error) {
+	publicId, err := newPublicId(folder, fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(expiresIn)
+	policy := minio.NewPostPolicy()
+	errs := []error{
+		policy.SetBucket(s.bucket),
+		policy.SetKey(publicId),
+		policy.SetExpires(expiresAt),
+		policy.SetContentLengthRange(1, maxSize),
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	postURL, fields, err := s.client.PresignedPostPolicy(ctx, policy)
+	if err != nil {
+		return nil, err
+	}
+	return &types.SignedUpload{
+		PublicId:  publicId,
+		URL:       postURL.String(),
+		Fields:    fields,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (s *S3Storage) Open(ctx context.Context, publicId string) (io.ReadCloser, error) {
+	// GetObject only fails on the first read
+	_, err := s.client.StatObject(ctx, s.bucket, publicId, minio.StatObjectOptions{})
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return nil, fmt.Errorf("%w: '%v'", ErrNotFound, publicId)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.client.GetObject(ctx, s.bucket, publicId, minio.GetObjectOptions{})
+}
+
+func (s *S3Storage) put(ctx context.Context, publicId string, file io.Reader, fileName string) (*types.UploadResponse, error) {
+	data, err := readImage(file, fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.client.PutObject(ctx, s.bucket, publicId, bytes.NewReader(data.content), int64(len(data.content)), minio.PutObjectOptions{
+		ContentType: data.contentType(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	imageURL := s.baseURL + "/" + publicId
+	return &types.UploadResponse{
+		Width: data.width, Height: data.height,
+		SecureUrl: imageURL, PublicID: publicId,
+		URL: imageURL, Format: data.format,
+	}, nil
+}
@@ -0,0 +1,241 @@
+// Package storage stores the product images and the avatars, the backend is chosen by IMAGE_STORAGE: cloudinary,
+// local (the disk of the api, for development and the tests) or any s3 compatible service.
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"main.go/config"
+	"main.go/pkg/imaging"
+	"main.go/types"
+)
+
+const (
+	Cloudinary = "cloudinary"
+	Local      = "local"
+	S3         = "s3"
+)
+
+// ImageStorage is implemented by every backend, the images are identified by the public id returned on upload.
+type ImageStorage interface {
+	// the name of the backend, it is used in the spans of the calls.
+	Name() string
+	Upload(ctx context.Context, file io.Reader, fileName string, folder types.Folder) (*types.UploadResponse, error)
+	// overwrites the image of the public id, its url may change.
+	Replace(ctx context.Context, publicId string, file io.Reader, fileName string) (*types.UploadResponse, error)
+	Delete(ctx context.Context, publicId string) error
+	DeleteMany(ctx context.Context, publicIds []string) error
+	// returns a url giving access to the image until it expires.
+	SignedURL(ctx context.Context, publicId string, expiresIn time.Duration) (string, error)
+	// lists every file stored in the folder, the variants included.
+	List(ctx context.Context, folder types.Folder) ([]StoredImage, error)
+	// returns the form uploading a file of at most maxSize bytes straight to the storage, the file is not processed
+	// so it must go through ConfirmUpload before it is used.
+	SignUpload(ctx context.Context, folder types.Folder, fileName string, maxSize int64, expiresIn time.Duration) (*types.SignedUpload, error)
+	// reads a stored file, ErrNotFound is returned when it does not exist.
+	Open(ctx context.Context, publicId string) (io.ReadCloser, error)
+}
+
+var ErrNotFound = errors.New("the image was not found in the storage")
+
+type StoredImage struct {
+	PublicId string
+	// when the file was written, the files uploaded moments ago may not have their row yet.
+	UpdatedAt time.Time
+}
+
+// builds the backend of the config, its calls are traced and the uploaded images are processed (see imaging.Process).
+func New(cfg config.Config) (ImageStorage, error) {
+	var backend ImageStorage
+	var err error
+	switch cfg.IMAGE_STORAGE {
+	case Cloudinary:
+		backend, err = NewCloudinaryStorage(cfg.CLOUDINARY_NAME, cfg.CLOUDINARY_APIKEY, cfg.CLOUDINARY_SECRET)
+	case Local:
+		var publicURL string
+		publicURL, err = cfg.PublicURL(LocalRoute)
+		if err != nil {
+			return nil, err
+		}
+		backend, err = NewLocalStorage(cfg.LOCAL_STORAGE_DIR, publicURL, []byte(cfg.STORAGE_SIGNING_KEY))
+	case S3:
+		backend, err = NewS3Storage(S3Options{
+			Endpoint:  cfg.S3_ENDPOINT,
+			Region:    cfg.S3_REGION,
+			Bucket:    cfg.S3_BUCKET,
+			AccessKey: cfg.S3_ACCESS_KEY,
+			SecretKey: cfg.S3_SECRET_KEY,
+			UseSSL:    cfg.S3_USE_SSL,
+			PublicURL: cfg.S3_PUBLIC_URL,
+		})
+	default:
+		return nil, fmt.Errorf("unknown image storage '%v', expected one of: cloudinary, local, s3", cfg.IMAGE_STORAGE)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the %v image storage: %w", cfg.IMAGE_STORAGE, err)
+	}
+
+	return &processedStorage{
+		ImageStorage: &tracedStorage{backend: backend},
+		options:      imaging.Options{MaxDimension: cfg.IMAGE_MAX_DIMENSION},
+	}, nil
+}
+
+// registers the routes serving the images of the backends that store them on the api (local), the other backends
+// serve their images themselves.
+func RegisterRoutes(images ImageStorage, router *http.ServeMux) {
+	if processed, ok := images.(*processedStorage); ok {
+		images = processed.ImageStorage
+	}
+	if traced, ok := images.(*tracedStorage); ok {
+		images = traced.backend
+	}
+	if served, ok := images.(interface{ RegisterRoutes(router *http.ServeMux) }); ok {
+		served.RegisterRoutes(router)
+	}
+}
+
+// uploads the files concurrently, the images that were uploaded are returned in the order of their files with the
+// errors of the others.
+func UploadMany(ctx context.Context, images ImageStorage, folder types.Folder, files []*multipart.FileHeader) ([]*types.UploadResponse, []error) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	uploaded := make([]*types.UploadResponse, len(files))
+	errs := make([]error, 0)
+
+	for i, fileHeader := range files {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			response, err := uploadFileHeader(ctx, images, folder, fileHeader)
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+				return
+			}
+			uploaded[i] = response
+		}()
+	}
+
+	wg.Wait()
+	responses := slices.DeleteFunc(uploaded, func(response *types.UploadResponse) bool { return response == nil })
+	return responses, errs
+}
+
+func uploadFileHeader(ctx context.Context, images ImageStorage, folder types.Folder, fileHeader *multipart.FileHeader) (*types.UploadResponse, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return images.Upload(ctx, file, fileHeader.Filename, folder)
+}
+
+// processes a file uploaded with SignUpload and stores it again at its public id with its variants, the files larger
+// than maxSize or that are not images are refused with imaging.ErrInvalidImage.
+func ConfirmUpload(ctx context.Context, images ImageStorage, publicId string, maxSize int64) (*types.UploadResponse, error) {
+	file, err := images.Open(ctx, publicId)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > maxSize {
+		return nil, fmt.Errorf("%w: the file is larger than %v bytes", imaging.ErrInvalidImage, maxSize)
+	}
+
+	return images.Replace(ctx, publicId, bytes.NewReader(content), publicId)
+}
+
+// returns the public id of an image stored in the folder from its url, ok is false for the urls of other hosts (e.g the
+// avatars of the identity providers). The public ids of cloudinary have no extension, its urls have the one of the
+// delivered format.
+func PublicIdFromURL(backend, imageURL string, folder types.Folder) (publicId string, ok bool) {
+	parsed, err := url.Parse(imageURL)
+	if err != nil {
+		return "", false
+	}
+	index := strings.Index(parsed.Path, "/"+string(folder)+"/")
+	if index == -1 {
+		return "", false
+	}
+
+	publicId = parsed.Path[index+1:]
+	if backend == Cloudinary {
+		publicId = strings.TrimSuffix(publicId, path.Ext(publicId))
+	}
+	return publicId, true
+}
+
+// the public id of a new image, the random suffix keeps the images with the same file name apart.
+func newPublicId(folder types.Folder, fileName string) (string, error) {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+
+	extension := strings.ToLower(path.Ext(fileName))
+	name := strings.TrimSuffix(path.Base(fileName), path.Ext(fileName))
+	name = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+
+	return fmt.Sprintf("%s/%s_%s%s", folder, name, hex.EncodeToString(suffix), extension), nil
+}
+
+// the images stored as they are (local, s3) are read in memory to get their size, the uploads are limited to a few MBs.
+type imageData struct {
+	content []byte
+	width   int
+	height  int
+	format  string
+}
+
+func readImage(file io.Reader, fileName string) (*imageData, error) {
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &imageData{
+		content: content,
+		format:  strings.TrimPrefix(strings.ToLower(path.Ext(fileName)), "."),
+	}
+	// the formats without a registered decoder (webp) are stored without their size
+	if imageConfig, format, err := image.DecodeConfig(bytes.NewReader(content)); err == nil {
+		data.width, data.height, data.format = imageConfig.Width, imageConfig.Height, format
+	}
+	return data, nil
+}
+
+func (data *imageData) contentType() string {
+	return http.DetectContentType(data.content)
+}
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"main.go/pkg/metrics"
+	"main.go/pkg/tracing"
+	"main.go/types"
+)
+
+// tracedStorage wraps the backends so each call is a child span of the request, the failed uploads are counted.
+type tracedStorage struct {
+	backend ImageStorage
+}
+
+func (s *tracedStorage) start(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, s.backend.Name()+"."+operation, attribute.String("peer.service", s.backend.Name()))
+}
+
+func (s *tracedStorage) Name() string {
+	return s.backend.Name()
+}
+
+func (s *tracedStorage) Upload(ctx context.Context, file io.Reader, fileName string, folder types.Folder) (*types.UploadResponse, error) {
+	ctx, span := s.start(ctx, "upload")
+	defer span.End()
+
+	response, err := s.backend.Upload(ctx, file, fileName, folder)
+	if err != nil {
+		tracing.RecordError(span, err)
+		metrics.ImageUploadFailures.Inc()
+	}
+	return response, err
+}
+
+func (s *tracedStorage) Replace(ctx context.Context, publicId string, file io.Reader, fileName string) (*types.UploadResponse, error) {
+	ctx, span := s.start(ctx, "replace")
+	defer span.End()
+
+	response, err := s.backend.Replace(ctx, publicId, file, fileName)
+	if err != nil {
+		tracing.RecordError(span, err)
+		metrics.ImageUploadFailures.Inc()
+	}
+	return response, err
+}
+
+func (s *tracedStorage) Delete(ctx context.Context, publicId string) error {
+	ctx, span := s.start(ctx, "delete")
+	defer span.End()
+
+	err := s.backend.Delete(ctx, publicId)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (s *tracedStorage) DeleteMany(ctx context.Context, publicIds []string) error {
+	ctx, span := s.start(ctx, "delete_many")
+	defer span.End()
+	span.SetAttributes(attribute.Int("storage.images_count", len(publicIds)))
+
+	err := s.backend.DeleteMany(ctx, publicIds)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (s *tracedStorage) SignedURL(ctx context.Context, publicId string, expiresIn time.Duration) (string, error) {
+	ctx, span := s.start(ctx, "signed_url")
+	defer span.End()
+
+	signedURL, err := s.backend.SignedURL(ctx, publicId, expiresIn)
+	tracing.RecordError(span, err)
+	return signedURL, err
+}
+
+func (s *tracedStorage) List(ctx context.Context, folder types.Folder) ([]StoredImage, error) {
+	ctx, span := s.start(ctx, "list")
+	defer span.End()
+
+	images, err := s.backend.List(ctx, folder)
+	tracing.RecordError(span, err)
+	return images, err
+}
+
+func (s *tracedStorage) SignUpload(ctx context.Context, folder types.Folder, fileName string, maxSize int64, expiresIn time.Duration) (*types.SignedUpload, error) {
+	ctx, span := s.start(ctx, "sign_upload")
+	defer span.End()
+
+	upload, err := s.backend.SignUpload(ctx, folder, fileName, maxSize, expiresIn)
+	tracing.RecordError(span, err)
+	return upload, err
+}
+
+// the span ends when the file is opened, not when it is read.
+func (s *tracedStorage) Open(ctx context.Context, publicId string) (io.ReadCloser, error) {
+	ctx, span := s.start(ctx, "open")
+	defer span.End()
+
+	file, err := s.backend.Open(ctx, publicId)
+	tracing.RecordError(span, err)
+	return file, err
+}
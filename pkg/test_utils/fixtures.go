@@ -0,0 +1,90 @@
+package test_utils
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"main.go/pkg/models"
+	"main.go/types"
+)
+
+// the ids the route tests use, the mysql test database has them from its seed, a sqlite one is seeded with them.
+var (
+	fixtureSuperAdminId = uint(17)
+	fixtureUserIds      = []uint{33, 46, 60, 61}
+	fixtureCategoryIds  = []uint{1, 15, 17}
+	fixtureProductIds   = []uint{22, 55, 76, 77, 111}
+	// owned by the user 46 on the product 76.
+	fixtureReviewId = uint(40)
+)
+
+// the fixture users are signed in by the generated cookies only, they need no password hash.
+const fixturePassword = "-"
+
+// inserts the rows the route tests expect, the ones already there are kept so it can run on every start.
+func seedFixtures(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var superAdmin, regularUser models.Role
+		if err := tx.Where("name = ?", string(types.SuperAdmin)).First(&superAdmin).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("name = ?", string(types.RegularUser)).First(&regularUser).Error; err != nil {
+			return err
+		}
+
+		users := []models.User{{
+			ModelBasicsTrackedDel: models.ModelBasicsTrackedDel{ID: fixtureSuperAdminId},
+			Name:                  "super admin",
+			Email:                 "texteemail@gmail.com",
+			Password:              fixturePassword,
+		}}
+		userRoles := []models.UserRoles{{UserID: fixtureSuperAdminId, RoleID: superAdmin.ID}}
+		for _, id := range fixtureUserIds {
+			users = append(users, models.User{
+				ModelBasicsTrackedDel: models.ModelBasicsTrackedDel{ID: id},
+				Name:                  fmt.Sprintf("user %v", id),
+				Email:                 fmt.Sprintf("user%v@example.com", id),
+				Password:              fixturePassword,
+			})
+			userRoles = append(userRoles, models.UserRoles{UserID: id, RoleID: regularUser.ID})
+		}
+
+		categories := []models.Category{}
+		for _, id := range fixtureCategoryIds {
+			categories = append(categories, models.Category{
+				ModelBasicsTrackedDel: models.ModelBasicsTrackedDel{ID: id},
+				Name:                  fmt.Sprintf("category %v", id),
+				Path:                  fmt.Sprintf("%v/", id),
+			})
+		}
+
+		products := []models.Product{}
+		for _, id := range fixtureProductIds {
+			products = append(products, models.Product{
+				ModelBasicsTrackedDel: models.ModelBasicsTrackedDel{ID: id},
+				Name:                  fmt.Sprintf("product %v", id),
+				SKU:                   fmt.Sprintf("PRODUCT-%v", id),
+				Slug:                  fmt.Sprintf("product-%v", id),
+				Quantity:              20,
+				Price:                 400,
+				CategoryID:            fixtureCategoryIds[0],
+			})
+		}
+
+		reviews := []models.Review{{
+			ModelBasicsTrackedDel: models.ModelBasicsTrackedDel{ID: fixtureReviewId},
+			UserID:                46,
+			ProductID:             76,
+			Comment:               "fixture review",
+			Rate:                  4,
+		}}
+
+		for _, rows := range []any{&users, &userRoles, &categories, &products, &reviews} {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(rows).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
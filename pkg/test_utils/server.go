@@ -0,0 +1,114 @@
+package test_utils
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"gorm.io/gorm"
+	"main.go/config"
+	"main.go/internal/database"
+	"main.go/internal/database/dialect"
+	"main.go/internal/database/migrations"
+	"main.go/middlewares"
+	"main.go/pkg/storage"
+	"main.go/services"
+)
+
+var (
+	testDB     *gorm.DB
+	testDBOnce sync.Once
+)
+
+// opens the test database once for all the tests of the package, the tests can not run without it so it panics
+// when the database is not reachable or not migrated. A sqlite database is migrated and seeded with the fixtures
+// here so the tests run without a database server, it is the default when DB_DIALECT is not set. Its file is kept in
+// the temp directory unless DB_NAME is an absolute path.
+func DB() *gorm.DB {
+	testDBOnce.Do(func() {
+		if err := config.LoadError(); err != nil {
+			panic(err)
+		}
+		if err := useTestDatabase(); err != nil {
+			panic(err)
+		}
+
+		isSQLite := config.Envs.DB_DIALECT == dialect.SQLite
+		if isSQLite {
+			if err := migrate(config.Envs); err != nil {
+				panic(err)
+			}
+		}
+
+		DB, err := database.Init(config.Envs)
+		if err != nil {
+			panic(err)
+		}
+
+		if isSQLite {
+			if err := seedFixtures(DB); err != nil {
+				panic(err)
+			}
+		}
+		testDB = DB
+	})
+	return testDB
+}
+
+// reloads the config with sqlite when DB_DIALECT is not set, the env files are loaded into the environment so a
+// DB_DIALECT set by one of them is kept. A relative DB_NAME would create the sqlite file in the directory of every
+// tested package.
+func useTestDatabase() error {
+	overrides := config.Overrides{}
+	if _, ok := os.LookupEnv("DB_DIALECT"); !ok {
+		overrides["DB_DIALECT"] = string(dialect.SQLite)
+	}
+	isSQLite := config.Envs.DB_DIALECT == dialect.SQLite || overrides["DB_DIALECT"] == string(dialect.SQLite)
+	if isSQLite && !filepath.IsAbs(config.Envs.DBName) {
+		overrides["DB_NAME"] = filepath.Join(os.TempDir(), config.Envs.DBName+".db")
+	}
+	if len(overrides) == 0 {
+		return nil
+	}
+
+	cfg, err := config.Load(config.LoadOptions{Overrides: overrides})
+	if err != nil {
+		return err
+	}
+	config.Envs = cfg
+	return nil
+}
+
+func migrate(cfg config.Config) error {
+	DB, err := database.Open(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close(DB)
+
+	migrator, err := migrations.NewMigrator(DB, migrations.All())
+	if err != nil {
+		return err
+	}
+	_, err = migrator.Up(0, false)
+	return err
+}
+
+// returns a router with every service registered on the test database.
+func NewServer() *http.ServeMux {
+	DB := DB()
+	if err := middlewares.Setup(DB, config.Envs); err != nil {
+		panic(err)
+	}
+
+	images, err := storage.New(config.Envs)
+	if err != nil {
+		panic(err)
+	}
+
+	server := http.NewServeMux()
+	services.SetupAllServices(DB, images, server)
+	storage.RegisterRoutes(images, server)
+	return server
+}
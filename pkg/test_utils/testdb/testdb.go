@@ -0,0 +1,26 @@
+// Package testdb opens the sqlite databases of the store and migration tests, it is apart from test_utils so the
+// tests inside the services can import it without an import cycle.
+package testdb
+
+import (
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// opens an empty in-memory sqlite database and creates the given tables, the database is gone with the test.
+func SQLite(t testing.TB, tables ...any) *gorm.DB {
+	t.Helper()
+	DB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) > 0 {
+		if err := DB.AutoMigrate(tables...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return DB
+}
@@ -19,7 +19,6 @@ import (
 	"github.com/gorilla/sessions"
 	"github.com/stretchr/testify/assert"
 	"main.go/constants"
-	"main.go/internal/database"
 	"main.go/pkg/models"
 	"main.go/services/auth"
 )
@@ -44,7 +43,7 @@ func GenSuperAdminCookie(w http.ResponseWriter, r *http.Request) error {
 
 func GenCookieByUserId(w http.ResponseWriter, r *http.Request, userId uint) error {
 	var user models.User
-	if err := database.DB.First(&user, userId).Error; err != nil {
+	if err := DB().First(&user, userId).Error; err != nil {
 		return err
 	}
 	accessToken, _, err := auth.GenerateAndSetTokens(user, w, r)
@@ -61,7 +60,7 @@ func GenCookieByUserId(w http.ResponseWriter, r *http.Request, userId uint) erro
 // theoretically it should not be causing any problem to use any of them
 // but "New" has issue and not setting a new cookie on testing server, for that reason this function is created.
 func SetCookieForTesting(w http.ResponseWriter, r *http.Request, user *models.User, accessToken string) (*sessions.Session, error) {
-	session, err := auth.CookiesStore.Get(r, "session_token")
+	session, err := auth.CookiesStore().Get(r, "session_token")
 	if err != nil {
 		return nil, err
 	}
@@ -74,9 +73,13 @@ func SetCookieForTesting(w http.ResponseWriter, r *http.Request, user *models.Us
 		SameSite: http.SameSiteStrictMode,
 	}
 
+	// state-changing requests authenticated by the cookie must send the csrf token
+	csrfToken := "test-csrf-token"
 	session.Values["userId"] = user.ID
 	session.Values["email"] = user.Email
 	session.Values["access_token"] = accessToken
+	session.Values["csrf_token"] = csrfToken
+	r.Header.Set(auth.CSRFHeader, csrfToken)
 
 	err = session.Save(r, w)
 	if err != nil {
@@ -113,6 +116,8 @@ func CreateImageFromData(keyName string, imagePath string) (*bytes.Buffer, *mult
 func CreateTestProduct(productAdjuster func(prod *models.Product) *models.Product) (*models.Product, error) {
 	var product models.Product
 	product.Name = "test product"
+	product.SKU = gofakeit.UUID()
+	product.Slug = "test-product-" + product.SKU
 	product.Quantity = 20
 	product.Price = 400
 	product.CategoryID = 1
@@ -120,7 +125,7 @@ func CreateTestProduct(productAdjuster func(prod *models.Product) *models.Produc
 		productAdjuster(&product)
 	}
 
-	err := database.DB.Model(models.Product{}).Create(&product).Error
+	err := DB().Model(models.Product{}).Create(&product).Error
 	if err != nil {
 		return nil, err
 	}
@@ -135,7 +140,21 @@ func CreateTestCategory(Adjuster func(prod *models.Category) *models.Category) (
 		Adjuster(&category)
 	}
 
-	err := database.DB.Model(models.Category{}).Create(&category).Error
+	err := DB().Model(models.Category{}).Create(&category).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// the path of the category is its ancestors ids then its own id
+	category.Path = fmt.Sprintf("%v/", category.ID)
+	if category.ParentID != nil {
+		var parent models.Category
+		if err := DB().First(&parent, *category.ParentID).Error; err != nil {
+			return nil, err
+		}
+		category.Path = parent.Path + category.Path
+	}
+	err = DB().Model(&category).Update("path", category.Path).Error
 	if err != nil {
 		return nil, err
 	}
@@ -203,7 +222,7 @@ func CreateTestReview(Adjuster func(rev *models.Review) *models.Review) (*models
 		Adjuster(&review)
 	}
 
-	err := database.DB.Model(models.Review{}).Create(&review).Error
+	err := DB().Model(models.Review{}).Create(&review).Error
 	if err != nil {
 		return nil, err
 	}
@@ -213,7 +232,7 @@ func CreateTestReview(Adjuster func(rev *models.Review) *models.Review) (*models
 
 func DeleteResourceById[TModel any](id uint) error {
 	var model TModel
-	err := database.DB.Unscoped().Delete(&model, id).Error
+	err := DB().Unscoped().Delete(&model, id).Error
 	return err
 }
 
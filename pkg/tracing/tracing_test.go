@@ -0,0 +1,96 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+type tracedModel struct {
+	ID   uint
+	Name string
+}
+
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	return recorder
+}
+
+// the dry run builds the queries without a database connection.
+func openDryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "user:pass@tcp(127.0.0.1:1)/db", SkipInitializeWithVersion: true}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(NewGormPlugin("mysql")); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+func TestGormPlugin(t *testing.T) {
+	t.Run("Should create a child span for the queries of a traced context", func(t *testing.T) {
+		recorder := setupRecorder(t)
+		db := openDryRunDB(t)
+
+		ctx, parent := Start(context.Background(), "request")
+		db.WithContext(ctx).Where("name = ?", "phone").Find(&[]tracedModel{})
+		parent.End()
+
+		spans := recorder.Ended()
+		assert.Len(t, spans, 2)
+		assert.Equal(t, "gorm.query", spans[0].Name())
+		assert.Equal(t, parent.SpanContext().TraceID(), spans[0].SpanContext().TraceID())
+		assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+
+		var statement string
+		for _, attr := range spans[0].Attributes() {
+			if attr.Key == "db.statement" {
+				statement = attr.Value.AsString()
+			}
+		}
+		assert.Contains(t, statement, "WHERE name = ?")
+	})
+
+	t.Run("Should trace the queries made outside of a traced context as root spans", func(t *testing.T) {
+		recorder := setupRecorder(t)
+		db := openDryRunDB(t)
+
+		db.Find(&[]tracedModel{})
+
+		spans := recorder.Ended()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, "gorm.query", spans[0].Name())
+		assert.False(t, spans[0].Parent().IsValid())
+	})
+}
+
+func TestSetup(t *testing.T) {
+	t.Run("Should reject an unknown exporter", func(t *testing.T) {
+		_, err := Setup(context.Background(), Options{Exporter: "zipkin"})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Should return the trace id of the context", func(t *testing.T) {
+		setupRecorder(t)
+		assert.Equal(t, "", TraceID(context.Background()))
+
+		ctx, span := Start(context.Background(), "operation")
+		defer span.End()
+		assert.Equal(t, span.SpanContext().TraceID().String(), TraceID(ctx))
+	})
+}
@@ -18,6 +18,15 @@ var Validate = validator.New()
 
 func init(){
 	Validate.RegisterValidation("alphanumWithSpaces", isAlphanumericWithSpaces)
+	Validate.RegisterValidation("sku", isSKU)
+	Validate.RegisterValidation("barcode", isBarcode)
+}
+
+var skuRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// letters, digits, dots, dashes and underscores, starting with a letter or a digit.
+func isSKU(fl validator.FieldLevel) bool {
+	return skuRegex.MatchString(fl.Field().String())
 }
 
 func isAlphanumericWithSpaces(fl validator.FieldLevel) bool {
@@ -50,4 +59,26 @@ func ValidateFile(fileHeader *multipart.FileHeader) error {
 	}
 
 	return fmt.Errorf("invalid file type: %v", fileMimeType)
-}
\ No newline at end of file
+}
+// an EAN-8, UPC-A (12 digits) or EAN-13 barcode with a valid check digit.
+func isBarcode(fl validator.FieldLevel) bool {
+	barcode := fl.Field().String()
+	if len(barcode) != 8 && len(barcode) != 12 && len(barcode) != 13 {
+		return false
+	}
+
+	sum := 0
+	for i := len(barcode) - 2; i >= 0; i-- {
+		digit := int(barcode[i] - '0')
+		if digit < 0 || digit > 9 {
+			return false
+		}
+		// the digits are weighted 3 and 1 from the right of the check digit
+		if (len(barcode)-2-i)%2 == 0 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	checkDigit := int(barcode[len(barcode)-1] - '0')
+	return checkDigit >= 0 && checkDigit <= 9 && (10-sum%10)%10 == checkDigit
+}
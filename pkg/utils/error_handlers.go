@@ -10,16 +10,16 @@ import (
 	"strings"
 
 	"github.com/go-playground/validator/v10"
-	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
 )
 
+// the drivers errors are translated by gorm, so both work on every dialect.
 func IsDuplicateKeyErr(err error) bool {
-	var mysqlErr *mysql.MySQLError
-	if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
-		return true
-	}
+	return errors.Is(err, gorm.ErrDuplicatedKey)
+}
 
-	return false
+func IsForeignKeyErr(err error) bool {
+	return errors.Is(err, gorm.ErrForeignKeyViolated)
 }
 
 func validationErrMsgHandler(errors validator.ValidationErrors) string {
@@ -63,6 +63,15 @@ func validationErrMsgHandler(errors validator.ValidationErrors) string {
 	return message
 }
 
+// the message of the first failed rule of a validation error, the other errors are returned as they are.
+func ValidationErrorMessage(err error) string {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return validationErrMsgHandler(validationErrs)
+	}
+	return err.Error()
+}
+
 func unmarshalErrMsgHandler(error *json.UnmarshalTypeError) string {
 	errMsg := fmt.Sprintf("%v is type %v can't be equal to %v", error.Field, error.Type, error.Value)
 	return errMsg
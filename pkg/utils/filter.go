@@ -7,7 +7,6 @@ import (
 	"sync"
 
 	"gorm.io/gorm"
-	"main.go/internal/database"
 	"main.go/types"
 )
 
@@ -22,7 +21,7 @@ func GenericFilter[T any](config *GenericFilterConfig) (result []T, count int64,
 
 	for _, filter := range config.Filters {
 		if config.WhiteListedParams[filter.Field] != nil {
-			condition := fmt.Sprintf("%s %s ?", filter.Field, filter.Operator)
+			condition := fmt.Sprintf("%s %s ?", filterColumn(filter.Field, config.WhiteListedParams), filter.Operator)
 			query = query.Where(condition, filter.Value)
 		}
 	}
@@ -67,15 +66,13 @@ func GenericFilterWithJoins[TModel any,TRow any](config *GenericFilterConfigWith
 	var err2_Mu sync.Mutex
 	var results []TRow
 
-	DB := database.DB
-
 	page := config.Pagination.Page
 	limit := config.Pagination.Limit
-	query := DB.Model(new(TModel))
+	query := config.DB.Model(new(TModel))
 
 	for _, filter := range config.Filters {
 		if config.WhiteListedParams[filter.Field] != nil {
-			condition := fmt.Sprintf("%s %s ?", filter.Field, filter.Operator)
+			condition := fmt.Sprintf("%s %s ?", filterColumn(filter.Field, config.WhiteListedParams), filter.Operator)
 			query = query.Where(condition, filter.Value)
 		}
 	}
@@ -130,7 +127,7 @@ var whiteListedOperators = map[string]any{
 // This function handles the given params values as long as they not received in array, if received in array only takes first value.
 func GetFilterConditions(r *http.Request, whiteListedParams map[string]any) []types.FilterCondition {
 	params := r.URL.Query()
-	var conditions = make([]types.FilterCondition, len(params))
+	var conditions = make([]types.FilterCondition, 0, len(params))
 	for key, values := range params {
 		field, op := GetFieldOperator(key, whiteListedParams)
 		if field != "" {
@@ -149,18 +146,25 @@ func GetFilterConditions(r *http.Request, whiteListedParams map[string]any) []ty
 //
 // if the key is not white listed then it returns both the field and operator as empty strings.
 func GetFieldOperator(key string, whiteListedParams map[string]any) (field string, operator string) {
-	var cond []string
-	if strings.Contains(key, "_") && len(strings.Split(key, "_")) == 2 && whiteListedParams[key] != nil {
-		cond = strings.Split(key, "_")
-		if whiteListedOperators[cond[1]] != nil {
-			key = cond[0]
-			return key, whiteListedOperators[cond[1]].(string)
-		}
+	if whiteListedParams[key] == nil {
+		return "", ""
+	}
+
+	if i := strings.LastIndex(key, "_"); i != -1 && whiteListedOperators[key[i+1:]] != nil {
+		return key[:i], whiteListedOperators[key[i+1:]].(string)
+	}
+
+	return key, "="
+}
 
-		return key, "="
+// the white listed params are mapped to the column of the same name, unless their value is the name of the column
+// (e.g "categoryId": "products.category_id").
+func filterColumn(field string, whiteListedParams map[string]any) string {
+	if column, ok := whiteListedParams[field].(string); ok {
+		return column
 	}
 
-	return "", ""
+	return field
 }
 
 type GenericFilterConfig struct {
@@ -173,6 +177,7 @@ type GenericFilterConfig struct {
 }
 
 type GenericFilterConfigWithJoins struct {
+	DB *gorm.DB
 	Filters []types.FilterCondition
 	SortQ string
 	Pagination types.Pagination
@@ -5,18 +5,21 @@ import (
 	"errors"
 	"fmt"
 	"mime/multipart"
+	"net"
 	"net/http"
+	"net/netip"
 	"strconv"
 	"strings"
 
 	appErrs "main.go/errors"
 
 	"github.com/go-playground/validator/v10"
-	"github.com/go-sql-driver/mysql"
 	"github.com/golang-jwt/jwt/v5"
 	"main.go/config"
 	"main.go/constants"
+	"main.go/internal/database/dialect"
 	"main.go/pkg/models"
+	"main.go/pkg/tracing"
 )
 
 func Trim(str *string) *string {
@@ -83,7 +86,6 @@ func WriteJSON(w http.ResponseWriter, status int, v any) error {
 func WriteError(w http.ResponseWriter, status int, err error) {
 	errObj := make(map[string]any, 0)
 	var UnmarshalTypeErr *json.UnmarshalTypeError
-	var mySqlError *mysql.MySQLError
 
 	if config.Envs.Env == "production" {
 		if status >= 500 {
@@ -95,7 +97,7 @@ func WriteError(w http.ResponseWriter, status int, err error) {
 			errObj["error"] = validationErrMsgHandler(err.(validator.ValidationErrors))
 			errObj["statusCode"] = status
 
-		} else if errors.As(err, &mySqlError) {
+		} else if dialect.IsDriverError(err) {
 			errObj["error"] = appErrs.ErrGenericMessage.Error()
 			errObj["statusCode"] = 500
 			logCaptureStackTrace()
@@ -115,6 +117,11 @@ func WriteError(w http.ResponseWriter, status int, err error) {
 		errObj["stackTrace"] = logCaptureStackTrace()
 	}
 
+	// set by the tracing middleware, it lets the clients report the error with the trace that produced it.
+	if traceId := w.Header().Get(tracing.TraceIDHeader); traceId != "" {
+		errObj["traceId"] = traceId
+	}
+
 	WriteJSON(w, status, errObj)
 }
 
@@ -295,4 +302,36 @@ func CopyCols(selectedFields []string) []string {
 	colsCopy := make([]string, len(selectedFields))
 	copy(colsCopy, selectedFields)
 	return colsCopy
-}
\ No newline at end of file
+}
+// returns the ip of the client, the "X-Real-IP" header set by the nginx proxy in front of the api is only read when
+// the request comes from one of the TRUSTED_PROXIES, so a client can not pick its ip.
+func GetClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	realIP := strings.TrimSpace(r.Header.Get("X-Real-IP"))
+	if realIP == "" || !isTrustedProxy(host, config.Envs.TRUSTED_PROXIES) {
+		return host
+	}
+	if _, err := netip.ParseAddr(realIP); err != nil {
+		return host
+	}
+
+	return realIP
+}
+
+func isTrustedProxy(host string, proxies []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, proxy := range proxies {
+		if proxy.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -50,7 +50,7 @@ func (h *Handler) GetAddressById(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	address, err := h.store.GetById(*addressId, *userId)
+	address, err := h.store.WithContext(r.Context()).GetById(*addressId, *userId)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("address with id: '%v' does not exist", *addressId))
 		return
@@ -67,7 +67,7 @@ func (h *Handler) GetAllAddress(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	addresses, err := h.store.GetAllAddresses(*userId)
+	addresses, err := h.store.WithContext(r.Context()).GetAllAddresses(*userId)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
@@ -90,7 +90,7 @@ func (h *Handler) CreateAddress(w http.ResponseWriter, r *http.Request) {
 	}
 
 	address := addrPayload.TrimStrs().ToModel(*userId)
-	newAddress, err := h.store.CreateAddress(address)
+	newAddress, err := h.store.WithContext(r.Context()).CreateAddress(address)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
@@ -120,7 +120,7 @@ func (h *Handler) UpdateAddress(w http.ResponseWriter, r *http.Request) {
 		utils.WriteError(w, http.StatusBadRequest, invalidAddressIdErr(receivedStr))
 		return
 	}
-	count, err := h.store.GetUndeletedAddressesCount(*userId)
+	count, err := h.store.WithContext(r.Context()).GetUndeletedAddressesCount(*userId)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
@@ -131,7 +131,7 @@ func (h *Handler) UpdateAddress(w http.ResponseWriter, r *http.Request) {
 	}
 
 	address := addrPayload.TrimStrs().ToModel()
-	updatedAddr, err := h.store.UpdateAddress(*addressId, address, addrPayload)
+	updatedAddr, err := h.store.WithContext(r.Context()).UpdateAddress(*addressId, address, addrPayload)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
@@ -152,7 +152,7 @@ func (h *Handler) DeleteAddress(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.store.DeleteAddress(*addressId ,*userId)
+	err = h.store.WithContext(r.Context()).DeleteAddress(*addressId ,*userId)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
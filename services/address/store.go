@@ -1,6 +1,7 @@
 package address
 
 import (
+	"context"
 	"gorm.io/gorm"
 	"main.go/constants"
 	"main.go/pkg/models"
@@ -21,6 +22,10 @@ func NewStore(DB *gorm.DB) *Store {
 	}
 }
 
+func (addressStore *Store) WithContext(ctx context.Context) types.AddressStore {
+	return NewStore(addressStore.DB.WithContext(ctx))
+}
+
 var (
 	notFoundMsg = "address with id: '%v' was not found"
 )
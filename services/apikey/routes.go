@@ -0,0 +1,120 @@
+package apikey
+
+import (
+	"net/http"
+
+	"main.go/constants"
+	"main.go/errors"
+	"main.go/middlewares"
+	"main.go/pkg/payloads"
+	"main.go/pkg/utils"
+	"main.go/services/auth"
+	"main.go/types"
+)
+
+type Handler struct {
+	store types.ApiKeyStore
+}
+
+func NewHandler(store Store) *Handler {
+	return &Handler{
+		store: &store,
+	}
+}
+
+var Authenticate = middlewares.Authenticate
+var AuthorizePermission = middlewares.AuthorizePermission
+var BlockImpersonation = middlewares.BlockImpersonation
+var Pagination = middlewares.PaginationMiddleware
+var Audit = middlewares.Audit
+
+func invalidApiKeyIdErr(id string) error {
+	return errors.NewInvalidIDError("api key", id)
+}
+
+// the hash of the key is not part of its json, so it never reaches the audit logs.
+func (h *Handler) audit(action types.AuditAction) func(next http.HandlerFunc) http.HandlerFunc {
+	return Audit(action, middlewares.AuditResource{Type: "api-keys", IdKey: constants.IdUrlPathKey, BodyKey: "apiKey", Load: h.store.GetAuditSnapshot})
+}
+
+func (h *Handler) RegisterRoutes(router *http.ServeMux) {
+	router.HandleFunc(utils.RoutePath("GET", "/admin/api-keys"), Pagination(Authenticate(AuthorizePermission(types.PermApiKeysManage)(h.GetAllApiKeys))))
+	router.HandleFunc(utils.RoutePath("POST", "/admin/api-keys"), Authenticate(BlockImpersonation(AuthorizePermission(types.PermApiKeysManage)(h.audit(types.AuditCreate)(h.CreateApiKey)))))
+	router.HandleFunc(utils.RoutePath("DELETE", "/admin/api-keys/{id}"), Authenticate(BlockImpersonation(AuthorizePermission(types.PermApiKeysManage)(h.audit(types.AuditRevoke)(h.RevokeApiKey)))))
+}
+
+func (h *Handler) GetAllApiKeys(w http.ResponseWriter, r *http.Request) {
+	pagination := middlewares.GetPagination(r)
+
+	apiKeys, count, err := h.store.WithContext(r.Context()).GetAllApiKeys(pagination.Page, pagination.Limit)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]any{
+		"page":    pagination.Page,
+		"limit":   pagination.Limit,
+		"count":   count,
+		"apiKeys": apiKeys,
+	})
+}
+
+// the plain key is returned only once inside this response.
+func (h *Handler) CreateApiKey(w http.ResponseWriter, r *http.Request) {
+	ckPayload, err := utils.ValidateAndParseBody[payloads.CreateApiKey](r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	ckPayload.TrimStrs()
+
+	ownerId, err := utils.GetUserIdCtx(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, errors.ErrGenericMessage)
+		return
+	}
+	if ckPayload.UserId != 0 {
+		owner, err := h.store.WithContext(r.Context()).GetUserById(ckPayload.UserId)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		ownerId = &owner.ID
+	}
+
+	plainKey, prefix, keyHash, err := auth.GenerateApiKey()
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	apiKey, err := h.store.WithContext(r.Context()).CreateApiKey(ckPayload.ToModel(*ownerId, prefix, keyHash))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, map[string]any{
+		"apiKey": apiKey,
+		"key":    plainKey,
+	})
+}
+
+func (h *Handler) RevokeApiKey(w http.ResponseWriter, r *http.Request) {
+	Id, receivedStr, err := utils.GetValidateId(r, constants.IdUrlPathKey)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, invalidApiKeyIdErr(receivedStr))
+		return
+	}
+
+	apiKey, err := h.store.WithContext(r.Context()).RevokeApiKey(*Id)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]any{
+		"apiKey": apiKey,
+	})
+}
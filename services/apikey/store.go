@@ -0,0 +1,85 @@
+package apikey
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	appErrors "main.go/errors"
+	"main.go/pkg/models"
+	"main.go/services/generic"
+	"main.go/types"
+)
+
+var (
+	notFoundMsg = "api key with id: '%v' was not found"
+)
+
+type Store struct {
+	DB      *gorm.DB
+	Generic *generic.GenericRepository[models.ApiKey]
+}
+
+func NewStore(DB *gorm.DB) *Store {
+	return &Store{
+		DB:      DB,
+		Generic: &generic.GenericRepository[models.ApiKey]{DB: DB},
+	}
+}
+
+func (apiKeyStore *Store) WithContext(ctx context.Context) types.ApiKeyStore {
+	return NewStore(apiKeyStore.DB.WithContext(ctx))
+}
+
+func (apiKeyStore *Store) GetAllApiKeys(page, limit int) ([]models.ApiKey, int64, error) {
+	apiKeys, count, errs := apiKeyStore.Generic.GetAll(page, limit)
+	if len(errs) != 0 {
+		return nil, 0, errs[0]
+	}
+
+	return apiKeys, count, nil
+}
+
+func (apiKeyStore *Store) CreateApiKey(apiKey *models.ApiKey) (*models.ApiKey, error) {
+	err := apiKeyStore.DB.Create(apiKey).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return apiKey, nil
+}
+
+// revoking keeps the row so the key usage stays traceable, revoked keys are rejected by the authentication middleware.
+func (apiKeyStore *Store) RevokeApiKey(id uint) (*models.ApiKey, error) {
+	apiKey, err := apiKeyStore.Generic.GetOne(id, notFoundMsg)
+	if err != nil {
+		return nil, err
+	}
+
+	if apiKey.RevokedAt != nil {
+		return &apiKey, nil
+	}
+
+	now := time.Now()
+	err = apiKeyStore.DB.Model(&apiKey).UpdateColumn("revoked_at", now).Error
+	if err != nil {
+		return nil, err
+	}
+	apiKey.RevokedAt = &now
+
+	return &apiKey, nil
+}
+
+func (apiKeyStore *Store) GetUserById(id uint) (*models.User, error) {
+	var user models.User
+	err := apiKeyStore.DB.First(&user, id).Error
+	if err != nil {
+		return nil, appErrors.NewResourceWasNotFoundError("user with id: '%v' was not found", id)
+	}
+
+	return &user, nil
+}
+
+func (apiKeyStore *Store) GetAuditSnapshot(ctx context.Context, id uint) (any, error) {
+	return apiKeyStore.Generic.GetAuditSnapshot(ctx, id)
+}
@@ -0,0 +1,87 @@
+package auditlog
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"main.go/middlewares"
+	"main.go/pkg/utils"
+	"main.go/types"
+)
+
+type Handler struct {
+	store types.AuditLogStore
+}
+
+func NewHandler(store Store) *Handler {
+	return &Handler{
+		store: &store,
+	}
+}
+
+var Authenticate = middlewares.Authenticate
+var AuthorizePermission = middlewares.AuthorizePermission
+var Pagination = middlewares.PaginationMiddleware
+
+func (h *Handler) RegisterRoutes(router *http.ServeMux) {
+	router.HandleFunc(utils.RoutePath("GET", "/admin/audit-logs"), Pagination(Authenticate(AuthorizePermission(types.PermAuditLogsRead)(h.GetAllAuditLogs))))
+}
+
+// the filters are read from the query: actorId, action, resourceType, resourceId, from and to (RFC3339).
+func getAuditLogFilter(r *http.Request) (*types.AuditLogFilter, error) {
+	query := r.URL.Query()
+	filter := &types.AuditLogFilter{
+		Action:       query.Get("action"),
+		ResourceType: query.Get("resourceType"),
+	}
+
+	for key, target := range map[string]**uint{"actorId": &filter.ActorID, "resourceId": &filter.ResourceID} {
+		value := query.Get(key)
+		if value == "" {
+			continue
+		}
+		id, err := utils.ConvertStrToUint(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %v: '%v'", key, value)
+		}
+		*target = id
+	}
+
+	for key, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		value := query.Get(key)
+		if value == "" {
+			continue
+		}
+		date, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %v: '%v', expected RFC3339 date (e.g 2024-01-02T15:04:05Z)", key, value)
+		}
+		*target = &date
+	}
+
+	return filter, nil
+}
+
+func (h *Handler) GetAllAuditLogs(w http.ResponseWriter, r *http.Request) {
+	pagination := middlewares.GetPagination(r)
+
+	filter, err := getAuditLogFilter(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	auditLogs, count, err := h.store.WithContext(r.Context()).GetAllAuditLogs(pagination.Page, pagination.Limit, filter)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]any{
+		"page":      pagination.Page,
+		"limit":     pagination.Limit,
+		"count":     count,
+		"auditLogs": auditLogs,
+	})
+}
@@ -0,0 +1,64 @@
+package auditlog
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"main.go/pkg/models"
+	"main.go/pkg/utils"
+	"main.go/types"
+)
+
+type Store struct {
+	DB *gorm.DB
+}
+
+func NewStore(DB *gorm.DB) *Store {
+	return &Store{
+		DB: DB,
+	}
+}
+
+func (auditLogStore *Store) WithContext(ctx context.Context) types.AuditLogStore {
+	return NewStore(auditLogStore.DB.WithContext(ctx))
+}
+
+func applyAuditLogFilter(query *gorm.DB, filter *types.AuditLogFilter) *gorm.DB {
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.ResourceType != "" {
+		query = query.Where("resource_type = ?", filter.ResourceType)
+	}
+	if filter.ResourceID != nil {
+		query = query.Where("resource_id = ?", *filter.ResourceID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at <= ?", *filter.To)
+	}
+
+	return query
+}
+
+func (auditLogStore *Store) GetAllAuditLogs(page, limit int, filter *types.AuditLogFilter) ([]models.AuditLog, int64, error) {
+	var auditLogs []models.AuditLog
+	var count int64
+
+	err := applyAuditLogFilter(auditLogStore.DB.Model(&models.AuditLog{}), filter).Count(&count).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	offset := utils.CalculateOffset(page, limit)
+	err = applyAuditLogFilter(auditLogStore.DB, filter).Order("id DESC").Offset(offset).Limit(limit).Find(&auditLogs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return auditLogs, count, nil
+}
@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+const (
+	apiKeyPrefix      = "gsk_"
+	apiKeySecretBytes = 24
+	apiKeyPrefixLen   = 12
+)
+
+// generates a new api key, the plain key must be shown to the user only once and never stored.
+func GenerateApiKey() (plainKey string, prefix string, keyHash string, err error) {
+	buf := make([]byte, apiKeySecretBytes)
+	_, err = rand.Read(buf)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	plainKey = apiKeyPrefix + hex.EncodeToString(buf)
+	return plainKey, plainKey[:apiKeyPrefixLen], HashApiKey(plainKey), nil
+}
+
+// api keys are random with high entropy so a fast hash is enough, and it allows looking up the key by its hash.
+func HashApiKey(plainKey string) string {
+	sum := sha256.Sum256([]byte(plainKey))
+	return hex.EncodeToString(sum[:])
+}
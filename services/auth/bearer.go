@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"main.go/errors"
+)
+
+// where the access token of the current request was read from, cookie authenticated requests must pass the csrf check
+// on state-changing methods while bearer authenticated requests are not exposed to csrf.
+type TokenSource string
+
+const (
+	CookieTokenSource TokenSource = "cookie"
+	BearerTokenSource TokenSource = "bearer"
+
+	AuthorizationHeader = "Authorization"
+	RefreshTokenHeader  = "X-Refresh-Token"
+	bearerPrefix        = "Bearer "
+)
+
+// returns the token of the "Authorization: Bearer <jwt>" header.
+//
+// exists is false when the header is not sent at all, if the header is sent with a different scheme or an empty token an error is returned.
+func GetBearerToken(r *http.Request) (token *string, exists bool, err error) {
+	header := r.Header.Get(AuthorizationHeader)
+	if header == "" {
+		return nil, false, nil
+	}
+
+	if len(header) < len(bearerPrefix) || !strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+		return nil, true, errors.ErrUnauthorized
+	}
+
+	tokenStr := strings.TrimSpace(header[len(bearerPrefix):])
+	if tokenStr == "" {
+		return nil, true, errors.ErrUnauthorized
+	}
+
+	return &tokenStr, true, nil
+}
+
+// state-changing methods are the methods that must be protected against csrf when the request is cookie authenticated.
+func IsStateChangingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
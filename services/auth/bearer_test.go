@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"main.go/config"
+	"main.go/pkg/models"
+)
+
+// the secret is read once by the cookies store, every test of the package must use the same one.
+func useTestConfig(t *testing.T) {
+	t.Helper()
+	envs := config.Envs
+	config.Envs.JWT_SECRET = "test-secret"
+	config.Envs.ACCESS_JWT_EXPIRATION = time.Minute
+	config.Envs.REFRESH_JWT_EXPIRATION = time.Hour
+	t.Cleanup(func() { config.Envs = envs })
+}
+
+// signs the user in and returns a request carrying its session cookie and the csrf token of the session.
+func cookieRequest(t *testing.T, method string, user *models.User) (*http.Request, string) {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	_, _, err := GenerateAndSetTokens(*user, recorder, httptest.NewRequest(http.MethodPost, "/login", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest(method, "/", nil)
+	csrfToken := ""
+	for _, cookie := range recorder.Result().Cookies() {
+		r.AddCookie(cookie)
+		if cookie.Name == CSRFCookieName {
+			csrfToken = cookie.Value
+		}
+	}
+	return r, csrfToken
+}
+
+func TestGetBearerToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		token   string
+		exists  bool
+		wantErr bool
+	}{
+		{name: "Should report a missing header", header: ""},
+		{name: "Should return the token", header: "Bearer abc", token: "abc", exists: true},
+		{name: "Should accept any case of the scheme", header: "bearer abc", token: "abc", exists: true},
+		{name: "Should refuse another scheme", header: "Basic abc", exists: true, wantErr: true},
+		{name: "Should refuse an empty token", header: "Bearer   ", exists: true, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if test.header != "" {
+				r.Header.Set(AuthorizationHeader, test.header)
+			}
+
+			token, exists, err := GetBearerToken(r)
+			assert.Equal(t, test.exists, exists)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			if test.token == "" {
+				assert.Nil(t, token)
+			} else {
+				assert.Equal(t, test.token, *token)
+			}
+		})
+	}
+}
+
+func TestGetAccessToken(t *testing.T) {
+	useTestConfig(t)
+	user := &models.User{ModelBasicsTrackedDel: models.ModelBasicsTrackedDel{ID: 1}, Email: "user@example.com"}
+
+	t.Run("Should read the session cookie without the header", func(t *testing.T) {
+		r, _ := cookieRequest(t, http.MethodGet, user)
+		token, source, err := GetAccessToken(r)
+		assert.NoError(t, err)
+		assert.Equal(t, CookieTokenSource, source)
+		assert.NotEmpty(t, *token)
+	})
+
+	t.Run("Should prefer the bearer token over the session cookie", func(t *testing.T) {
+		r, _ := cookieRequest(t, http.MethodGet, user)
+		r.Header.Set(AuthorizationHeader, "Bearer abc")
+		token, source, err := GetAccessToken(r)
+		assert.NoError(t, err)
+		assert.Equal(t, BearerTokenSource, source)
+		assert.Equal(t, "abc", *token)
+	})
+
+	t.Run("Should not fall back to the cookie when the header is invalid", func(t *testing.T) {
+		r, _ := cookieRequest(t, http.MethodGet, user)
+		r.Header.Set(AuthorizationHeader, "Basic abc")
+		_, source, err := GetAccessToken(r)
+		assert.Error(t, err)
+		assert.Equal(t, BearerTokenSource, source)
+	})
+
+	t.Run("Should refuse a request without any token", func(t *testing.T) {
+		_, _, err := GetAccessToken(httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Error(t, err)
+	})
+}
+
+func TestIsStateChangingMethod(t *testing.T) {
+	for method, expected := range map[string]bool{
+		http.MethodGet: false, http.MethodHead: false, http.MethodOptions: false,
+		http.MethodPost: true, http.MethodPut: true, http.MethodPatch: true, http.MethodDelete: true,
+	} {
+		assert.Equal(t, expected, IsStateChangingMethod(method), method)
+	}
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"net/http"
+	"sync"
 
 	"github.com/gorilla/sessions"
 	"main.go/config"
@@ -11,10 +12,21 @@ import (
 
 const CookieMaxAge = 1209600 // 14 days
 
-var CookiesStore = sessions.NewCookieStore([]byte(config.Envs.JWT_SECRET))
+var (
+	cookiesStore     *sessions.CookieStore
+	cookiesStoreOnce sync.Once
+)
+
+// built on first use so the JWT_SECRET given by the flags of the api is used.
+func CookiesStore() *sessions.CookieStore {
+	cookiesStoreOnce.Do(func() {
+		cookiesStore = sessions.NewCookieStore([]byte(config.Envs.JWT_SECRET))
+	})
+	return cookiesStore
+}
 
 func GetCookie(r *http.Request) (*sessions.Session, error) {
-	session, err := CookiesStore.Get(r, "session_token")
+	session, err := CookiesStore().Get(r, "session_token")
 	if err != nil {
 		return nil, err
 	}
@@ -23,7 +35,7 @@ func GetCookie(r *http.Request) (*sessions.Session, error) {
 }
 
 func SetCookie(w http.ResponseWriter, r *http.Request, user *models.User, accessToken, refreshToken string) (*sessions.Session, error) {
-	session, err := CookiesStore.New(r, "session_token")
+	session, err := CookiesStore().New(r, "session_token")
 	if err != nil {
 		return nil, err
 	}
@@ -36,15 +48,22 @@ func SetCookie(w http.ResponseWriter, r *http.Request, user *models.User, access
 		SameSite: http.SameSiteStrictMode,
 	}
 
+	csrfToken, err := newCSRFToken()
+	if err != nil {
+		return nil, err
+	}
+
 	session.Values["userId"] = user.ID
 	session.Values["email"] = user.Email
 	session.Values["access_token"] = accessToken
 	session.Values["refresh_token"] = refreshToken
+	session.Values[csrfSessionKey] = csrfToken
 	
 	err = session.Save(r, w)
 	if err != nil {
 		return nil, err
 	}
+	setCSRFCookie(w, csrfToken)
 
 	return session, nil
 }
\ No newline at end of file
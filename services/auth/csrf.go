@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+
+	"main.go/constants"
+	"main.go/errors"
+)
+
+// csrf protection uses the double submit pattern, the token is stored inside the signed session cookie
+// and exposed to the frontend in a readable cookie, the frontend must send it back inside the "X-CSRF-Token" header.
+const (
+	CSRFHeader        = "X-CSRF-Token"
+	CSRFCookieName    = "csrf_token"
+	csrfSessionKey    = "csrf_token"
+	csrfTokenSizeByte = 32
+)
+
+func newCSRFToken() (string, error) {
+	buf := make([]byte, csrfTokenSizeByte)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// the cookie is not http only on purpose, it must be readable by the frontend to be sent in the header.
+func setCSRFCookie(w http.ResponseWriter, csrfToken string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     CSRFCookieName,
+		Value:    csrfToken,
+		MaxAge:   CookieMaxAge,
+		Path:     constants.Prefix,
+		HttpOnly: false,
+		Secure:   false,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// compares the csrf header against the token stored inside the session cookie.
+func ValidateCSRF(r *http.Request) error {
+	session, err := GetCookie(r)
+	if err != nil {
+		return errors.ErrInvalidCSRFToken
+	}
+
+	expected, ok := session.Values[csrfSessionKey].(string)
+	if !ok || expected == "" {
+		return errors.ErrInvalidCSRFToken
+	}
+
+	received := r.Header.Get(CSRFHeader)
+	if received == "" {
+		return errors.ErrInvalidCSRFToken
+	}
+
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(received)) != 1 {
+		return errors.ErrInvalidCSRFToken
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"main.go/pkg/models"
+)
+
+func TestValidateCSRF(t *testing.T) {
+	useTestConfig(t)
+	user := &models.User{ModelBasicsTrackedDel: models.ModelBasicsTrackedDel{ID: 1}, Email: "user@example.com"}
+
+	tests := []struct {
+		name    string
+		header  func(csrfToken string) string
+		wantErr bool
+	}{
+		{name: "Should accept the token of the session", header: func(csrfToken string) string { return csrfToken }},
+		{name: "Should refuse a missing token", header: func(string) string { return "" }, wantErr: true},
+		{name: "Should refuse a mismatched token", header: func(csrfToken string) string { return csrfToken + "x" }, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, csrfToken := cookieRequest(t, http.MethodPost, user)
+			assert.NotEmpty(t, csrfToken)
+			if header := test.header(csrfToken); header != "" {
+				r.Header.Set(CSRFHeader, header)
+			}
+
+			err := ValidateCSRF(r)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("Should refuse a token without a session", func(t *testing.T) {
+		_, csrfToken := cookieRequest(t, http.MethodPost, user)
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		r.Header.Set(CSRFHeader, csrfToken)
+		assert.Error(t, ValidateCSRF(r))
+	})
+}
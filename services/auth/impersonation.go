@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"main.go/config"
+	"main.go/pkg/models"
+)
+
+// the responses of impersonated requests carry the id of the impersonator inside this header.
+const ImpersonatedByHeader = "X-Impersonated-By"
+
+type ImpersonationClaims struct {
+	ImpersonatorID uint
+	SessionID      uint
+}
+
+// the token identifies the target user like a regular access token and carries the impersonator and the session,
+// its only returned in the body so the cookies of the impersonator are not replaced.
+func GenerateImpersonationToken(target *models.User, impersonatorId, sessionId uint, expiresAt time.Time) (string, error) {
+	claims := jwt.MapClaims{
+		"userId":                 target.ID,
+		"email":                  target.Email,
+		"impersonatorId":         impersonatorId,
+		"impersonationSessionId": sessionId,
+		"typ":                    AccessTokenType,
+		"iat":                    time.Now().Unix(),
+		"exp":                    expiresAt.Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(config.Envs.JWT_SECRET))
+}
+
+// returns false when the token is not an impersonation token.
+func GetImpersonationFromClaims(claims jwt.MapClaims) (*ImpersonationClaims, bool) {
+	impersonatorId, ok := claims["impersonatorId"].(float64)
+	if !ok {
+		return nil, false
+	}
+
+	sessionId, ok := claims["impersonationSessionId"].(float64)
+	if !ok {
+		return nil, false
+	}
+
+	return &ImpersonationClaims{
+		ImpersonatorID: uint(impersonatorId),
+		SessionID:      uint(sessionId),
+	}, true
+}
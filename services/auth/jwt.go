@@ -3,7 +3,6 @@ package auth
 import (
 	"fmt"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -21,16 +20,17 @@ func Unauthorized(w http.ResponseWriter) {
 	utils.WriteError(w, http.StatusUnauthorized, errors.ErrUnauthorized)
 }
 
-func ValidateToken(tokenString *string) (*jwt.Token, error) {
-	token, err := jwt.Parse(*tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.ErrInvalidToken
-		}
+// the "typ" claim of the tokens, a refresh token is not accepted in place of an access token and the other way around.
+const (
+	AccessTokenType  = "access"
+	RefreshTokenType = "refresh"
+)
 
+// the token must be signed with HS256, not be expired (its "exp" claim is required) and be of the given type.
+func ValidateToken(tokenString *string, tokenType string) (*jwt.Token, error) {
+	token, err := jwt.Parse(*tokenString, func(token *jwt.Token) (interface{}, error) {
 		return []byte(config.Envs.JWT_SECRET), nil
-	})
-
-	jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name})
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name}), jwt.WithExpirationRequired(), jwt.WithIssuedAt())
 
 	if err != nil {
 		return nil, err
@@ -41,6 +41,11 @@ func ValidateToken(tokenString *string) (*jwt.Token, error) {
 		return nil, errors.ErrInvalidToken
 	}
 
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != tokenType {
+		return nil, errors.ErrInvalidToken
+	}
+
 	return token, nil
 }
 
@@ -88,36 +93,51 @@ func GenerateAndSetTokens(user models.User, w http.ResponseWriter, r *http.Reque
 	return accessToken, refreshToken, nil
 }
 
-func GetRefreshToken(r *http.Request)(*string, error) {
+// the refresh token is read from the "X-Refresh-Token" header for bearer clients, otherwise from the cookie.
+func GetRefreshToken(r *http.Request)(*string, TokenSource, error) {
+	if headerToken := r.Header.Get(RefreshTokenHeader); headerToken != "" {
+		return &headerToken, BearerTokenSource, nil
+	}
+
 	session, err := GetCookie(r)
 	if err != nil {
-		return nil, errors.ErrUnauthorized
+		return nil, CookieTokenSource, errors.ErrUnauthorized
 	}
 
 	token := session.Values["refresh_token"]
 	var tokenAsString string
 	tokenAsString, ok := token.(string); 
 	if !ok {
-		return nil, errors.ErrGenericMessage
+		return nil, CookieTokenSource, errors.ErrUnauthorized
 	}
 
-	return &tokenAsString, nil
+	return &tokenAsString, CookieTokenSource, nil
 }
 
-func GetAccessToken(r *http.Request) (*string, error) {
+// the "Authorization: Bearer <jwt>" header takes precedence over the session cookie, the returned source
+// is used by the authentication middleware to decide whether the csrf check is required.
+func GetAccessToken(r *http.Request) (*string, TokenSource, error) {
+	bearerToken, exists, err := GetBearerToken(r)
+	if exists {
+		if err != nil {
+			return nil, BearerTokenSource, err
+		}
+		return bearerToken, BearerTokenSource, nil
+	}
+
 	session, err := GetCookie(r)
 	if err != nil {
-		return nil, errors.ErrUnauthorized
+		return nil, CookieTokenSource, errors.ErrUnauthorized
 	}
 
 	token := session.Values["access_token"]
 	var tokenAsString string
 	tokenAsString, ok := token.(string); 
 	if !ok {
-		return nil, errors.ErrGenericMessage
+		return nil, CookieTokenSource, errors.ErrGenericMessage
 	}
 
-	return &tokenAsString, nil
+	return &tokenAsString, CookieTokenSource, nil
 }
 
 // generates access token and sets the cookie with the refresh token and the new generated access token.
@@ -133,16 +153,21 @@ func GenerateAccessTokenAndSetTokens(refreshToken string ,user *models.User, w h
 	return accessToken, nil
 }
 
+// generates an access token without setting any cookie, this is meant for bearer clients.
+func GenerateAccessToken(user *models.User) (string, error) {
+	secret := config.Envs.JWT_SECRET
+	return createAccessToken(user, []byte(secret))
+}
+
 func createAccessToken(user *models.User, secret []byte) (string, error){
-	accessDurationInt, err := strconv.Atoi(config.Envs.ACCESS_JWT_EXPIRATION_IN_SECONDS)
-	if err != nil {
-		return "", err
-	}
-	accessExpiration := time.Second * time.Duration(accessDurationInt)
+	accessExpiration := config.Envs.ACCESS_JWT_EXPIRATION
+	now := time.Now()
 	accessClaims := jwt.MapClaims{
-		"userId":    user.ID,
-		"email":     user.Email,
-		"expiredAt": time.Now().Add(accessExpiration).Unix(),
+		"userId": user.ID,
+		"email":  user.Email,
+		"typ":    AccessTokenType,
+		"iat":    now.Unix(),
+		"exp":    now.Add(accessExpiration).Unix(),
 	}
 	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
 	stringAccessToken, err := accessToken.SignedString(secret)
@@ -154,14 +179,13 @@ func createAccessToken(user *models.User, secret []byte) (string, error){
 }
 
 func createRefreshToken(user *models.User, secret []byte) (string, error){
-	refreshDurationInt, err := strconv.Atoi(config.Envs.REFRESH_JWT_EXPIRATION_IN_SECONDS)
-	if err != nil {
-		return "",err
-	}
-	refreshExpiration := time.Second * time.Duration(refreshDurationInt)
+	refreshExpiration := config.Envs.REFRESH_JWT_EXPIRATION
+	now := time.Now()
 	refreshClaims := jwt.MapClaims{
-		"userId":    user.ID,
-		"expiredAt": time.Now().Add(refreshExpiration).Unix(),
+		"userId": user.ID,
+		"typ":    RefreshTokenType,
+		"iat":    now.Unix(),
+		"exp":    now.Add(refreshExpiration).Unix(),
 	}
 
 	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
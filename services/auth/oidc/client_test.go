@@ -0,0 +1,109 @@
+package oidc_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"main.go/services/auth/oidc"
+	"main.go/services/auth/oidc/oidctest"
+)
+
+const redirectURL = "http://localhost:8080/api/auth/oidc/fake/callback"
+
+// follows the authorization url without following the redirect to the callback and returns the callback query.
+func authorize(t *testing.T, authURL string) url.Values {
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	res, err := client.Get(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	location, err := res.Location()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return location.Query()
+}
+
+func setup(t *testing.T) (*oidctest.Provider, *oidc.Client) {
+	provider, err := oidctest.NewProvider("client-id", "client-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(provider.Close)
+
+	client := oidc.NewClient(oidc.ProviderConfig{
+		Name:         "fake",
+		Issuer:       provider.Issuer(),
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		RedirectURL:  redirectURL,
+	}, nil)
+
+	return provider, client
+}
+
+func TestClient_AuthorizationCodeFlow(t *testing.T) {
+	t.Run("Should return the claims of the signed in user", func(t *testing.T) {
+		provider, client := setup(t)
+		provider.SetUser(oidctest.User{Subject: "subject-1", Email: "user@example.com", EmailVerified: true, Name: "User"})
+		ctx := context.Background()
+
+		verifier, _ := oidc.NewCodeVerifier()
+		authURL, err := client.AuthCodeURL(ctx, "state-1", "nonce-1", verifier)
+		assert.Nil(t, err)
+
+		callbackQuery := authorize(t, authURL)
+		assert.Equal(t, "state-1", callbackQuery.Get("state"))
+
+		claims, err := client.Exchange(ctx, callbackQuery.Get("code"), verifier, "nonce-1")
+		assert.Nil(t, err)
+		assert.Equal(t, "subject-1", claims.Subject)
+		assert.Equal(t, "user@example.com", claims.Email)
+		assert.True(t, claims.EmailVerified)
+	})
+
+	t.Run("Should reject a wrong code verifier", func(t *testing.T) {
+		_, client := setup(t)
+		ctx := context.Background()
+
+		verifier, _ := oidc.NewCodeVerifier()
+		authURL, err := client.AuthCodeURL(ctx, "state-1", "nonce-1", verifier)
+		assert.Nil(t, err)
+
+		callbackQuery := authorize(t, authURL)
+		otherVerifier, _ := oidc.NewCodeVerifier()
+		_, err = client.Exchange(ctx, callbackQuery.Get("code"), otherVerifier, "nonce-1")
+		assert.ErrorIs(t, err, oidc.ErrTokenExchange)
+	})
+
+	t.Run("Should reject a wrong nonce", func(t *testing.T) {
+		_, client := setup(t)
+		ctx := context.Background()
+
+		verifier, _ := oidc.NewCodeVerifier()
+		authURL, err := client.AuthCodeURL(ctx, "state-1", "nonce-1", verifier)
+		assert.Nil(t, err)
+
+		callbackQuery := authorize(t, authURL)
+		_, err = client.Exchange(ctx, callbackQuery.Get("code"), verifier, "nonce-2")
+		assert.ErrorIs(t, err, oidc.ErrNonceMismatch)
+	})
+
+	t.Run("Should reject an invalid id token", func(t *testing.T) {
+		_, client := setup(t)
+
+		_, err := client.VerifyIDToken(context.Background(), "header.payload.signature", "nonce-1")
+		assert.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+	})
+}
@@ -0,0 +1,171 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// the claims of the id token used to find or create the user.
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+	Nonce         string
+}
+
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Email         string `json:"email"`
+	EmailVerified any    `json:"email_verified"`
+	Name          string `json:"name"`
+	Picture       string `json:"picture"`
+	Nonce         string `json:"nonce"`
+}
+
+// some providers send "email_verified" as a string.
+func (c *idTokenClaims) isEmailVerified() bool {
+	switch verified := c.EmailVerified.(type) {
+	case bool:
+		return verified
+	case string:
+		return verified == "true"
+	}
+
+	return false
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// keySet caches the rsa keys of the provider, the keys are refetched when a token is signed by an unknown key
+// to support the keys rotation, the refetch is limited to once per minute.
+type keySet struct {
+	uri       string
+	fetchJSON func(ctx context.Context, url string, v any) error
+
+	mu          sync.Mutex
+	keys        map[string]*rsa.PublicKey
+	lastFetched time.Time
+}
+
+const minKeysRefetchInterval = time.Minute
+
+func newKeySet(uri string, fetchJSON func(ctx context.Context, url string, v any) error) *keySet {
+	return &keySet{
+		uri:       uri,
+		fetchJSON: fetchJSON,
+		keys:      map[string]*rsa.PublicKey{},
+	}
+}
+
+func (s *keySet) getKey(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+
+	if time.Since(s.lastFetched) < minKeysRefetchInterval {
+		return nil, fmt.Errorf("%w: unknown key id '%v'", ErrInvalidIDToken, kid)
+	}
+
+	var set jsonWebKeySet
+	err := s.fetchJSON(ctx, s.uri, &set)
+	if err != nil {
+		return nil, err
+	}
+	s.lastFetched = time.Now()
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+
+		key, err := parseRSAPublicKey(jwk)
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	s.keys = keys
+
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key id '%v'", ErrInvalidIDToken, kid)
+	}
+
+	return key, nil
+}
+
+func parseRSAPublicKey(jwk jsonWebKey) (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, err
+	}
+
+	eBytes, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, err
+	}
+
+	e := new(big.Int).SetBytes(eBytes)
+	if !e.IsInt64() || e.Int64() < 3 {
+		return nil, errors.New("invalid rsa exponent")
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(e.Int64()),
+	}, nil
+}
+
+func verifyIDToken(ctx context.Context, keys *keySet, rawIDToken, issuer, clientID string) (*Claims, error) {
+	var claims idTokenClaims
+	token, err := jwt.ParseWithClaims(rawIDToken, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return keys.getKey(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Name}),
+		jwt.WithIssuer(issuer),
+		jwt.WithAudience(clientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30*time.Second),
+	)
+	if err != nil {
+		return nil, errors.Join(ErrInvalidIDToken, err)
+	}
+	if !token.Valid || claims.Subject == "" {
+		return nil, ErrInvalidIDToken
+	}
+
+	return &Claims{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.isEmailVerified(),
+		Name:          claims.Name,
+		Picture:       claims.Picture,
+		Nonce:         claims.Nonce,
+	}, nil
+}
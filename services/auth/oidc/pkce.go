@@ -0,0 +1,28 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// returns a url safe random string, used for the state, the nonce and the pkce code verifier.
+func RandomString(sizeInBytes int) (string, error) {
+	buf := make([]byte, sizeInBytes)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// 32 random bytes are encoded to 43 chars which is the minimum length of the verifier (RFC 7636).
+func NewCodeVerifier() (string, error) {
+	return RandomString(32)
+}
+
+func CodeChallengeS256(codeVerifier string) string {
+	hash := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
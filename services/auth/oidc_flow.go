@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gorilla/sessions"
+	"main.go/constants"
+	"main.go/errors"
+	"main.go/services/auth/oidc"
+)
+
+const (
+	oidcFlowCookieName = "oidc_flow"
+	oidcFlowMaxAge     = 600 // 10 minutes
+)
+
+// the values that must survive the redirect to the provider, LinkUserID is set when the flow links a provider to an existing user.
+type OIDCFlow struct {
+	Provider     string
+	State        string
+	Nonce        string
+	CodeVerifier string
+	LinkUserID   uint
+}
+
+// the flow cookie uses the lax mode because the callback is a cross site redirect from the provider.
+func oidcFlowOptions(maxAge int) *sessions.Options {
+	return &sessions.Options{
+		MaxAge:   maxAge,
+		Path:     constants.Prefix + "/auth/oidc",
+		HttpOnly: true,
+		Secure:   false,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// generates the state, nonce and pkce verifier of a new flow and stores them inside a short lived signed cookie.
+func StartOIDCFlow(w http.ResponseWriter, r *http.Request, provider string, linkUserId uint) (*OIDCFlow, error) {
+	state, err := oidc.RandomString(24)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := oidc.RandomString(24)
+	if err != nil {
+		return nil, err
+	}
+	codeVerifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := CookiesStore().New(r, oidcFlowCookieName)
+	if err != nil {
+		return nil, err
+	}
+	session.Options = oidcFlowOptions(oidcFlowMaxAge)
+
+	flow := &OIDCFlow{
+		Provider:     provider,
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		LinkUserID:   linkUserId,
+	}
+	session.Values["provider"] = flow.Provider
+	session.Values["state"] = flow.State
+	session.Values["nonce"] = flow.Nonce
+	session.Values["code_verifier"] = flow.CodeVerifier
+	session.Values["link_user_id"] = flow.LinkUserID
+
+	err = session.Save(r, w)
+	if err != nil {
+		return nil, err
+	}
+
+	return flow, nil
+}
+
+// returns the flow when the state of the callback matches the stored state, the cookie is removed so the flow can not be replayed.
+func ConsumeOIDCFlow(w http.ResponseWriter, r *http.Request, provider, state string) (*OIDCFlow, error) {
+	session, err := CookiesStore().Get(r, oidcFlowCookieName)
+	if err != nil || session.IsNew {
+		return nil, errors.ErrInvalidOIDCState
+	}
+
+	flow := &OIDCFlow{}
+	flow.Provider, _ = session.Values["provider"].(string)
+	flow.State, _ = session.Values["state"].(string)
+	flow.Nonce, _ = session.Values["nonce"].(string)
+	flow.CodeVerifier, _ = session.Values["code_verifier"].(string)
+	flow.LinkUserID, _ = session.Values["link_user_id"].(uint)
+
+	session.Options = oidcFlowOptions(-1)
+	err = session.Save(r, w)
+	if err != nil {
+		return nil, err
+	}
+
+	if flow.State == "" || flow.Provider != provider || subtle.ConstantTimeCompare([]byte(flow.State), []byte(state)) != 1 {
+		return nil, errors.ErrInvalidOIDCState
+	}
+
+	return flow, nil
+}
@@ -16,4 +16,10 @@ func HashPassword(password string) (string, error) {
 func ComparePassword(hashedPassword string, plain []byte) (isEqual bool) {
 	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), plain)
 	return err == nil
-}
\ No newline at end of file
+}
+// the password of the users created through a social login, it can never match because its not a bcrypt hash.
+const NoPasswordMarker = "!oidc"
+
+func HasPassword(hashedPassword string) bool {
+	return hashedPassword != NoPasswordMarker
+}
@@ -42,7 +42,7 @@ func (h *Handler) GetUserCart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cart, err := h.store.GetCartByUserId(*userId)
+	cart, err := h.store.WithContext(r.Context()).GetCartByUserId(*userId)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
@@ -64,7 +64,7 @@ func (h *Handler) AddToCart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cartItem, err := h.store.AddToCart(cartPayload, *userId)
+	cartItem, err := h.store.WithContext(r.Context()).AddToCart(cartPayload, *userId)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
@@ -88,7 +88,7 @@ func (h *Handler) ChangeCartItemQty(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cartItem, err := h.store.GetCartItemById(*cartItemId)
+	cartItem, err := h.store.WithContext(r.Context()).GetCartItemById(*cartItemId)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
@@ -103,7 +103,7 @@ func (h *Handler) ChangeCartItemQty(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	updatedCartItem, err := h.store.ChangeCartItemQty(cartItem.Quantity, payload, cartItem)
+	updatedCartItem, err := h.store.WithContext(r.Context()).ChangeCartItemQty(cartItem.Quantity, payload, cartItem)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
@@ -124,7 +124,7 @@ func (h *Handler) DeleteCartItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	carItem, err := h.store.GetCartItemById(*cartItemId)
+	carItem, err := h.store.WithContext(r.Context()).GetCartItemById(*cartItemId)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
@@ -134,7 +134,7 @@ func (h *Handler) DeleteCartItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.store.DeleteCartItem(*cartItemId)
+	err = h.store.WithContext(r.Context()).DeleteCartItem(*cartItemId)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
@@ -151,7 +151,7 @@ func (h *Handler) ClearCart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	err = h.store.ClearCart(*userIdToken)
+	err = h.store.WithContext(r.Context()).ClearCart(*userIdToken)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
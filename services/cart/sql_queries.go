@@ -7,7 +7,7 @@ var selectQ = ` cart_items.id as id, cart_items.quantity as quantity,
 				images.image_url as product_image`
 
 var joinWProducts = `LEFT JOIN products ON cart_items.product_id = products.id`
-var joinWImages = `LEFT JOIN images ON images.product_id = products.id AND images.is_main = 1`
+var joinWImages = `LEFT JOIN images ON images.product_id = products.id AND images.is_main = true`
 
 func convertRowsToResponse(rows []types.GetCartRow) *types.RespCartShape {
 	var respShape types.RespCartShape
@@ -1,6 +1,7 @@
 package cart
 
 import (
+	"context"
 	"fmt"
 
 	"gorm.io/gorm"
@@ -22,6 +23,10 @@ func NewStore(DB *gorm.DB) *Store {
 	}
 }
 
+func (cartStore *Store) WithContext(ctx context.Context) types.CartStore {
+	return NewStore(cartStore.DB.WithContext(ctx))
+}
+
 var (
 	notFoundMsg = "cart item with id: '%v' was not found"
 )
@@ -22,18 +22,24 @@ func NewHandler(store Store) *Handler {
 }
 
 var Authenticate = middlewares.Authenticate
-var AuthorizeAdmin = middlewares.AuthorizeAdmin
+var AuthorizePermission = middlewares.AuthorizePermission
+var Audit = middlewares.Audit
 var Pagination = middlewares.PaginationMiddleware
 
 func invalidCategoryIdErr(id string) error {
 	return errors.NewInvalidIDError("category", id)
 }
 
+func (h *Handler) audit(action types.AuditAction) func(next http.HandlerFunc) http.HandlerFunc {
+	return Audit(action, middlewares.AuditResource{Type: "categories", IdKey: constants.IdUrlPathKey, BodyKey: "category", Load: h.store.GetAuditSnapshot})
+}
+
 func (h *Handler) RegisterRoutes(router *http.ServeMux) {
 	router.HandleFunc(utils.RoutePath("GET","/categories"), Pagination(h.GetAllCategories))
 	router.HandleFunc(utils.RoutePath("GET","/categories/{id}"), h.GetCategoryById)
-	router.HandleFunc(utils.RoutePath("POST","/categories"), Authenticate(AuthorizeAdmin(h.CreateCategory)))
-	router.HandleFunc(utils.RoutePath("PUT","/categories/{id}"), Authenticate(AuthorizeAdmin(h.UpdateCategory)))
+	router.HandleFunc(utils.RoutePath("POST","/categories"), Authenticate(AuthorizePermission(types.PermCategoriesCreate)(h.audit(types.AuditCreate)(h.CreateCategory))))
+	router.HandleFunc(utils.RoutePath("PUT","/categories/{id}"), Authenticate(AuthorizePermission(types.PermCategoriesUpdate)(h.audit(types.AuditUpdate)(h.UpdateCategory))))
+	router.HandleFunc(utils.RoutePath("PATCH","/categories/{id}/parent"), Authenticate(AuthorizePermission(types.PermCategoriesUpdate)(h.audit(types.AuditUpdate)(h.MoveCategory))))
 }
 
 func (h *Handler) GetCategoryById(w http.ResponseWriter, r *http.Request){
@@ -43,19 +49,25 @@ func (h *Handler) GetCategoryById(w http.ResponseWriter, r *http.Request){
 		return
 	}
 
-	category, err := h.store.GetCategoryById(*Id)
+	category, err := h.store.WithContext(r.Context()).GetCategoryById(*Id)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest , err)
 		return
 	}
 
-	utils.WriteJSON(w, http.StatusOK , map[string]any{"category":category})
+	breadcrumbs, err := h.store.WithContext(r.Context()).GetBreadcrumbs(category)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK , map[string]any{"category":category, "breadcrumbs":breadcrumbs})
 }
 
 func (h *Handler) GetAllCategories(w http.ResponseWriter, r *http.Request) {
 	pagination := middlewares.GetPagination(r)
 
-	categories, count, err := h.store.GetAllCategories(pagination.Page, pagination.Limit)
+	categories, count, err := h.store.WithContext(r.Context()).GetAllCategories(pagination.Page, pagination.Limit)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
@@ -78,7 +90,7 @@ func (h *Handler) CreateCategory(w http.ResponseWriter, r *http.Request) {
 	}
 	model := catePayload.ToModel()
 
-	category, err := h.store.CreateCategory(model)
+	category, err := h.store.WithContext(r.Context()).CreateCategory(model)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
@@ -104,7 +116,32 @@ func (h *Handler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	category, err := h.store.UpdateCategory(*Id, model)
+	category, err := h.store.WithContext(r.Context()).UpdateCategory(*Id, model)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusAccepted, map[string]any{
+		"category": *category,
+	})
+}
+
+// moves the category with its subcategories, their products follow them.
+func (h *Handler) MoveCategory(w http.ResponseWriter, r *http.Request) {
+	movePayload, err := utils.ValidateAndParseBody[payloads.MoveCategory](r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	Id, receivedStr, err := utils.GetValidateId(r, constants.IdUrlPathKey)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, invalidCategoryIdErr(receivedStr))
+		return
+	}
+
+	category, err := h.store.WithContext(r.Context()).MoveCategory(*Id, movePayload.ParentID)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
@@ -9,15 +9,12 @@ import (
 
 	"github.com/brianvoe/gofakeit/v6"
 	"gorm.io/gorm"
-	"main.go/internal/database"
 	"main.go/pkg/models"
 	"main.go/pkg/test_utils"
-	"main.go/services"
 )
 
 func TestCategoriesHandler(t *testing.T) {
-	server := http.NewServeMux()
-	services.SetupAllServices(database.DB, server)
+	server := test_utils.NewServer()
 	categoryIdNotExistent := 9999999
 
 	t.Run("Should get categories and return 200 status code", func(t *testing.T) {
@@ -226,6 +223,44 @@ func TestCategoriesHandler(t *testing.T) {
 		test_utils.ExpectStatusCode(t, rr, http.StatusAccepted)
 	})
 
+	t.Run("Should move a category under another one and refuse a cycle", func(t *testing.T) {
+		t.Parallel()
+		parent, err := test_utils.CreateTestCategory(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		child, err := test_utils.CreateTestCategory(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		move := func(id, parentId uint) *httptest.ResponseRecorder {
+			reqBody := test_utils.CreateRequestBody(t, map[string]any{"parentId": parentId})
+			req, err := http.NewRequest("PATCH", test_utils.GetRoutePath(fmt.Sprintf("/categories/%v/parent", id)), reqBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+			err = test_utils.GenSuperAdminCookie(rr, req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			server.ServeHTTP(rr, req)
+			return rr
+		}
+
+		rr := move(child.ID, parent.ID)
+		test_utils.AssertBodyType(t, rr, func(resp test_utils.CategoryUpdate) bool {
+			return resp.Category.ParentID != nil && *resp.Category.ParentID == parent.ID
+		})
+		test_utils.ExpectStatusCode(t, rr, http.StatusAccepted)
+
+		rr = move(parent.ID, child.ID)
+		test_utils.ExpectStatusCode(t, rr, http.StatusBadRequest)
+	})
+
 	t.Run("Should return an error that category does not exist on update attempt", func(t *testing.T) {
 		t.Parallel()
 		changes := models.Category{Name: test_utils.CapStrLen(gofakeit.Name(), 32)}
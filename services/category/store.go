@@ -1,11 +1,20 @@
 package category
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"main.go/constants"
+	appErrors "main.go/errors"
 	"main.go/pkg/models"
 	"main.go/services/generic"
+	"main.go/types"
 )
 
 type Store struct {
@@ -20,13 +29,36 @@ func NewStore(DB *gorm.DB) *Store {
 	}
 }
 
+func (cateStore *Store) WithContext(ctx context.Context) types.CategoryStore {
+	return NewStore(cateStore.DB.WithContext(ctx))
+}
+
+// the path of a category holds the ids of its ancestors, it is bounded by the size of its (indexed) column, e.g 63
+// levels of 3 digits ids or 42 levels of 5 digits ids.
+const maxPathSize = 255
+
+// serializes the changes of the tree inside the api, sqlite ignores the row locks the concurrent moves rely on.
+var treeMu sync.Mutex
+
 var (
 	notFoundMsg = "category with id: '%v' was not found"
+	parentNotFoundMsg = "parent category with id: '%v' was not found"
+
+	errOwnParent = errors.New("a category can not be its own parent")
+	errMovedUnderDescendant = errors.New("a category can not be moved under one of its subcategories")
+	errTooDeep = fmt.Errorf("the category tree would be too deep, the ids of a category and of its ancestors joined by '/' can not exceed %v characters", maxPathSize)
+	errTreeChanged = errors.New("the category tree was changed by another request, try again")
+	errHasSubcategories = errors.New("the category has subcategories, move or delete them first")
 )
 
+// returns the category with its direct subcategories.
 func (cateStore *Store) GetCategoryById(Id uint) (*models.Category, error) {
-	category, err := cateStore.Generic.GetOne(Id, notFoundMsg)
+	var category models.Category
+	err := cateStore.DB.Preload("Children", func(DB *gorm.DB) *gorm.DB { return DB.Order("name") }).First(&category, Id).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErrors.NewResourceWasNotFoundError(notFoundMsg, Id)
+		}
 		return nil, err
 	}
 
@@ -42,8 +74,30 @@ func (cateStore *Store) GetAllCategories(page, limit int) ([]models.Category, in
 	return categories, count, nil
 }
 
+// the path of the category needs its id, so it is set once the category is created.
 func (cateStore *Store) CreateCategory(category *models.Category) (*models.Category, error) {
-	category, err := cateStore.Generic.Create(category, constants.CategoryCols)
+	treeMu.Lock()
+	defer treeMu.Unlock()
+
+	err := cateStore.DB.Transaction(func(tx *gorm.DB) error {
+		parentPath := ""
+		if category.ParentID != nil {
+			parent, err := lockCategory(tx, *category.ParentID, parentNotFoundMsg)
+			if err != nil {
+				return err
+			}
+			parentPath = parent.Path
+		}
+
+		if err := tx.Select(append([]string{"ParentID"}, constants.CategoryCols...)).Create(category).Error; err != nil {
+			return err
+		}
+		category.Path = categoryPath(parentPath, category.ID)
+		if len(category.Path) > maxPathSize {
+			return errTooDeep
+		}
+		return tx.Model(category).Update("path", category.Path).Error
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -63,4 +117,111 @@ func (cateStore *Store) UpdateCategory(id uint, category *models.Category) (*mod
 	}
 
 	return uCategory, nil
-}
\ No newline at end of file
+}
+
+// moves the category with its subcategories under the parent, or to the roots when parentId is nil. The category, the
+// parent and the ancestors of the parent are locked so two concurrent moves (e.g A under a descendant of B and B under
+// a descendant of A) can not make a cycle.
+func (cateStore *Store) MoveCategory(id uint, parentId *uint) (*models.Category, error) {
+	treeMu.Lock()
+	defer treeMu.Unlock()
+
+	var category *models.Category
+	err := cateStore.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		category, err = lockCategory(tx, id, notFoundMsg)
+		if err != nil {
+			return err
+		}
+
+		parentPath := ""
+		if parentId != nil {
+			if *parentId == id {
+				return errOwnParent
+			}
+			parent, err := lockCategory(tx, *parentId, parentNotFoundMsg)
+			if err != nil {
+				return err
+			}
+			if err := lockAncestors(tx, parent); err != nil {
+				return err
+			}
+			if strings.HasPrefix(parent.Path, category.Path) {
+				return errMovedUnderDescendant
+			}
+			parentPath = parent.Path
+		}
+
+		if err := tx.Model(category).Update("parent_id", parentId).Error; err != nil {
+			return err
+		}
+		category.ParentID = parentId
+		path := categoryPath(parentPath, id)
+		if path == category.Path {
+			return nil
+		}
+		if err := movePaths(tx, category.Path, path); err != nil {
+			return err
+		}
+		category.Path = path
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+// returns the ancestors of the category then the category, from the root down.
+func (cateStore *Store) GetBreadcrumbs(category *models.Category) ([]types.CategoryBreadcrumb, error) {
+	ids := pathIds(category.Path)
+	var ancestors []types.CategoryBreadcrumb
+	err := cateStore.DB.Model(&models.Category{}).Select("id", "name").Where("id IN ?", ids).Scan(&ancestors).Error
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(ancestors, func(a, b types.CategoryBreadcrumb) int {
+		return slices.Index(ids, a.ID) - slices.Index(ids, b.ID)
+	})
+	return ancestors, nil
+}
+
+func (cateStore *Store) GetAuditSnapshot(ctx context.Context, id uint) (any, error) {
+	return cateStore.Generic.GetAuditSnapshot(ctx, id)
+}
+
+// locks the ancestors of the category from the root down then checks they are still its ancestors, a move committed
+// while waiting for the locks changes its path.
+func lockAncestors(tx *gorm.DB, category *models.Category) error {
+	var ancestors []models.Category
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+		Where("id IN ?", pathIds(category.Path)).Order("id").Find(&ancestors).Error
+	if err != nil {
+		return err
+	}
+
+	var path string
+	err = tx.Model(&models.Category{}).Select("path").Where("id = ?", category.ID).Scan(&path).Error
+	if err != nil {
+		return err
+	}
+	if path != category.Path {
+		return errTreeChanged
+	}
+	return nil
+}
+
+func lockCategory(tx *gorm.DB, id uint, notFoundMsg string) (*models.Category, error) {
+	var category models.Category
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&category, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErrors.NewResourceWasNotFoundError(notFoundMsg, id)
+		}
+		return nil, err
+	}
+
+	return &category, nil
+}
@@ -0,0 +1,154 @@
+package category
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"gorm.io/gorm"
+	"main.go/pkg/models"
+	"main.go/pkg/test_utils/testdb"
+	"main.go/pkg/utils"
+	"main.go/types"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	return testdb.SQLite(t, &models.Category{}, &models.Product{}, &models.Image{}, &models.StorageDeletion{})
+}
+
+func createCategory(t *testing.T, store *Store, name string, parentId *uint) *models.Category {
+	t.Helper()
+	category, err := store.CreateCategory(&models.Category{Name: name, ParentID: parentId})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return category
+}
+
+func TestCreateAndMoveCategories(t *testing.T) {
+	store := NewStore(openTestDB(t))
+	clothes := createCategory(t, store, "Clothes", nil)
+	shoes := createCategory(t, store, "Shoes", &clothes.ID)
+	boots := createCategory(t, store, "Boots", &shoes.ID)
+	sports := createCategory(t, store, "Sports", nil)
+
+	if boots.Path != "1/2/3/" {
+		t.Fatalf("expected the path 1/2/3/, got %v", boots.Path)
+	}
+	missingId := uint(99)
+	if _, err := store.CreateCategory(&models.Category{Name: "Hats", ParentID: &missingId}); err == nil {
+		t.Fatal("expected a missing parent to be refused")
+	}
+
+	invalidMoves := map[string]uint{
+		"under itself":         shoes.ID,
+		"under its descendant": boots.ID,
+		"under a missing one":  missingId,
+	}
+	for name, parentId := range invalidMoves {
+		if _, err := store.MoveCategory(shoes.ID, &parentId); err == nil {
+			t.Errorf("expected the move %v to be refused", name)
+		}
+	}
+
+	if _, err := store.MoveCategory(shoes.ID, &sports.ID); err != nil {
+		t.Fatal(err)
+	}
+	boots, err := store.GetCategoryById(boots.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if boots.Path != "4/2/3/" {
+		t.Fatalf("expected the subcategories to move with their parent, got the path %v", boots.Path)
+	}
+	breadcrumbs, err := store.GetBreadcrumbs(boots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(breadcrumbs) != 3 || breadcrumbs[0].Name != "Sports" || breadcrumbs[1].Name != "Shoes" || breadcrumbs[2].Name != "Boots" {
+		t.Fatalf("expected the breadcrumbs Sports, Shoes, Boots, got %+v", breadcrumbs)
+	}
+
+	moved, err := store.MoveCategory(shoes.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved.ParentID != nil || moved.Path != "2/" {
+		t.Fatalf("expected the category to be a root, got %+v", moved)
+	}
+	sports, err = store.GetCategoryById(sports.ID)
+	if err != nil || len(sports.Children) != 0 {
+		t.Fatalf("expected the previous parent to have no subcategories, got %+v %v", sports, err)
+	}
+}
+
+func TestListProductsOfTheDescendants(t *testing.T) {
+	DB := openTestDB(t)
+	store := NewStore(DB)
+	clothes := createCategory(t, store, "Clothes", nil)
+	shoes := createCategory(t, store, "Shoes", &clothes.ID)
+	boots := createCategory(t, store, "Boots", &shoes.ID)
+	createCategory(t, store, "Sports", nil)
+	for i, name := range []string{"Shirt", "Sneaker", "Boot", "Ball"} {
+		DB.Create(&models.Product{Name: name, SKU: name, Slug: name, Quantity: 1, CategoryID: uint(i + 1), Price: 10})
+	}
+
+	categoryIds, err := DescendantIds(DB, shoes.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	products, count, errs := utils.GenericFilter[models.Product](&utils.GenericFilterConfig{
+		DB:                DB,
+		Filters:           []types.FilterCondition{{Field: "categoryId", Operator: "IN", Value: categoryIds}},
+		SortQ:             "id",
+		Pagination:        types.Pagination{Page: 1, Limit: 10},
+		WhiteListedParams: map[string]any{"categoryId": "products.category_id"},
+	})
+	if len(errs) != 0 {
+		t.Fatal(errs[0])
+	}
+	if count != 2 || len(products) != 2 || products[0].Name != "Sneaker" || products[1].Name != "Boot" {
+		t.Fatalf("expected the products of Shoes and Boots, got %v %+v", count, products)
+	}
+
+	if err := DB.Transaction(func(tx *gorm.DB) error { return BeforeHardDelete(tx, shoes.ID) }); err == nil {
+		t.Fatal("expected a category with subcategories not to be deleted")
+	}
+	if err := DB.Transaction(func(tx *gorm.DB) error { return BeforeHardDelete(tx, boots.ID) }); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCategoriesDepthLimit(t *testing.T) {
+	store := NewStore(openTestDB(t))
+	// each level adds 2 characters ("1/", "2/"...) then 3 from the tenth one, the chain stops at the limit.
+	var parentId *uint
+	var deepest *models.Category
+	for {
+		category, err := store.CreateCategory(&models.Category{Name: "Level", ParentID: parentId})
+		if errors.Is(err, errTooDeep) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		deepest = category
+		parentId = &category.ID
+	}
+	if len(deepest.Path) > maxPathSize {
+		t.Fatalf("expected the path to be at most %v characters, got %v", maxPathSize, len(deepest.Path))
+	}
+
+	root := createCategory(t, store, "Root", nil)
+	child := createCategory(t, store, "Child", &root.ID)
+	if _, err := store.MoveCategory(root.ID, &deepest.ID); !errors.Is(err, errTooDeep) {
+		t.Fatalf("expected the move to be refused as too deep, got %v", err)
+	}
+	child, err := store.GetCategoryById(child.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if child.Path != fmt.Sprintf("%v/%v/", root.ID, child.ID) {
+		t.Fatalf("expected the refused move to be rolled back, got the path %v", child.Path)
+	}
+}
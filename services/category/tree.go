@@ -0,0 +1,76 @@
+package category
+
+import (
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	appErrors "main.go/errors"
+	"main.go/pkg/models"
+	"main.go/services/image"
+)
+
+// returns the ids of the category and of its descendants, e.g to list the products of a category with the products
+// of its subcategories.
+func DescendantIds(DB *gorm.DB, id uint) ([]uint, error) {
+	var category models.Category
+	if err := DB.Select("id", "path").First(&category, id).Error; err != nil {
+		return nil, appErrors.NewResourceWasNotFoundError(notFoundMsg, id)
+	}
+
+	var ids []uint
+	err := DB.Model(&models.Category{}).Where("path LIKE ?", category.Path+"%").Pluck("id", &ids).Error
+	return ids, err
+}
+
+// the subcategories are not deleted with their parent, they have to be moved or deleted first. The images of the
+// products of the category are recorded before the cascade deletes them.
+func BeforeHardDelete(tx *gorm.DB, id uint) error {
+	var count int64
+	err := tx.Unscoped().Model(&models.Category{}).Where("parent_id = ?", id).Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count != 0 {
+		return errHasSubcategories
+	}
+
+	return image.EnqueueCategoryImagesDeletions(tx, id)
+}
+
+// rewrites the paths of the moved category and of its descendants (the soft deleted ones too) from the old prefix to
+// the new one.
+func movePaths(tx *gorm.DB, oldPath, newPath string) error {
+	var subtree []models.Category
+	err := tx.Unscoped().Select("id", "path").Where("path LIKE ?", oldPath+"%").Find(&subtree).Error
+	if err != nil {
+		return err
+	}
+
+	for _, category := range subtree {
+		path := newPath + strings.TrimPrefix(category.Path, oldPath)
+		if len(path) > maxPathSize {
+			return errTooDeep
+		}
+		err := tx.Unscoped().Model(&models.Category{}).Where("id = ?", category.ID).Update("path", path).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func categoryPath(parentPath string, id uint) string {
+	return parentPath + strconv.FormatUint(uint64(id), 10) + "/"
+}
+
+func pathIds(path string) []uint {
+	ids := make([]uint, 0)
+	for _, part := range strings.Split(strings.TrimSuffix(path, "/"), "/") {
+		id, err := strconv.ParseUint(part, 10, 64)
+		if err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids
+}
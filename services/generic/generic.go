@@ -1,6 +1,7 @@
 package generic
 
 import (
+	"context"
 	"errors"
 	"sync"
 
@@ -25,6 +26,20 @@ func (g GenericRepository[TModel]) GetOne(id uint, notFoundMsg string) (TModel,
 	return model, nil
 }
 
+// returns the model even when its soft deleted, its used to take the snapshots saved in the audit logs.
+func (g GenericRepository[TModel]) GetAuditSnapshot(ctx context.Context, id uint, preloads ...string) (any, error) {
+	var model TModel
+	query := g.DB.WithContext(ctx).Unscoped()
+	for _, preload := range preloads {
+		query = query.Preload(preload)
+	}
+	if err := query.First(&model, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &model, nil
+}
+
 // this function meant to be used with any model that contains user id inside it, it will search based on both the resource id and user id.
 //
 // * if the model does not contain user id it will throw an error.
@@ -152,6 +167,18 @@ func (g GenericRepository[TModel]) HardDelete(Id uint, notFoundMsg string) error
 	return nil
 }
 
+// hard deletes the item in a transaction with the writes of before.
+func (g GenericRepository[TModel]) HardDeleteTx(Id uint, notFoundMsg string, before func(tx *gorm.DB, id uint) error) error {
+	return g.DB.Transaction(func(tx *gorm.DB) error {
+		err := before(tx, Id)
+		if err != nil {
+			return err
+		}
+
+		return GenericRepository[TModel]{DB: tx}.HardDelete(Id, notFoundMsg)
+	})
+}
+
 // * This store function applies only when soft delete is applied on the route
 func (g *GenericRepository[TModel]) Restore(id uint, notFoundMsg string) (*TModel, error) {
 	var item TModel
@@ -2,12 +2,19 @@ package generic
 
 import (
 	"net/http"
+
+	"gorm.io/gorm"
 )
 
 // Soft Delete routes includes: Get soft deleted, Soft delete by id, Restore soft deleted by id
 type Options struct {
 	SoftDeleteRoutes RouteOptions
 	HardDelete RouteOptions
+	// called with the id of the item after it was soft deleted, restored or hard deleted (e.g to invalidate caches)
+	OnChange func(id uint)
+	// called in the transaction of the hard delete before the item is deleted (e.g to record what the cascade deletes),
+	// an error rolls back the delete
+	BeforeHardDelete func(tx *gorm.DB, id uint) error
 }
 
 type RouteOptions struct {
@@ -40,6 +47,8 @@ func NewRouteOptions(options *RouteOptions) RouteOptions {
 func NewOptions(options *Options) *Options {
 	return &Options{
 		SoftDeleteRoutes: options.SoftDeleteRoutes,
-		HardDelete: options.SoftDeleteRoutes,
+		HardDelete: options.HardDelete,
+		OnChange: options.OnChange,
+		BeforeHardDelete: options.BeforeHardDelete,
 	}
 }
\ No newline at end of file
@@ -1,6 +1,7 @@
 package generic
 
 import (
+	"context"
 	"net/http"
 
 	"gorm.io/gorm"
@@ -8,6 +9,7 @@ import (
 	"main.go/errors"
 	"main.go/middlewares"
 	"main.go/pkg/utils"
+	"main.go/types"
 )
 
 type Store[TModel any] struct {
@@ -22,8 +24,14 @@ func NewStore[TModel any](DB *gorm.DB) *Store[TModel] {
 	}
 }
 
+func (s *Store[TModel]) WithContext(ctx context.Context) *Store[TModel] {
+	return NewStore[TModel](s.DB.WithContext(ctx))
+}
+
 type Handler[TModel any] struct {
-	store Store[TModel]
+	store    Store[TModel]
+	onChange func(id uint)
+	beforeHardDelete func(tx *gorm.DB, id uint) error
 }
 
 func NewHandler[TModel any](store Store[TModel]) *Handler[TModel] {
@@ -44,29 +52,42 @@ func invalidModelIdErr(modelName string,id string) error {
 	return errors.NewInvalidIDError(ModelNameMapper[modelName], id)
 }
 var Pagination = middlewares.PaginationMiddleware
+func (h *Handler[TModel]) auditMW(action types.AuditAction, modelName string) func(next http.HandlerFunc) http.HandlerFunc {
+	return middlewares.Audit(action, middlewares.AuditResource{
+		Type:  modelName,
+		IdKey: constants.IdUrlPathKey,
+		Load:  func(ctx context.Context, id uint) (any, error) { return h.store.Generic.GetAuditSnapshot(ctx, id) },
+	})
+}
+
 func (h *Handler[TModel]) RegisterRoutesGeneric(router *http.ServeMux, modelName string, options Options) {
+	h.onChange = options.OnChange
+	h.beforeHardDelete = options.BeforeHardDelete
+	auditRestore := h.auditMW(types.AuditRestore, modelName)
+	auditSoftDelete := h.auditMW(types.AuditSoftDelete, modelName)
+	auditHardDelete := h.auditMW(types.AuditHardDelete, modelName)
 	
 	if options.SoftDeleteRoutes.IsEnabled {
 		_ = options.SoftDeleteRoutes.AuthenticateMiddleware
-		if options.HardDelete.AuthorizeMiddleware != nil {
-			AuthorizeMW := *options.HardDelete.AuthorizeMiddleware
+		if options.SoftDeleteRoutes.AuthorizeMiddleware != nil {
+			AuthorizeMW := *options.SoftDeleteRoutes.AuthorizeMiddleware
 			router.HandleFunc(utils.RoutePath("GET","/"+modelName+"/deleted"), Pagination(middlewares.Authenticate(AuthorizeMW(h.GenerateGetAllDeleted(modelName)))))
-			router.HandleFunc(utils.RoutePath("PATCH","/"+modelName+"/{id}/restore"), middlewares.Authenticate(AuthorizeMW(h.GenerateRestoreRoute(modelName))))
-			router.HandleFunc(utils.RoutePath("DELETE","/"+modelName+"/{id}/soft-delete"), middlewares.Authenticate(AuthorizeMW(h.GenerateSoftDeleteRoute(modelName))))
+			router.HandleFunc(utils.RoutePath("PATCH","/"+modelName+"/{id}/restore"), middlewares.Authenticate(AuthorizeMW(auditRestore(h.GenerateRestoreRoute(modelName)))))
+			router.HandleFunc(utils.RoutePath("DELETE","/"+modelName+"/{id}/soft-delete"), middlewares.Authenticate(AuthorizeMW(auditSoftDelete(h.GenerateSoftDeleteRoute(modelName)))))
 		} else {
 			router.HandleFunc(utils.RoutePath("GET","/"+modelName+"/deleted"), Pagination(middlewares.Authenticate(h.GenerateGetAllDeleted(modelName))))
-			router.HandleFunc(utils.RoutePath("PATCH","/"+modelName+"/{id}/restore"), middlewares.Authenticate(h.GenerateRestoreRoute(modelName)))
-			router.HandleFunc(utils.RoutePath("DELETE","/"+modelName+"/{id}/soft-delete"), middlewares.Authenticate(h.GenerateSoftDeleteRoute(modelName)))
+			router.HandleFunc(utils.RoutePath("PATCH","/"+modelName+"/{id}/restore"), middlewares.Authenticate(auditRestore(h.GenerateRestoreRoute(modelName))))
+			router.HandleFunc(utils.RoutePath("DELETE","/"+modelName+"/{id}/soft-delete"), middlewares.Authenticate(auditSoftDelete(h.GenerateSoftDeleteRoute(modelName))))
 		}
 	}
 	
 	if options.HardDelete.IsEnabled  {
 		AuthenticateMW := options.SoftDeleteRoutes.AuthenticateMiddleware
 		if options.HardDelete.AuthorizeMiddleware != nil {
-			router.HandleFunc(utils.RoutePath("DELETE","/"+modelName+"/{id}"), AuthenticateMW(h.GenerateHardDeleteRoute(modelName)))
-		} else {
 			AuthorizeMW := *options.HardDelete.AuthorizeMiddleware
-			router.HandleFunc(utils.RoutePath("DELETE","/"+modelName+"/{id}"), AuthenticateMW(AuthorizeMW(h.GenerateHardDeleteRoute(modelName))))
+			router.HandleFunc(utils.RoutePath("DELETE","/"+modelName+"/{id}"), AuthenticateMW(AuthorizeMW(auditHardDelete(h.GenerateHardDeleteRoute(modelName)))))
+		} else {
+			router.HandleFunc(utils.RoutePath("DELETE","/"+modelName+"/{id}"), AuthenticateMW(auditHardDelete(h.GenerateHardDeleteRoute(modelName))))
 		}
 	}
 }
@@ -81,11 +102,12 @@ func (h *Handler[TModel]) GenerateSoftDeleteRoute(modelName string) func(w http.
 
 		notFoundMsg := ModelNameMapper[modelName]+" "+"with id: '%v' was not found"
 
-		err = h.store.Generic.SoftDelete(*Id, notFoundMsg)
+		err = h.store.WithContext(r.Context()).Generic.SoftDelete(*Id, notFoundMsg)
 		if err != nil {
 			utils.WriteError(w, http.StatusBadRequest , err)
 			return
 		}
+		h.notifyChange(*Id)
 	
 		utils.WriteJSON(w, http.StatusNoContent , map[string]any{})
 	}
@@ -101,11 +123,12 @@ func (h *Handler[TModel]) GenerateRestoreRoute(modelName string) func(w http.Res
 
 		notFoundMsg := ModelNameMapper[modelName]+" deleted "+"with id: '%v' was not found"
 
-		item, err := h.store.Generic.Restore(*Id, notFoundMsg)
+		item, err := h.store.WithContext(r.Context()).Generic.Restore(*Id, notFoundMsg)
 		if err != nil {
 			utils.WriteError(w, http.StatusBadRequest , err)
 			return
 		}
+		h.notifyChange(*Id)
 
 		utils.WriteJSON(w, http.StatusOK , map[string]any{
 			"message":"item was restored successfully",
@@ -124,11 +147,16 @@ func (h *Handler[TModel]) GenerateHardDeleteRoute(modelName string) func(w http.
 
 		notFoundMsg := ModelNameMapper[modelName]+" "+"with id: '%v' was not found"
 
-		err = h.store.Generic.HardDelete(*Id, notFoundMsg)
+		if h.beforeHardDelete != nil {
+			err = h.store.WithContext(r.Context()).Generic.HardDeleteTx(*Id, notFoundMsg, h.beforeHardDelete)
+		} else {
+			err = h.store.WithContext(r.Context()).Generic.HardDelete(*Id, notFoundMsg)
+		}
 		if err != nil {
 			utils.WriteError(w, http.StatusBadRequest , err)
 			return
 		}
+		h.notifyChange(*Id)
 
 		utils.WriteJSON(w, http.StatusNoContent , map[string]any{})
 	}
@@ -138,7 +166,7 @@ func (h *Handler[TModel]) GenerateGetAllDeleted(modelName string) func(w http.Re
 	return func(w http.ResponseWriter, r *http.Request) {
 		pagination := middlewares.GetPagination(r)
 
-		models, count, errors := h.store.Generic.GetAllDeleted(pagination.Page, pagination.Limit)
+		models, count, errors := h.store.WithContext(r.Context()).Generic.GetAllDeleted(pagination.Page, pagination.Limit)
 		if len(errors) != 0 {
 			utils.WriteError(w, http.StatusBadRequest, errors[0])
 			return
@@ -151,4 +179,10 @@ func (h *Handler[TModel]) GenerateGetAllDeleted(modelName string) func(w http.Re
 			modelName: models,
 		})
 	}
-}
\ No newline at end of file
+}
+
+func (h *Handler[TModel]) notifyChange(id uint) {
+	if h.onChange != nil {
+		h.onChange(id)
+	}
+}
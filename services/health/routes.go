@@ -0,0 +1,90 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"main.go/internal/database"
+	"main.go/pkg/utils"
+)
+
+const pingTimeout = 2 * time.Second
+
+const (
+	statusOK          = "ok"
+	statusUnavailable = "unavailable"
+)
+
+type Handler struct {
+	DB           *gorm.DB
+	shuttingDown atomic.Bool
+	isMigrated   func() bool
+}
+
+func NewHandler(DB *gorm.DB) *Handler {
+	return &Handler{
+		DB:         DB,
+		isMigrated: database.IsMigrated,
+	}
+}
+
+// the probes are not under the api prefix, they are called by the orchestrator and the load balancer.
+func (h *Handler) RegisterRoutes(router *http.ServeMux) {
+	router.HandleFunc("GET /livez", h.Health)
+	router.HandleFunc("GET /healthz", h.Health)
+	router.HandleFunc("GET /readyz", h.Ready)
+}
+
+// makes the readiness check fail so the load balancer stops sending new requests while the in-flight ones are drained.
+func (h *Handler) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+// the process is alive as long as it can respond.
+func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
+	utils.WriteJSON(w, http.StatusOK, map[string]any{
+		"status": statusOK,
+	})
+}
+
+func (h *Handler) Ready(w http.ResponseWriter, r *http.Request) {
+	checks := map[string]string{
+		"database":   statusOK,
+		"migrations": statusOK,
+		"server":     statusOK,
+	}
+	isReady := true
+
+	ctx, cancel := context.WithTimeout(r.Context(), pingTimeout)
+	defer cancel()
+	if err := database.Ping(ctx, h.DB); err != nil {
+		checks["database"] = err.Error()
+		isReady = false
+	}
+
+	if !h.isMigrated() {
+		checks["migrations"] = "pending"
+		isReady = false
+	}
+
+	if h.shuttingDown.Load() {
+		checks["server"] = "shutting down"
+		isReady = false
+	}
+
+	if !isReady {
+		utils.WriteJSON(w, http.StatusServiceUnavailable, map[string]any{
+			"status": statusUnavailable,
+			"checks": checks,
+		})
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]any{
+		"status": statusOK,
+		"checks": checks,
+	})
+}
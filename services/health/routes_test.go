@@ -0,0 +1,81 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"main.go/pkg/test_utils/testdb"
+)
+
+type probeResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+func probe(t *testing.T, router *http.ServeMux, path string) (int, probeResponse) {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var response probeResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	return recorder.Code, response
+}
+
+func newTestHandler(t *testing.T, isMigrated bool) (*Handler, *http.ServeMux) {
+	t.Helper()
+	router := http.NewServeMux()
+	handler := Setup(testdb.SQLite(t), router)
+	handler.isMigrated = func() bool { return isMigrated }
+	return handler, router
+}
+
+func TestProbes(t *testing.T) {
+	t.Run("Should be alive and ready", func(t *testing.T) {
+		_, router := newTestHandler(t, true)
+
+		for _, path := range []string{"/livez", "/healthz", "/readyz"} {
+			code, response := probe(t, router, path)
+			assert.Equal(t, http.StatusOK, code, path)
+			assert.Equal(t, statusOK, response.Status, path)
+		}
+	})
+
+	t.Run("Should not be ready while the server is shutting down but stay alive", func(t *testing.T) {
+		handler, router := newTestHandler(t, true)
+		handler.SetShuttingDown()
+
+		code, response := probe(t, router, "/readyz")
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, statusUnavailable, response.Status)
+		assert.Equal(t, "shutting down", response.Checks["server"])
+		assert.Equal(t, statusOK, response.Checks["database"])
+
+		code, _ = probe(t, router, "/livez")
+		assert.Equal(t, http.StatusOK, code)
+	})
+
+	t.Run("Should not be ready with pending migrations", func(t *testing.T) {
+		_, router := newTestHandler(t, false)
+
+		code, response := probe(t, router, "/readyz")
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, "pending", response.Checks["migrations"])
+	})
+
+	t.Run("Should not be ready when the database does not respond", func(t *testing.T) {
+		handler, router := newTestHandler(t, true)
+		sqlDB, err := handler.DB.DB()
+		assert.NoError(t, err)
+		assert.NoError(t, sqlDB.Close())
+
+		code, response := probe(t, router, "/readyz")
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.NotEqual(t, statusOK, response.Checks["database"])
+	})
+}
@@ -0,0 +1,13 @@
+package health
+
+import (
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+func Setup(DB *gorm.DB, router *http.ServeMux) *Handler {
+	handler := NewHandler(DB)
+	handler.RegisterRoutes(router)
+	return handler
+}
@@ -0,0 +1,224 @@
+package identity
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	appErrors "main.go/errors"
+	"main.go/middlewares"
+	"main.go/pkg/models"
+	"main.go/pkg/utils"
+	"main.go/services/auth"
+	"main.go/services/auth/oidc"
+	"main.go/types"
+)
+
+type Handler struct {
+	store       types.IdentityStore
+	clients     map[string]*oidc.Client
+	frontendURL *url.URL
+}
+
+func NewHandler(store Store, clients map[string]*oidc.Client, frontendURL *url.URL) *Handler {
+	return &Handler{
+		store:       &store,
+		clients:     clients,
+		frontendURL: frontendURL,
+	}
+}
+
+var Authenticate = middlewares.Authenticate
+var BlockImpersonation = middlewares.BlockImpersonation
+
+func (h *Handler) RegisterRoutes(router *http.ServeMux) {
+	authorizeIdentityOwner := middlewares.AuthorizeUser("id", "identity id is required", notFoundMsg, h.store.GetIdentityById)
+
+	router.HandleFunc(utils.RoutePath("GET", "/auth/oidc/{provider}/login"), h.Login)
+	router.HandleFunc(utils.RoutePath("GET", "/auth/oidc/{provider}/callback"), h.Callback)
+	router.HandleFunc(utils.RoutePath("GET", "/users/me/identities"), Authenticate(h.GetUserIdentities))
+	router.HandleFunc(utils.RoutePath("POST", "/users/me/identities/{provider}/link"), Authenticate(BlockImpersonation(h.LinkIdentity)))
+	router.HandleFunc(utils.RoutePath("DELETE", "/users/me/identities/{id}"), Authenticate(BlockImpersonation(authorizeIdentityOwner(h.UnlinkIdentity))))
+}
+
+func (h *Handler) getClient(r *http.Request) (*oidc.Client, error) {
+	provider := r.PathValue("provider")
+	client, ok := h.clients[provider]
+	if !ok {
+		return nil, fmt.Errorf("provider '%v' is not supported", provider)
+	}
+
+	return client, nil
+}
+
+// redirects the user to the provider.
+func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
+	client, err := h.getClient(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+
+	flow, err := auth.StartOIDCFlow(w, r, client.Name(), 0)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, appErrors.ErrGenericMessage)
+		return
+	}
+
+	authURL, err := client.AuthCodeURL(r.Context(), flow.State, flow.Nonce, flow.CodeVerifier)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// returns the url of the provider, the frontend must navigate to it to link the provider to the current user.
+func (h *Handler) LinkIdentity(w http.ResponseWriter, r *http.Request) {
+	client, err := h.getClient(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+
+	userId, err := utils.GetUserIdCtx(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, appErrors.ErrGenericMessage)
+		return
+	}
+
+	flow, err := auth.StartOIDCFlow(w, r, client.Name(), *userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, appErrors.ErrGenericMessage)
+		return
+	}
+
+	authURL, err := client.AuthCodeURL(r.Context(), flow.State, flow.Nonce, flow.CodeVerifier)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]any{
+		"authorizationUrl": authURL,
+	})
+}
+
+// the provider redirects back here with the code, the flow either links the provider to the user that started it
+// or signs the user in with the same cookies of the password login. The browser is then redirected to the frontend,
+// see redirectToFrontend.
+func (h *Handler) Callback(w http.ResponseWriter, r *http.Request) {
+	client, err := h.getClient(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+
+	query := r.URL.Query()
+	if providerErr := query.Get("error"); providerErr != "" {
+		h.redirectToFrontend(w, r, callbackError, "provider_error")
+		return
+	}
+
+	flow, err := auth.ConsumeOIDCFlow(w, r, client.Name(), query.Get("state"))
+	if err != nil {
+		h.redirectToFrontend(w, r, callbackError, "invalid_state")
+		return
+	}
+
+	claims, err := client.Exchange(r.Context(), query.Get("code"), flow.CodeVerifier, flow.Nonce)
+	if err != nil {
+		if errors.Is(err, oidc.ErrInvalidIDToken) || errors.Is(err, oidc.ErrNonceMismatch) {
+			h.redirectToFrontend(w, r, callbackError, "invalid_id_token")
+			return
+		}
+		h.redirectToFrontend(w, r, callbackError, "provider_unavailable")
+		return
+	}
+
+	if flow.LinkUserID != 0 {
+		_, err := h.store.WithContext(r.Context()).LinkIdentity(flow.LinkUserID, client.Name(), claims)
+		if err != nil {
+			if utils.IsDuplicateKeyErr(err) {
+				h.redirectToFrontend(w, r, callbackError, "already_linked")
+				return
+			}
+			h.redirectToFrontend(w, r, callbackError, "link_failed")
+			return
+		}
+
+		h.redirectToFrontend(w, r, callbackLinked, "")
+		return
+	}
+
+	user, err := h.store.WithContext(r.Context()).FindOrCreateUser(client.Name(), claims)
+	if err != nil {
+		h.redirectToFrontend(w, r, callbackError, "login_failed")
+		return
+	}
+
+	_, _, err = auth.GenerateAndSetTokens(*user, w, r)
+	if err != nil {
+		h.redirectToFrontend(w, r, callbackError, "login_failed")
+		return
+	}
+
+	h.redirectToFrontend(w, r, callbackLogin, "")
+}
+
+const (
+	callbackLogin  = "login"
+	callbackLinked = "linked"
+	callbackError  = "error"
+)
+
+// the callback is a navigation of the browser, so its outcome is given to the frontend in the query of the redirect
+// ("oidc=login", "oidc=linked" or "oidc=error&reason=..."). The signed in frontend gets the user and the otp of the
+// websocket from GET /users, the otp is not put in the url.
+func (h *Handler) redirectToFrontend(w http.ResponseWriter, r *http.Request, outcome string, reason string) {
+	target := *h.frontendURL
+	query := target.Query()
+	query.Set("oidc", outcome)
+	if reason != "" {
+		query.Set("reason", reason)
+	}
+	target.RawQuery = query.Encode()
+
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+func (h *Handler) GetUserIdentities(w http.ResponseWriter, r *http.Request) {
+	userId, err := utils.GetUserIdCtx(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, appErrors.ErrGenericMessage)
+		return
+	}
+
+	identities, err := h.store.WithContext(r.Context()).GetUserIdentities(*userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]any{
+		"identities": identities,
+	})
+}
+
+func (h *Handler) UnlinkIdentity(w http.ResponseWriter, r *http.Request) {
+	identity, err := utils.GetResourceCtx[models.Identity](r, "identity")
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, appErrors.ErrGenericMessage)
+		return
+	}
+
+	err = h.store.WithContext(r.Context()).UnlinkIdentity(identity)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusNoContent, map[string]any{})
+}
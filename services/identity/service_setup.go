@@ -0,0 +1,31 @@
+package identity
+
+import (
+	"net/http"
+
+	"gorm.io/gorm"
+	"main.go/config"
+	"main.go/services/auth/oidc"
+)
+
+func Setup(DB *gorm.DB, router *http.ServeMux) {
+	clients := map[string]*oidc.Client{}
+	for _, provider := range config.GetOIDCProviders() {
+		clients[provider.Name] = oidc.NewClient(oidc.ProviderConfig{
+			Name:         provider.Name,
+			Issuer:       provider.Issuer,
+			ClientID:     provider.ClientID,
+			ClientSecret: provider.ClientSecret,
+			RedirectURL:  provider.RedirectURL,
+		}, nil)
+	}
+
+	frontendURL := config.Envs.OIDC_FRONTEND_URL
+	if frontendURL == nil {
+		frontendURL = config.Envs.PublicHost
+	}
+
+	store := NewStore(DB)
+	handler := NewHandler(*store, clients, frontendURL)
+	handler.RegisterRoutes(router)
+}
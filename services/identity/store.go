@@ -0,0 +1,171 @@
+package identity
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"main.go/pkg/models"
+	"main.go/services/auth"
+	"main.go/services/auth/oidc"
+	"main.go/services/generic"
+	"main.go/types"
+)
+
+var (
+	notFoundMsg = "identity with id: '%v' was not found"
+)
+
+type Store struct {
+	DB      *gorm.DB
+	Generic *generic.GenericRepository[models.Identity]
+}
+
+func NewStore(DB *gorm.DB) *Store {
+	return &Store{
+		DB:      DB,
+		Generic: &generic.GenericRepository[models.Identity]{DB: DB},
+	}
+}
+
+func (identityStore *Store) WithContext(ctx context.Context) types.IdentityStore {
+	return NewStore(identityStore.DB.WithContext(ctx))
+}
+
+func (identityStore *Store) GetIdentityById(id uint) (*models.Identity, error) {
+	identity, err := identityStore.Generic.GetOne(id, notFoundMsg)
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (identityStore *Store) GetUserIdentities(userId uint) ([]models.Identity, error) {
+	var identities []models.Identity
+	err := identityStore.DB.Where("user_id = ?", userId).Order("id").Find(&identities).Error
+	if err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+func newIdentity(userId uint, provider string, claims *oidc.Claims) *models.Identity {
+	identity := &models.Identity{
+		Provider: provider,
+		Subject:  claims.Subject,
+		UserID:   userId,
+	}
+	if claims.Email != "" {
+		identity.Email = &claims.Email
+	}
+
+	return identity
+}
+
+func (identityStore *Store) LinkIdentity(userId uint, provider string, claims *oidc.Claims) (*models.Identity, error) {
+	identity := newIdentity(userId, provider, claims)
+	err := identityStore.DB.Create(identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// returns the user linked to the provider subject, when there is no linked user:
+//   - a user with the same verified email gets the identity linked.
+//   - otherwise a new user without password is created.
+func (identityStore *Store) FindOrCreateUser(provider string, claims *oidc.Claims) (*models.User, error) {
+	var user models.User
+	err := identityStore.DB.Transaction(func(tx *gorm.DB) error {
+		var identity models.Identity
+		err := tx.Where("provider = ? AND subject = ?", provider, claims.Subject).First(&identity).Error
+		if err == nil {
+			return tx.First(&user, identity.UserID).Error
+		}
+		if err != gorm.ErrRecordNotFound {
+			return err
+		}
+
+		if claims.Email == "" {
+			return fmt.Errorf("the provider did not share the email of the account")
+		}
+		email := strings.ToLower(claims.Email)
+
+		err = tx.Where("email = ?", email).First(&user).Error
+		if err == nil {
+			// linking an unverified email would let anyone take over the account that owns it
+			if !claims.EmailVerified {
+				return fmt.Errorf("an account with this email already exists, sign in with your password and link the provider from your profile")
+			}
+			return tx.Create(newIdentity(user.ID, provider, claims)).Error
+		}
+		if err != gorm.ErrRecordNotFound {
+			return err
+		}
+
+		user = models.User{
+			Name:     userName(claims),
+			Email:    email,
+			Password: auth.NoPasswordMarker,
+		}
+		if claims.Picture != "" {
+			user.Avatar = &claims.Picture
+		}
+		err = tx.Create(&user).Error
+		if err != nil {
+			return err
+		}
+
+		var regularUser models.Role
+		err = tx.Where("name = ?", string(types.RegularUser)).First(&regularUser).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Create(&models.UserRoles{UserID: user.ID, RoleID: regularUser.ID}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(newIdentity(user.ID, provider, claims)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// the user must keep at least one way to sign in, so the last identity of a user without password can not be unlinked.
+func (identityStore *Store) UnlinkIdentity(identity *models.Identity) error {
+	var user models.User
+	err := identityStore.DB.First(&user, identity.UserID).Error
+	if err != nil {
+		return err
+	}
+
+	if !auth.HasPassword(user.Password) {
+		var count int64
+		err = identityStore.DB.Model(&models.Identity{}).Where("user_id = ?", user.ID).Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count <= 1 {
+			return fmt.Errorf("can not unlink the only sign in method of the account")
+		}
+	}
+
+	return identityStore.DB.Delete(identity).Error
+}
+
+func userName(claims *oidc.Claims) string {
+	name := strings.TrimSpace(claims.Name)
+	if name == "" {
+		name, _, _ = strings.Cut(claims.Email, "@")
+	}
+	if len(name) > 32 {
+		name = name[:32]
+	}
+
+	return name
+}
@@ -0,0 +1,197 @@
+package image
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"main.go/pkg/models"
+	"main.go/pkg/storage"
+	"main.go/types"
+)
+
+const (
+	deletionsBatchSize   = 100
+	deletionErrorMaxSize = 512
+)
+
+// records the files to delete from the storage, tx is the transaction removing their rows so the files of a rolled
+// back delete are kept. The variants are deleted with their image.
+func EnqueueDeletions(tx *gorm.DB, publicIds []string) error {
+	if len(publicIds) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	deletions := make([]models.StorageDeletion, 0, len(publicIds))
+	for _, publicId := range publicIds {
+		deletions = append(deletions, models.StorageDeletion{PublicId: publicId, NextAttemptAt: now})
+	}
+	return tx.CreateInBatches(deletions, deletionsBatchSize).Error
+}
+
+// the images rows of the product are deleted by the cascade of its hard delete, their files are recorded before.
+func EnqueueProductImagesDeletions(tx *gorm.DB, productId uint) error {
+	return enqueueImagesDeletions(tx, "product_id = ?", productId)
+}
+
+// the products of the category (the soft deleted ones too) and their images are deleted by the cascade.
+func EnqueueCategoryImagesDeletions(tx *gorm.DB, categoryId uint) error {
+	products := tx.Unscoped().Model(&models.Product{}).Select("id").Where("category_id = ?", categoryId)
+	return enqueueImagesDeletions(tx, "product_id IN (?)", products)
+}
+
+func enqueueImagesDeletions(tx *gorm.DB, query string, args ...any) error {
+	var publicIds []string
+	err := tx.Unscoped().Model(&models.Image{}).Where(query, args...).Pluck("image_public_id", &publicIds).Error
+	if err != nil {
+		return err
+	}
+
+	return EnqueueDeletions(tx, publicIds)
+}
+
+// for the files uploaded by a request that failed before their rows were created, the error is only logged since
+// the request already failed (the reconcile command finds the files that were not recorded).
+func DiscardUploads(DB *gorm.DB, responses []*types.UploadResponse) {
+	publicIds := make([]string, 0, len(responses))
+	for _, response := range responses {
+		publicIds = append(publicIds, response.PublicID)
+	}
+
+	err := EnqueueDeletions(DB, publicIds)
+	if err != nil {
+		slog.Error("failed to record the uploaded images to delete", "publicIds", publicIds, "error", err)
+	}
+}
+
+// moves the files of the uploads that were not confirmed in time to the deletions, a row that a confirmation
+// removed meanwhile is skipped so the file of its image is kept.
+func ExpirePendingUploads(DB *gorm.DB, now time.Time) (int, error) {
+	var expired []models.PendingUpload
+	err := DB.Where("confirm_before <= ?", now).Order("id").Limit(deletionsBatchSize).Find(&expired).Error
+	if err != nil || len(expired) == 0 {
+		return 0, err
+	}
+
+	publicIds := make([]string, 0, len(expired))
+	err = DB.Transaction(func(tx *gorm.DB) error {
+		for _, pendingUpload := range expired {
+			result := tx.Where("confirm_before <= ?", now).Delete(&pendingUpload)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 1 {
+				publicIds = append(publicIds, pendingUpload.PublicId)
+			}
+		}
+
+		return EnqueueDeletions(tx, publicIds)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(publicIds), nil
+}
+
+// lists the files of the folder that are neither an image (or one of its variants), a pending upload nor waiting to be
+// deleted, the files written after writtenBefore are skipped since the rows of their request may not be committed yet.
+func FindOrphans(ctx context.Context, DB *gorm.DB, images storage.ImageStorage, folder types.Folder, writtenBefore time.Time) ([]storage.StoredImage, error) {
+	var publicIds, pendingIds []string
+	err := DB.Unscoped().Model(&models.Image{}).Pluck("image_public_id", &publicIds).Error
+	if err != nil {
+		return nil, err
+	}
+	err = DB.Model(&models.StorageDeletion{}).Pluck("public_id", &pendingIds).Error
+	if err != nil {
+		return nil, err
+	}
+	var uploadIds []string
+	err = DB.Model(&models.PendingUpload{}).Pluck("public_id", &uploadIds).Error
+	if err != nil {
+		return nil, err
+	}
+	pendingIds = append(pendingIds, uploadIds...)
+
+	known := make(map[string]bool, len(publicIds)*4+len(pendingIds))
+	for _, publicId := range publicIds {
+		known[publicId] = true
+		for _, variantId := range storage.VariantPublicIds(publicId) {
+			known[variantId] = true
+		}
+	}
+	for _, publicId := range pendingIds {
+		known[publicId] = true
+	}
+
+	stored, err := images.List(ctx, folder)
+	if err != nil {
+		return nil, err
+	}
+	orphans := make([]storage.StoredImage, 0)
+	for _, file := range stored {
+		if !known[file.PublicId] && file.UpdatedAt.Before(writtenBefore) {
+			orphans = append(orphans, file)
+		}
+	}
+
+	return orphans, nil
+}
+
+// deletes the files of the due deletions and returns how many were deleted, the failed ones are retried later.
+// The replicas may delete the same files twice, the storages ignore the missing files.
+func ProcessDeletions(ctx context.Context, DB *gorm.DB, images storage.ImageStorage, now time.Time) (int, error) {
+	var deletions []models.StorageDeletion
+	err := DB.Where("next_attempt_at <= ?", now).Order("id").Limit(deletionsBatchSize).Find(&deletions).Error
+	if err != nil || len(deletions) == 0 {
+		return 0, err
+	}
+
+	publicIds := make([]string, 0, len(deletions))
+	for _, deletion := range deletions {
+		publicIds = append(publicIds, deletion.PublicId)
+	}
+	if err := images.DeleteMany(ctx, publicIds); err == nil {
+		return len(deletions), DB.Delete(&deletions).Error
+	}
+
+	// the batch fails with any of its files, they are deleted one by one so a file failing every time does not keep
+	// the others
+	deleted := 0
+	for _, deletion := range deletions {
+		deleteErr := images.Delete(ctx, deletion.PublicId)
+		if deleteErr != nil {
+			slog.Warn("failed to delete the image from the storage, it is retried later", "publicId", deletion.PublicId, "attempts", deletion.Attempts+1, "error", deleteErr)
+			err = retryLater(DB, deletion, deleteErr, now)
+		} else {
+			err = DB.Delete(&deletion).Error
+			deleted++
+		}
+		if err != nil {
+			return deleted, err
+		}
+	}
+
+	return deleted, nil
+}
+
+func retryLater(DB *gorm.DB, deletion models.StorageDeletion, deleteErr error, now time.Time) error {
+	attempts := deletion.Attempts + 1
+	message := deleteErr.Error()
+	if len(message) > deletionErrorMaxSize {
+		message = message[:deletionErrorMaxSize]
+	}
+
+	return DB.Model(&deletion).Updates(map[string]any{
+		"attempts":        attempts,
+		"last_error":      message,
+		"next_attempt_at": now.Add(retryDelay(attempts)),
+	}).Error
+}
+
+// doubles from a minute up to an hour.
+func retryDelay(attempts int) time.Duration {
+	return min(time.Minute<<min(attempts-1, 6), time.Hour)
+}
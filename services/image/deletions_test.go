@@ -0,0 +1,174 @@
+package image
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"main.go/pkg/models"
+	"main.go/pkg/storage"
+	"main.go/pkg/test_utils/testdb"
+	"main.go/types"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	DB := testdb.SQLite(t, &models.Image{}, &models.StorageDeletion{}, &models.PendingUpload{})
+	// only the columns read by the deletions
+	if err := DB.Exec("CREATE TABLE products (id integer PRIMARY KEY, category_id integer, deleted_at datetime)").Error; err != nil {
+		t.Fatal(err)
+	}
+	return DB
+}
+
+func newLocalStorage(t *testing.T) *storage.LocalStorage {
+	t.Helper()
+	local, err := storage.NewLocalStorage(t.TempDir(), "http://localhost:8080"+storage.LocalRoute, []byte("signing-key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return local
+}
+
+func pngContent(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func upload(t *testing.T, local *storage.LocalStorage) string {
+	t.Helper()
+	resp, err := local.Upload(context.Background(), bytes.NewReader(pngContent(t)), "a.png", types.ProductsFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.PublicID
+}
+
+func storedIds(t *testing.T, local *storage.LocalStorage) map[string]bool {
+	t.Helper()
+	stored, err := local.List(context.Background(), types.ProductsFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make(map[string]bool, len(stored))
+	for _, file := range stored {
+		ids[file.PublicId] = true
+	}
+	return ids
+}
+
+func TestProcessDeletionsRetriesTheFailedFilesLater(t *testing.T) {
+	DB := openTestDB(t)
+	local := newLocalStorage(t)
+	kept, deleted := upload(t, local), upload(t, local)
+	now := time.Now()
+
+	// the invalid public id fails the batch, the other file is still deleted
+	if err := EnqueueDeletions(DB, []string{deleted, "../outside.png"}); err != nil {
+		t.Fatal(err)
+	}
+	count, err := ProcessDeletions(context.Background(), DB, local, now.Add(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 deleted file, got %v", count)
+	}
+	if ids := storedIds(t, local); ids[deleted] || !ids[kept] {
+		t.Fatalf("expected only the enqueued file to be deleted, got %v", ids)
+	}
+
+	var pending []models.StorageDeletion
+	if err := DB.Find(&pending).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].Attempts != 1 || pending[0].LastError == nil || !pending[0].NextAttemptAt.After(now.Add(time.Minute-time.Second)) {
+		t.Fatalf("expected the failed file to be retried later, got %+v", pending)
+	}
+
+	count, err = ProcessDeletions(context.Background(), DB, local, now.Add(2*time.Second))
+	if err != nil || count != 0 {
+		t.Fatalf("expected the failed file not to be retried before its delay, got %v %v", count, err)
+	}
+}
+
+func TestEnqueueCategoryImagesDeletions(t *testing.T) {
+	DB := openTestDB(t)
+	DB.Exec("INSERT INTO products (id, category_id, deleted_at) VALUES (1, 1, NULL), (2, 1, CURRENT_TIMESTAMP), (3, 2, NULL)")
+	isMain := true
+	for productId, publicId := range map[uint]string{1: "first", 2: "soft-deleted", 3: "other-category"} {
+		if err := DB.Create(&models.Image{ProductID: productId, ImageUrl: publicId, ImagePublicId: publicId, IsMain: &isMain}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := EnqueueCategoryImagesDeletions(DB, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	var publicIds []string
+	DB.Model(&models.StorageDeletion{}).Order("public_id").Pluck("public_id", &publicIds)
+	if len(publicIds) != 2 || publicIds[0] != "first" || publicIds[1] != "soft-deleted" {
+		t.Fatalf("expected the images of the products of the category, got %v", publicIds)
+	}
+}
+
+func TestFindOrphans(t *testing.T) {
+	DB := openTestDB(t)
+	local := newLocalStorage(t)
+	recorded, pending, orphan := upload(t, local), upload(t, local), upload(t, local)
+	variant := storage.VariantPublicIds(recorded)[0]
+	if _, err := local.Replace(context.Background(), variant, bytes.NewReader(pngContent(t)), "a.png"); err != nil {
+		t.Fatal(err)
+	}
+
+	isMain := true
+	DB.Create(&models.Image{ProductID: 1, ImageUrl: recorded, ImagePublicId: recorded, IsMain: &isMain})
+	EnqueueDeletions(DB, []string{pending})
+
+	orphans, err := FindOrphans(context.Background(), DB, local, types.ProductsFolder, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orphans) != 1 || orphans[0].PublicId != orphan {
+		t.Fatalf("expected only '%v' to be an orphan, got %+v", orphan, orphans)
+	}
+
+	orphans, err = FindOrphans(context.Background(), DB, local, types.ProductsFolder, time.Now().Add(-time.Hour))
+	if err != nil || len(orphans) != 0 {
+		t.Fatalf("expected the recent files to be skipped, got %+v %v", orphans, err)
+	}
+}
+
+func TestExpirePendingUploads(t *testing.T) {
+	DB := openTestDB(t)
+	store := NewStore(DB, nil)
+	now := time.Now()
+	uploads := []*types.SignedUpload{{PublicId: "expired", ExpiresAt: now.Add(-2 * time.Hour)}, {PublicId: "pending", ExpiresAt: now}}
+	if err := store.CreatePendingUploads(1, uploads, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	expired, err := ExpirePendingUploads(DB, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expired != 1 {
+		t.Fatalf("expected 1 expired upload, got %v", expired)
+	}
+
+	var deletions, pending []string
+	DB.Model(&models.StorageDeletion{}).Pluck("public_id", &deletions)
+	DB.Model(&models.PendingUpload{}).Pluck("public_id", &pending)
+	if len(deletions) != 1 || deletions[0] != "expired" || len(pending) != 1 || pending[0] != "pending" {
+		t.Fatalf("expected only the expired upload to be deleted, got the deletions %v and the pending uploads %v", deletions, pending)
+	}
+}
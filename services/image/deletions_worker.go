@@ -0,0 +1,45 @@
+package image
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"main.go/pkg/storage"
+)
+
+// runs ExpirePendingUploads then ProcessDeletions every interval until the context is canceled, wg is done once the
+// running pass returned.
+func StartDeletionsWorker(ctx context.Context, wg *sync.WaitGroup, DB *gorm.DB, images storage.ImageStorage, interval time.Duration) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				expired, err := ExpirePendingUploads(DB, now)
+				if err != nil {
+					slog.Error("failed to expire the pending uploads", "error", err)
+				} else if expired > 0 {
+					slog.Info("expired the pending uploads", "count", expired)
+				}
+
+				deleted, err := ProcessDeletions(ctx, DB, images, now)
+				if err != nil {
+					slog.Error("failed to delete the images from the storage", "error", err)
+					continue
+				}
+				if deleted > 0 {
+					slog.Info("deleted the images from the storage", "count", deleted)
+				}
+			}
+		}
+	}()
+}
@@ -1,40 +1,68 @@
 package image
 
 import (
-	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	appErrors "main.go/errors"
 	"main.go/constants"
 	"main.go/middlewares"
+	"main.go/pkg/imaging"
+	"main.go/pkg/payloads"
+	"main.go/pkg/storage"
 	"main.go/pkg/utils"
 	"main.go/types"
 )
 
 type Handler struct {
-	store types.ImageStore
+	store  types.ImageStore
+	images storage.ImageStorage
 }
 
 func NewHandler(store Store) *Handler {
 	return &Handler{
-		store: &store,
+		store:  &store,
+		images: store.Images,
 	}
 }
 const (
 	maxImagesCapacity = 10
 	maxSizeInMBForMultipleUploads int64 = 10
 	maxSizeInMBForOneUpload int64 = 2
+	// the max size of each file uploaded straight to the storage
+	maxSizeInMBForDirectUpload int64 = 10
+	directUploadExpiration = 15 * time.Minute
+	// the time left to confirm an upload after its signature expired
+	directUploadConfirmWindow = time.Hour
 )
 
 var Authenticate = middlewares.Authenticate
-var AuthorizeAdmin = middlewares.AuthorizeAdmin
+var AuthorizePermission = middlewares.AuthorizePermission
+var Audit = middlewares.Audit
+
+func (h *Handler) audit(action types.AuditAction) func(next http.HandlerFunc) http.HandlerFunc {
+	return Audit(action, middlewares.AuditResource{Type: "images", IdKey: "imageId", Load: h.store.GetAuditSnapshot})
+}
+
+func (h *Handler) auditProductImages(action types.AuditAction) func(next http.HandlerFunc) http.HandlerFunc {
+	return Audit(action, middlewares.AuditResource{Type: "product-images", IdKey: constants.IdUrlPathKey, Load: h.store.GetProductImagesAuditSnapshot})
+}
+
+func (h *Handler) auditPendingUploads(action types.AuditAction) func(next http.HandlerFunc) http.HandlerFunc {
+	return Audit(action, middlewares.AuditResource{Type: "pending-uploads", IdKey: constants.IdUrlPathKey, Load: h.store.GetPendingUploadsAuditSnapshot})
+}
 
 func (h *Handler) RegisterRoutes(router *http.ServeMux) {
-	router.HandleFunc(utils.RoutePath("DELETE", "/products/{id}/images/{imageId}"), Authenticate(AuthorizeAdmin(h.DeleteProductImageById)))
-	router.HandleFunc(utils.RoutePath("PUT", "/products/{id}/images/{imageId}"), Authenticate(AuthorizeAdmin(h.UpdateImageById)))
-	router.HandleFunc(utils.RoutePath("PATCH", "/products/{id}/images/{imageId}"), Authenticate(AuthorizeAdmin(h.SetImageProductAsMain)))
-	router.HandleFunc(utils.RoutePath("POST", "/products/{id}/images"), Authenticate(AuthorizeAdmin(h.CreateImagesForProduct)))
+	router.HandleFunc(utils.RoutePath("DELETE", "/products/{id}/images/{imageId}"), Authenticate(AuthorizePermission(types.PermImagesDelete)(h.audit(types.AuditDelete)(h.DeleteProductImageById))))
+	router.HandleFunc(utils.RoutePath("PUT", "/products/{id}/images/{imageId}"), Authenticate(AuthorizePermission(types.PermImagesUpdate)(h.audit(types.AuditUpdate)(h.UpdateImageById))))
+	router.HandleFunc(utils.RoutePath("PATCH", "/products/{id}/images/{imageId}"), Authenticate(AuthorizePermission(types.PermImagesUpdate)(h.auditProductImages(types.AuditSetMainImage)(h.SetImageProductAsMain))))
+	router.HandleFunc(utils.RoutePath("PATCH", "/products/{id}/images/order"), Authenticate(AuthorizePermission(types.PermImagesUpdate)(h.auditProductImages(types.AuditReorderImages)(h.ReorderProductImages))))
+	router.HandleFunc(utils.RoutePath("PATCH", "/products/{id}/images/{imageId}/alt-text"), Authenticate(AuthorizePermission(types.PermImagesUpdate)(h.audit(types.AuditUpdate)(h.UpdateImageAltText))))
+	router.HandleFunc(utils.RoutePath("POST", "/products/{id}/images"), Authenticate(AuthorizePermission(types.PermImagesCreate)(h.auditProductImages(types.AuditCreate)(h.CreateImagesForProduct))))
+	router.HandleFunc(utils.RoutePath("POST", "/products/{id}/images/upload-urls"), Authenticate(AuthorizePermission(types.PermImagesCreate)(h.auditPendingUploads(types.AuditCreateUploadURLs)(h.CreateUploadURLs))))
+	router.HandleFunc(utils.RoutePath("POST", "/products/{id}/images/confirm"), Authenticate(AuthorizePermission(types.PermImagesCreate)(h.auditProductImages(types.AuditCreate)(h.ConfirmUploads))))
 }
 
 func (h *Handler) DeleteProductImageById(w http.ResponseWriter, r *http.Request) {
@@ -44,7 +72,7 @@ func (h *Handler) DeleteProductImageById(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err = h.store.DeleteImageById(*imageId)
+	err = h.store.WithContext(r.Context()).DeleteImageById(*imageId)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
@@ -60,7 +88,7 @@ func (h *Handler) SetImageProductAsMain(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	image, err := h.store.GetImageById(*imageId)
+	image, err := h.store.WithContext(r.Context()).GetImageById(*imageId)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
@@ -71,7 +99,7 @@ func (h *Handler) SetImageProductAsMain(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err = h.store.SwapMainStatus(*imageId, image.ProductID)
+	err = h.store.WithContext(r.Context()).SwapMainStatus(*imageId, image.ProductID)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("an unexpected error has occurred during transaction"))
 		return
@@ -87,13 +115,13 @@ func (h *Handler) CreateImagesForProduct(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	_, err = h.store.GetProductById(*productId)
+	_, err = h.store.WithContext(r.Context()).GetProductById(*productId)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("product with id: '%v' was not found", *productId))
 		return
 	}
 
-	count ,err := h.store.GetCountOfProductImages(*productId)
+	count ,err := h.store.WithContext(r.Context()).GetCountOfProductImages(*productId)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
@@ -116,14 +144,18 @@ func (h *Handler) CreateImagesForProduct(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	imgHandler := utils.NewImagesHandler()
-	responses, errs := imgHandler.UploadMany(r, types.ProductsFolder, files, context.Background())
+	responses, errs := storage.UploadMany(r.Context(), h.images, types.ProductsFolder, files)
 	if len(errs) != 0 {
-		utils.WriteError(w, http.StatusInternalServerError, errs[0])
+		// the images that were uploaded are not kept without their rows
+		h.store.WithContext(r.Context()).DiscardUploads(responses)
+		utils.WriteError(w, uploadErrorStatus(errs[0]), errs[0])
 		return
 	}
-	newImages, err := h.store.CreateManyImages(responses, productId)
+	// optional, one per file in the order of the files
+	altTexts := r.MultipartForm.Value["altTexts"]
+	newImages, err := h.store.WithContext(r.Context()).CreateManyImages(responses, altTexts, productId)
 	if err != nil {
+		h.store.WithContext(r.Context()).DiscardUploads(responses)
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
@@ -141,7 +173,7 @@ func (h *Handler) UpdateImageById(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	image, err := h.store.GetImageById(*imageId)
+	image, err := h.store.WithContext(r.Context()).GetImageById(*imageId)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("image with id: '%v' was not found", imageId))
 		return
@@ -152,21 +184,212 @@ func (h *Handler) UpdateImageById(w http.ResponseWriter, r *http.Request) {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
 	}
-	imgHandler := utils.NewImagesHandler()
-	upResult, err := imgHandler.UploadOne(&file ,fileHeader, types.ProductsFolder, context.Background())
+	defer file.Close()
+	// the image keeps its public id so the replaced file does not stay in the storage
+	upResult, err := h.images.Replace(r.Context(), image.ImagePublicId, file, fileHeader.Filename)
 	if err != nil {
-		utils.WriteError(w, http.StatusInternalServerError, err)
+		utils.WriteError(w, uploadErrorStatus(err), err)
 		return
 	}
 
-	err = h.store.UpdateImageUrl(*imageId, upResult.URL)
+	err = h.store.WithContext(r.Context()).UpdateImageFile(*imageId, upResult)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
 
 	image.ImageUrl = upResult.URL
+	image.Variants = upResult.Variants
 	utils.WriteJSON(w, http.StatusAccepted, map[string]any{
 		"image":image,
 	})
-}
\ No newline at end of file
+}
+
+func (h *Handler) ReorderProductImages(w http.ResponseWriter, r *http.Request) {
+	productId, receivedStr, err := utils.GetValidateId(r, constants.IdUrlPathKey)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, appErrors.NewInvalidIDError("product", receivedStr))
+		return
+	}
+
+	payload, err := utils.ValidateAndParseBody[payloads.ReorderImages](r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.store.WithContext(r.Context()).ReorderImages(*productId, payload.ImageIds)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusNoContent, map[string]any{})
+}
+
+func (h *Handler) UpdateImageAltText(w http.ResponseWriter, r *http.Request) {
+	productId, receivedStr, err := utils.GetValidateId(r, constants.IdUrlPathKey)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, appErrors.NewInvalidIDError("product", receivedStr))
+		return
+	}
+	imageId, receivedStr, err := utils.GetValidateId(r, "imageId")
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, appErrors.NewInvalidIDError("image", receivedStr))
+		return
+	}
+
+	payload, err := utils.ValidateAndParseBody[payloads.UpdateImageAltText](r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	image, err := h.store.WithContext(r.Context()).UpdateImageAltText(*imageId, *productId, payload.TrimStrs().AltText)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusAccepted, map[string]any{
+		"image": image,
+	})
+}
+
+// signs the uploads of the files straight to the storage, the files are not images of the product until their uploads
+// are confirmed.
+func (h *Handler) CreateUploadURLs(w http.ResponseWriter, r *http.Request) {
+	productId, receivedStr, err := utils.GetValidateId(r, constants.IdUrlPathKey)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, appErrors.NewInvalidIDError("product", receivedStr))
+		return
+	}
+
+	_, err = h.store.WithContext(r.Context()).GetProductById(*productId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("product with id: '%v' was not found", *productId))
+		return
+	}
+
+	payload, err := utils.ValidateAndParseBody[payloads.CreateUploadURLs](r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	count, err := h.store.WithContext(r.Context()).GetCountOfProductImages(*productId)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	pendingCount, err := h.store.WithContext(r.Context()).GetCountOfPendingUploads(*productId, time.Now())
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if int(*count+pendingCount)+len(payload.FileNames) > maxImagesCapacity {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("you can not upload more than 10 images for a single product, you have '%v' and '%v' pending uploads and you trying to upload more '%v'", *count, pendingCount, len(payload.FileNames)))
+		return
+	}
+
+	uploads := make([]*types.SignedUpload, 0, len(payload.FileNames))
+	for _, fileName := range payload.FileNames {
+		upload, err := h.images.SignUpload(r.Context(), types.ProductsFolder, fileName, maxSizeInMBForDirectUpload<<20, directUploadExpiration)
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		uploads = append(uploads, upload)
+	}
+
+	err = h.store.WithContext(r.Context()).CreatePendingUploads(*productId, uploads, directUploadConfirmWindow)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, map[string]any{
+		"uploads": uploads,
+		"maxSizeInMB": maxSizeInMBForDirectUpload,
+	})
+}
+
+// processes the files uploaded with the signed uploads and creates their images, a file that is not a valid image is
+// deleted and its upload can not be confirmed again.
+func (h *Handler) ConfirmUploads(w http.ResponseWriter, r *http.Request) {
+	productId, receivedStr, err := utils.GetValidateId(r, constants.IdUrlPathKey)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, appErrors.NewInvalidIDError("product", receivedStr))
+		return
+	}
+
+	payload, err := utils.ValidateAndParseBody[payloads.ConfirmUploads](r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	publicIds := make([]string, 0, len(payload.Uploads))
+	altTexts := make([]string, 0, len(payload.Uploads))
+	for _, upload := range payload.Uploads {
+		publicIds = append(publicIds, upload.PublicId)
+		altTexts = append(altTexts, upload.AltText)
+	}
+	pendingUploads, err := h.store.WithContext(r.Context()).GetPendingUploads(*productId, publicIds, time.Now())
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if len(pendingUploads) != len(publicIds) {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("the uploads must be signed for the product with id: '%v' and not expired", *productId))
+		return
+	}
+
+	count, err := h.store.WithContext(r.Context()).GetCountOfProductImages(*productId)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if int(*count)+len(publicIds) > maxImagesCapacity {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("you can not upload more than 10 images for a single product, you have '%v' and you trying to confirm more '%v'", *count, len(publicIds)))
+		return
+	}
+
+	responses := make([]*types.UploadResponse, 0, len(publicIds))
+	for _, publicId := range publicIds {
+		response, err := storage.ConfirmUpload(r.Context(), h.images, publicId, maxSizeInMBForDirectUpload<<20)
+		if errors.Is(err, imaging.ErrInvalidImage) {
+			if discardErr := h.store.WithContext(r.Context()).DiscardPendingUpload(publicId); discardErr != nil {
+				utils.WriteError(w, http.StatusInternalServerError, discardErr)
+				return
+			}
+		}
+		if errors.Is(err, storage.ErrNotFound) {
+			utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("the file of the upload '%v' was not uploaded", publicId))
+			return
+		}
+		if err != nil {
+			utils.WriteError(w, uploadErrorStatus(err), fmt.Errorf("failed to confirm the upload '%v': %w", publicId, err))
+			return
+		}
+		responses = append(responses, response)
+	}
+
+	newImages, err := h.store.WithContext(r.Context()).ConfirmUploads(*productId, responses, altTexts)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, map[string]any{
+		"images": newImages,
+	})
+}
+
+// the images refused by the processing are the client's fault, the other errors come from the storage.
+func uploadErrorStatus(err error) int {
+	if errors.Is(err, imaging.ErrInvalidImage) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
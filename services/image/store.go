@@ -3,12 +3,15 @@ package image
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	"gorm.io/gorm"
 	"main.go/constants"
 	"main.go/pkg/models"
-	"main.go/pkg/utils"
+	"main.go/pkg/storage"
 	"main.go/services/generic"
 	"main.go/types"
 )
@@ -16,15 +19,22 @@ import (
 type Store struct {
 	DB      *gorm.DB
 	Generic *generic.GenericRepository[models.Image]
+	// the files of the images, they are uploaded by the handler and deleted by the deletions worker.
+	Images storage.ImageStorage
 }
 
-func NewStore(DB *gorm.DB) *Store {
+func NewStore(DB *gorm.DB, images storage.ImageStorage) *Store {
 	return &Store{
 		DB:      DB,
 		Generic: &generic.GenericRepository[models.Image]{DB: DB},
+		Images:  images,
 	}
 }
 
+func (imageStore *Store) WithContext(ctx context.Context) types.ImageStore {
+	return NewStore(imageStore.DB.WithContext(ctx), imageStore.Images)
+}
+
 var (
 	notFoundMsg = "image with id: '%v' was not found"
 )
@@ -47,11 +57,13 @@ func (imageStore *Store) CreateImage(image *models.Image) (*models.Image, error)
 	return image, nil
 }
 
-func (imageStore *Store) UpdateImageUrl(id uint, newImageUrl string) (error) {
-	err := imageStore.DB.Model(&models.Image{}).Where("id = ?", id).Update("ImageUrl", newImageUrl).Error
-    if err != nil {
-        return err
-    }
+// the url and the variants of a replaced image, its public id does not change.
+func (imageStore *Store) UpdateImageFile(id uint, upResult *types.UploadResponse) error {
+	err := imageStore.DB.Model(&models.Image{}).Where("id = ?", id).
+	Select("ImageUrl", "Variants").Updates(&models.Image{ImageUrl: upResult.URL, Variants: upResult.Variants}).Error
+	if err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -84,19 +96,20 @@ func (imageStore *Store) DeleteImageById(id uint) error {
 	if *image.IsMain == true {
 		return fmt.Errorf("you can not delete a main product image, set another product image as main then try again")
 	}
-	imgHandler := utils.NewImagesHandler()
 
-	err = imgHandler.DeleteOne(image.ImagePublicId, context.Background())
-	if err != nil {
-		return err
-	}
+	// the file is deleted by the deletions worker once the row is gone
+	return imageStore.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Delete(&image).Error
+		if err != nil {
+			return err
+		}
 
-	err = imageStore.DB.Unscoped().Delete(image).Error
-	if err != nil {
-		return err
-	}
+		return EnqueueDeletions(tx, []string{image.ImagePublicId})
+	})
+}
 
-	return nil
+func (imageStore *Store) DiscardUploads(uploadResults []*types.UploadResponse) {
+	DiscardUploads(imageStore.DB, uploadResults)
 }
 
 func (imageStore *Store) SetImageAsMainTx(tx *gorm.DB, id, productId uint) error {
@@ -114,7 +127,7 @@ func (imageStore *Store) SetImageAsMainTx(tx *gorm.DB, id, productId uint) error
 
 func (imageStore *Store) SetImageAsNotMainTx(tx *gorm.DB, productId uint) error {
 	isTrue := false
-	err := tx.Where("is_main = 1 AND product_id = ?", productId).
+	err := tx.Where("is_main = true AND product_id = ?", productId).
 	Select("IsMain").Updates(&models.Image{IsMain: &isTrue}).Error
 	if err != nil {
 		return err
@@ -161,24 +174,172 @@ func (imageStore *Store) SwapMainStatus(id, productId uint) error {
 	})
 }
 
-func (imageStore *Store) CreateManyImages(uploadResults []*types.UploadResponse, productId *uint) ([]models.Image, error) {
+// the images are added after the images of the product in the order of the uploads, altTexts[i] is the alt text of
+// the i-th upload when it is given.
+func (imageStore *Store) CreateManyImages(uploadResults []*types.UploadResponse, altTexts []string, productId *uint) ([]models.Image, error) {
+	var lastPosition *int
+	err := imageStore.DB.Model(&models.Image{}).Where("product_id = ?", *productId).Select("MAX(position)").Scan(&lastPosition).Error
+	if err != nil {
+		return nil, err
+	}
+	nextPosition := 0
+	if lastPosition != nil {
+		nextPosition = *lastPosition + 1
+	}
+
 	var images = make([]models.Image, 0, len(uploadResults))
 	isMain := false
-	for _, upResult := range uploadResults {
+	for i, upResult := range uploadResults {
 		newImg := models.Image{
 			ProductID: *productId,
 			ImageUrl: upResult.URL,
 			IsMain: &isMain,
 			ImagePublicId: upResult.PublicID,
+			Variants: upResult.Variants,
+			Position: nextPosition + i,
+		}
+		if i < len(altTexts) {
+			newImg.AltText = strings.TrimSpace(altTexts[i])
 		}
 		
 		images = append(images, newImg)
 	}
 
-	err := imageStore.DB.Create(images).Error
+	err = imageStore.DB.Create(images).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
+
+// the ids must be exactly the ids of the images of the product, the position of an image is its index.
+func (imageStore *Store) ReorderImages(productId uint, imageIds []uint) error {
+	return imageStore.DB.Transaction(func(tx *gorm.DB) error {
+		var currentIds []uint
+		err := tx.Model(&models.Image{}).Where("product_id = ?", productId).Pluck("id", &currentIds).Error
+		if err != nil {
+			return err
+		}
+
+		if len(currentIds) != len(imageIds) || slices.ContainsFunc(imageIds, func(id uint) bool { return !slices.Contains(currentIds, id) }) {
+			return fmt.Errorf("the image ids must be the ids of the '%v' images of the product, got '%v'", len(currentIds), imageIds)
+		}
+
+		for position, id := range imageIds {
+			err := tx.Model(&models.Image{}).Where("id = ?", id).Update("position", position).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (imageStore *Store) UpdateImageAltText(id, productId uint, altText string) (*models.Image, error) {
+	result := imageStore.DB.Model(&models.Image{}).Where("id = ? AND product_id = ?", id, productId).Update("alt_text", altText)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf(notFoundMsg, id)
+	}
+
+	return imageStore.GetImageById(id)
+}
+
+// records the signed uploads of the product, they must be confirmed within confirmWindow after their expiration.
+func (imageStore *Store) CreatePendingUploads(productId uint, uploads []*types.SignedUpload, confirmWindow time.Duration) error {
+	pendingUploads := make([]models.PendingUpload, 0, len(uploads))
+	for _, upload := range uploads {
+		pendingUploads = append(pendingUploads, models.PendingUpload{
+			ProductID:     productId,
+			PublicId:      upload.PublicId,
+			ConfirmBefore: upload.ExpiresAt.Add(confirmWindow),
+		})
+	}
+
+	return imageStore.DB.Create(pendingUploads).Error
+}
+
+// the uploads of the product that can still be confirmed, they count towards its images capacity.
+func (imageStore *Store) GetCountOfPendingUploads(productId uint, now time.Time) (int64, error) {
+	var count int64
+	err := imageStore.DB.Model(&models.PendingUpload{}).Where("product_id = ? AND confirm_before > ?", productId, now).Count(&count).Error
+	return count, err
+}
+
+// the uploads among the public ids that were signed for the product and can still be confirmed.
+func (imageStore *Store) GetPendingUploads(productId uint, publicIds []string, now time.Time) ([]models.PendingUpload, error) {
+	var pendingUploads []models.PendingUpload
+	err := imageStore.DB.Where("product_id = ? AND public_id IN ? AND confirm_before > ?", productId, publicIds, now).Find(&pendingUploads).Error
+	return pendingUploads, err
+}
+
+// the file of a refused upload is deleted by the deletions worker.
+func (imageStore *Store) DiscardPendingUpload(publicId string) error {
+	return imageStore.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("public_id = ?", publicId).Delete(&models.PendingUpload{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		return EnqueueDeletions(tx, []string{publicId})
+	})
+}
+
+// creates the images of the confirmed uploads (see CreateManyImages) and removes their pending uploads, the
+// confirmation fails when one of them was expired meanwhile since its file is being deleted.
+func (imageStore *Store) ConfirmUploads(productId uint, uploadResults []*types.UploadResponse, altTexts []string) ([]models.Image, error) {
+	publicIds := make([]string, 0, len(uploadResults))
+	for _, upResult := range uploadResults {
+		publicIds = append(publicIds, upResult.PublicID)
+	}
+
+	var images []models.Image
+	err := imageStore.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("product_id = ? AND public_id IN ?", productId, publicIds).Delete(&models.PendingUpload{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != int64(len(publicIds)) {
+			return fmt.Errorf("the uploads '%v' expired before their confirmation", publicIds)
+		}
+
+		var err error
+		images, err = NewStore(tx, imageStore.Images).CreateManyImages(uploadResults, altTexts, &productId)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
+
+func (imageStore *Store) GetAuditSnapshot(ctx context.Context, id uint) (any, error) {
+	return imageStore.Generic.GetAuditSnapshot(ctx, id)
+}
+
+// the images of a product are audited as a whole since creating images and changing the main image touch several rows.
+func (imageStore *Store) GetProductImagesAuditSnapshot(ctx context.Context, productId uint) (any, error) {
+	var images []models.Image
+	err := imageStore.DB.WithContext(ctx).Where("product_id = ?", productId).Order("id").Find(&images).Error
 	if err != nil {
 		return nil, err
 	}
 
 	return images, nil
-}
\ No newline at end of file
+}
+
+// the signed uploads of a product are audited as a whole like its images.
+func (imageStore *Store) GetPendingUploadsAuditSnapshot(ctx context.Context, productId uint) (any, error) {
+	var pendingUploads []models.PendingUpload
+	err := imageStore.DB.WithContext(ctx).Where("product_id = ?", productId).Order("id").Find(&pendingUploads).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return pendingUploads, nil
+}
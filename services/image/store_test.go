@@ -0,0 +1,94 @@
+package image
+
+import (
+	"testing"
+	"time"
+
+	"main.go/pkg/models"
+	"main.go/types"
+)
+
+func TestCreateManyImagesAppendsAfterTheProductImages(t *testing.T) {
+	store := NewStore(openTestDB(t), nil)
+	productId := uint(1)
+
+	first, err := store.CreateManyImages([]*types.UploadResponse{{PublicID: "a"}, {PublicID: "b"}}, []string{" a shoe "}, &productId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := store.CreateManyImages([]*types.UploadResponse{{PublicID: "c"}}, nil, &productId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first[0].Position != 0 || first[1].Position != 1 || second[0].Position != 2 {
+		t.Fatalf("expected the positions 0, 1 and 2, got %v, %v and %v", first[0].Position, first[1].Position, second[0].Position)
+	}
+	if first[0].AltText != "a shoe" || first[1].AltText != "" {
+		t.Fatalf("expected the alt text of the first upload only, got '%v' and '%v'", first[0].AltText, first[1].AltText)
+	}
+}
+
+func TestReorderImages(t *testing.T) {
+	store := NewStore(openTestDB(t), nil)
+	productId, otherProductId := uint(1), uint(2)
+	images, err := store.CreateManyImages([]*types.UploadResponse{{PublicID: "a"}, {PublicID: "b"}, {PublicID: "c"}}, nil, &productId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := store.CreateManyImages([]*types.UploadResponse{{PublicID: "d"}}, nil, &otherProductId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	invalidOrders := map[string][]uint{
+		"missing an image":              {images[2].ID, images[0].ID},
+		"with the image of another one": {images[2].ID, images[0].ID, other[0].ID},
+	}
+	for name, imageIds := range invalidOrders {
+		if err := store.ReorderImages(productId, imageIds); err == nil {
+			t.Errorf("expected the order %v to be refused", name)
+		}
+	}
+
+	if err := store.ReorderImages(productId, []uint{images[2].ID, images[0].ID, images[1].ID}); err != nil {
+		t.Fatal(err)
+	}
+	var publicIds []string
+	store.DB.Model(&models.Image{}).Where("product_id = ?", productId).Order("position").Pluck("image_public_id", &publicIds)
+	if len(publicIds) != 3 || publicIds[0] != "c" || publicIds[1] != "a" || publicIds[2] != "b" {
+		t.Fatalf("expected the order c, a, b, got %v", publicIds)
+	}
+}
+
+func TestConfirmUploads(t *testing.T) {
+	store := NewStore(openTestDB(t), nil)
+	now := time.Now()
+	uploads := []*types.SignedUpload{{PublicId: "a", ExpiresAt: now}, {PublicId: "b", ExpiresAt: now}}
+	if err := store.CreatePendingUploads(1, uploads, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.ConfirmUploads(2, []*types.UploadResponse{{PublicID: "a"}}, nil); err == nil {
+		t.Fatal("expected the upload of another product to be refused")
+	}
+
+	images, err := store.ConfirmUploads(1, []*types.UploadResponse{{PublicID: "a"}}, []string{"a shoe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(images) != 1 || images[0].ImagePublicId != "a" || images[0].AltText != "a shoe" {
+		t.Fatalf("expected the image of the upload, got %+v", images)
+	}
+
+	pending, err := store.GetPendingUploads(1, []string{"a", "b"}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].PublicId != "b" {
+		t.Fatalf("expected only the unconfirmed upload to be pending, got %+v", pending)
+	}
+	if _, err := store.ConfirmUploads(1, []*types.UploadResponse{{PublicID: "a"}}, nil); err == nil {
+		t.Fatal("expected a confirmed upload not to be confirmed twice")
+	}
+}
@@ -0,0 +1,189 @@
+package impersonation
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"main.go/constants"
+	"main.go/errors"
+	"main.go/middlewares"
+	"main.go/pkg/payloads"
+	"main.go/pkg/utils"
+	"main.go/services/auth"
+	"main.go/types"
+)
+
+type Handler struct {
+	store types.ImpersonationStore
+}
+
+func NewHandler(store Store) *Handler {
+	return &Handler{
+		store: &store,
+	}
+}
+
+var Authenticate = middlewares.Authenticate
+var AuthorizePermission = middlewares.AuthorizePermission
+var BlockImpersonation = middlewares.BlockImpersonation
+var Pagination = middlewares.PaginationMiddleware
+var Audit = middlewares.Audit
+
+func invalidSessionIdErr(id string) error {
+	return errors.NewInvalidIDError("impersonation session", id)
+}
+
+// the id of the impersonate route is the one of the target user, the started session is read from the response.
+func (h *Handler) audit(action types.AuditAction, idKey string) func(next http.HandlerFunc) http.HandlerFunc {
+	return Audit(action, middlewares.AuditResource{Type: "impersonation-sessions", IdKey: idKey, BodyKey: "session", Load: h.store.GetAuditSnapshot})
+}
+
+func (h *Handler) RegisterRoutes(router *http.ServeMux) {
+	authorizeImpersonate := AuthorizePermission(types.PermUsersImpersonate)
+
+	router.HandleFunc(utils.RoutePath("POST", "/admin/users/{id}/impersonate"), Authenticate(BlockImpersonation(authorizeImpersonate(h.audit(types.AuditStartImpersonation, "")(h.ImpersonateUser)))))
+	router.HandleFunc(utils.RoutePath("GET", "/admin/impersonations"), Pagination(Authenticate(BlockImpersonation(authorizeImpersonate(h.GetAllSessions)))))
+	router.HandleFunc(utils.RoutePath("GET", "/admin/impersonations/{id}/requests"), Pagination(Authenticate(BlockImpersonation(authorizeImpersonate(h.GetSessionRequests)))))
+	router.HandleFunc(utils.RoutePath("DELETE", "/admin/impersonations/{id}"), Authenticate(BlockImpersonation(authorizeImpersonate(h.audit(types.AuditEndImpersonation, constants.IdUrlPathKey)(h.EndSession)))))
+}
+
+// the returned token must be sent as a bearer token, it acts as the target user until it expires or the session is ended.
+func (h *Handler) ImpersonateUser(w http.ResponseWriter, r *http.Request) {
+	Id, receivedStr, err := utils.GetValidateId(r, constants.IdUrlPathKey)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, errors.NewInvalidIDError("user", receivedStr))
+		return
+	}
+
+	iuPayload, err := utils.ValidateAndParseBody[payloads.ImpersonateUser](r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	iuPayload.TrimStrs()
+
+	impersonatorId, err := utils.GetUserIdCtx(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, errors.ErrGenericMessage)
+		return
+	}
+	if *impersonatorId == *Id {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("can not impersonate yourself"))
+		return
+	}
+
+	target, err := h.store.WithContext(r.Context()).GetUserById(*Id)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	allowed, err := canImpersonate(r.Context(), *impersonatorId, target.ID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, errors.ErrGenericMessage)
+		return
+	}
+	if !allowed {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("can not impersonate a user with permissions you don't have"))
+		return
+	}
+
+	session, err := h.store.WithContext(r.Context()).CreateSession(iuPayload.ToModel(*impersonatorId, target.ID))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	token, err := auth.GenerateImpersonationToken(target, *impersonatorId, session.ID, session.ExpiresAt)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, errors.ErrGenericMessage)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, map[string]any{
+		"session":   session,
+		"token":     token,
+		"tokenType": "Bearer",
+	})
+}
+
+// the target must not have any permission the impersonator lacks, otherwise impersonating it (e.g an admin
+// impersonating a superadmin) would grant the impersonator those permissions.
+func canImpersonate(ctx context.Context, impersonatorId, targetId uint) (bool, error) {
+	impersonatorPermissions, err := middlewares.UserPermissions(ctx, impersonatorId)
+	if err != nil {
+		return false, err
+	}
+
+	targetPermissions, err := middlewares.UserPermissions(ctx, targetId)
+	if err != nil {
+		return false, err
+	}
+
+	for _, permission := range targetPermissions {
+		if !slices.Contains(impersonatorPermissions, permission) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (h *Handler) GetAllSessions(w http.ResponseWriter, r *http.Request) {
+	pagination := middlewares.GetPagination(r)
+
+	sessions, count, err := h.store.WithContext(r.Context()).GetAllSessions(pagination.Page, pagination.Limit)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]any{
+		"page":     pagination.Page,
+		"limit":    pagination.Limit,
+		"count":    count,
+		"sessions": sessions,
+	})
+}
+
+func (h *Handler) GetSessionRequests(w http.ResponseWriter, r *http.Request) {
+	Id, receivedStr, err := utils.GetValidateId(r, constants.IdUrlPathKey)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, invalidSessionIdErr(receivedStr))
+		return
+	}
+	pagination := middlewares.GetPagination(r)
+
+	requests, count, err := h.store.WithContext(r.Context()).GetSessionRequests(*Id, pagination.Page, pagination.Limit)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]any{
+		"page":     pagination.Page,
+		"limit":    pagination.Limit,
+		"count":    count,
+		"requests": requests,
+	})
+}
+
+func (h *Handler) EndSession(w http.ResponseWriter, r *http.Request) {
+	Id, receivedStr, err := utils.GetValidateId(r, constants.IdUrlPathKey)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, invalidSessionIdErr(receivedStr))
+		return
+	}
+
+	session, err := h.store.WithContext(r.Context()).EndSession(*Id)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]any{
+		"session": session,
+	})
+}
@@ -0,0 +1,74 @@
+package impersonation
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"main.go/config"
+	"main.go/middlewares"
+	"main.go/pkg/models"
+	"main.go/pkg/test_utils/testdb"
+	"main.go/types"
+)
+
+// creates a user with a role holding the given permissions.
+func createUser(t *testing.T, DB *gorm.DB, name string, permissions ...types.Permission) uint {
+	t.Helper()
+	user := &models.User{Name: name, Email: name + "@example.com", Password: "-"}
+	role := &models.Role{Name: name}
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(role).Error; err != nil {
+			return err
+		}
+		for _, name := range permissions {
+			permission := models.Permission{Name: string(name)}
+			if err := tx.Where(&permission).FirstOrCreate(&permission).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&models.RolePermissions{RoleID: role.ID, PermissionID: permission.ID}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(&models.UserRoles{UserID: user.ID, RoleID: role.ID}).Error
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user.ID
+}
+
+func TestCanImpersonate(t *testing.T) {
+	DB := testdb.SQLite(t, &models.User{}, &models.Role{}, &models.Permission{}, &models.UserRoles{}, &models.RolePermissions{})
+	if err := middlewares.Setup(DB, config.Envs); err != nil {
+		t.Fatal(err)
+	}
+
+	superAdmin := createUser(t, DB, "superadmin", types.PermUsersImpersonate, types.PermProductsDelete, types.PermRolesUpdate)
+	admin := createUser(t, DB, "admin", types.PermUsersImpersonate, types.PermProductsDelete)
+	otherAdmin := createUser(t, DB, "otheradmin", types.PermProductsDelete, types.PermOrdersUpdate)
+	customer := createUser(t, DB, "customer")
+
+	tests := []struct {
+		impersonator, target uint
+		expected             bool
+	}{
+		{impersonator: superAdmin, target: admin, expected: true},
+		{impersonator: admin, target: customer, expected: true},
+		{impersonator: admin, target: superAdmin, expected: false},
+		{impersonator: admin, target: otherAdmin, expected: false},
+		{impersonator: customer, target: admin, expected: false},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("Should return %v for the user %v impersonating the user %v", test.expected, test.impersonator, test.target), func(t *testing.T) {
+			allowed, err := canImpersonate(context.Background(), test.impersonator, test.target)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, allowed)
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package impersonation
+
+import (
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+func Setup(DB *gorm.DB, router *http.ServeMux) {
+	store := NewStore(DB)
+	handler := NewHandler(*store)
+	handler.RegisterRoutes(router)
+}
@@ -0,0 +1,118 @@
+package impersonation
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	appErrors "main.go/errors"
+	"main.go/pkg/models"
+	"main.go/pkg/utils"
+	"main.go/services/generic"
+	"main.go/types"
+)
+
+var (
+	notFoundMsg = "impersonation session with id: '%v' was not found"
+)
+
+type Store struct {
+	DB      *gorm.DB
+	Generic *generic.GenericRepository[models.ImpersonationSession]
+}
+
+func NewStore(DB *gorm.DB) *Store {
+	return &Store{
+		DB:      DB,
+		Generic: &generic.GenericRepository[models.ImpersonationSession]{DB: DB},
+	}
+}
+
+func (impersonationStore *Store) WithContext(ctx context.Context) types.ImpersonationStore {
+	return NewStore(impersonationStore.DB.WithContext(ctx))
+}
+
+func (impersonationStore *Store) GetUserById(id uint) (*models.User, error) {
+	var user models.User
+	err := impersonationStore.DB.First(&user, id).Error
+	if err != nil {
+		return nil, appErrors.NewResourceWasNotFoundError("user with id: '%v' was not found", id)
+	}
+
+	return &user, nil
+}
+
+func (impersonationStore *Store) CreateSession(session *models.ImpersonationSession) (*models.ImpersonationSession, error) {
+	err := impersonationStore.DB.Create(session).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (impersonationStore *Store) GetAllSessions(page, limit int) ([]models.ImpersonationSession, int64, error) {
+	var sessions []models.ImpersonationSession
+	var count int64
+
+	err := impersonationStore.DB.Model(&models.ImpersonationSession{}).Count(&count).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	offset := utils.CalculateOffset(page, limit)
+	err = impersonationStore.DB.Preload("Impersonator").Preload("TargetUser").
+		Order("id DESC").Offset(offset).Limit(limit).Find(&sessions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return sessions, count, nil
+}
+
+func (impersonationStore *Store) GetSessionRequests(sessionId uint, page, limit int) ([]models.ImpersonationRequest, int64, error) {
+	_, err := impersonationStore.Generic.GetOne(sessionId, notFoundMsg)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var requests []models.ImpersonationRequest
+	var count int64
+	err = impersonationStore.DB.Model(&models.ImpersonationRequest{}).Where("session_id = ?", sessionId).Count(&count).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	offset := utils.CalculateOffset(page, limit)
+	err = impersonationStore.DB.Where("session_id = ?", sessionId).Order("id").Offset(offset).Limit(limit).Find(&requests).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return requests, count, nil
+}
+
+// ending an already ended session keeps its original end time.
+func (impersonationStore *Store) EndSession(id uint) (*models.ImpersonationSession, error) {
+	session, err := impersonationStore.Generic.GetOne(id, notFoundMsg)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.EndedAt != nil {
+		return &session, nil
+	}
+
+	now := time.Now()
+	err = impersonationStore.DB.Model(&session).UpdateColumn("ended_at", now).Error
+	if err != nil {
+		return nil, err
+	}
+	session.EndedAt = &now
+
+	return &session, nil
+}
+
+func (impersonationStore *Store) GetAuditSnapshot(ctx context.Context, id uint) (any, error) {
+	return impersonationStore.Generic.GetAuditSnapshot(ctx, id)
+}
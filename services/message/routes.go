@@ -22,10 +22,10 @@ func NewHandler(store Store) *Handler {
 }
 
 var Authenticate = middlewares.Authenticate
-var AuthorizeAdmin = middlewares.AuthorizeAdmin
+var AuthorizePermission = middlewares.AuthorizePermission
 
 func (h *Handler) RegisterRoutes(router *http.ServeMux) {
-	router.HandleFunc(utils.RoutePath("GET", "/messages/users/{userId}"), Authenticate(AuthorizeAdmin(h.GetUserMessages)))
+	router.HandleFunc(utils.RoutePath("GET", "/messages/users/{userId}"), Authenticate(AuthorizePermission(types.PermMessagesRead)(h.GetUserMessages)))
 	router.HandleFunc(utils.RoutePath("GET", "/messages"), Authenticate(h.GetChatMessages))
 }
 
@@ -43,7 +43,7 @@ func (h *Handler) GetUserMessages(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	messages, err := h.store.GetById(*userId, uint(lastMessageId), *cursor, limit)
+	messages, err := h.store.WithContext(r.Context()).GetById(*userId, uint(lastMessageId), *cursor, limit)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
@@ -67,7 +67,7 @@ func (h *Handler) GetChatMessages(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	messages, err := h.store.GetByUsersIds(*userId, uint(to), uint(lastMessageId), *cursor ,limit)
+	messages, err := h.store.WithContext(r.Context()).GetByUsersIds(*userId, uint(to), uint(lastMessageId), *cursor ,limit)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
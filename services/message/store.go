@@ -1,12 +1,14 @@
 package message
 
 import (
+	"context"
 	"sync"
 	"time"
 
 	"gorm.io/gorm"
 	"main.go/pkg/models"
 	"main.go/services/generic"
+	"main.go/types"
 )
 
 type Store struct {
@@ -21,6 +23,10 @@ func NewStore(DB *gorm.DB) *Store {
 	}
 }
 
+func (s *Store) WithContext(ctx context.Context) types.MessageStore {
+	return NewStore(s.DB.WithContext(ctx))
+}
+
 func (s *Store) GetById(userId uint, lastMessageId uint, cursor time.Time, limit int) ([]*models.Message, error) {
 	var messages []*models.Message
 	var user models.User
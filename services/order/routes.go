@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"net/http"
 
+	"gorm.io/gorm"
 	"main.go/constants"
 	"main.go/errors"
 	"main.go/middlewares"
+	"main.go/pkg/metrics"
 	"main.go/pkg/models"
 	"main.go/pkg/payloads"
 	"main.go/pkg/utils"
@@ -16,11 +18,14 @@ import (
 
 type Handler struct {
 	store types.OrderStore
+	// the rows of the list route are read by the generic filter.
+	DB *gorm.DB
 }
 
 func NewHandler(store Store) *Handler {
 	return &Handler{
 		store: &store,
+		DB:    store.DB,
 	}
 }
 
@@ -29,14 +34,22 @@ func invalidOrderIdErr(id string) error {
 }
 
 var Authenticate = middlewares.Authenticate
-var AuthorizeAdmin = middlewares.AuthorizeAdmin
+var AuthenticateWithApiKey = middlewares.AuthenticateWithApiKey
+var AuthorizePermission = middlewares.AuthorizePermission
+var BlockImpersonation = middlewares.BlockImpersonation
+var Audit = middlewares.Audit
+var RequireOrdersRead = middlewares.RequireScope(types.ScopeOrdersRead)
+
+func (h *Handler) audit(action types.AuditAction) func(next http.HandlerFunc) http.HandlerFunc {
+	return Audit(action, middlewares.AuditResource{Type: "orders", IdKey: constants.IdUrlPathKey, Load: h.store.GetAuditSnapshot})
+}
 
 func (h *Handler) RegisterRoutes(router *http.ServeMux) {
-	router.HandleFunc(utils.RoutePath("GET", "/orders/{id}"), Authenticate(h.GetOrderById))
-	router.HandleFunc(utils.RoutePath("GET", "/orders"), Authenticate(h.GetAllOrders))
-	router.HandleFunc(utils.RoutePath("POST", "/orders"), Authenticate(h.CreateOrder))
+	router.HandleFunc(utils.RoutePath("GET", "/orders/{id}"), AuthenticateWithApiKey(RequireOrdersRead(h.GetOrderById)))
+	router.HandleFunc(utils.RoutePath("GET", "/orders"), AuthenticateWithApiKey(RequireOrdersRead(h.GetAllOrders)))
+	router.HandleFunc(utils.RoutePath("POST", "/orders"), Authenticate(BlockImpersonation(h.CreateOrder)))
 	router.HandleFunc(utils.RoutePath("DELETE", "/orders/{id}"), Authenticate(h.CancelOrderById))
-	router.HandleFunc(utils.RoutePath("PATCH", "/orders/{id}/status"), Authenticate(AuthorizeAdmin(h.UpdateOrderStatusById)))
+	router.HandleFunc(utils.RoutePath("PATCH", "/orders/{id}/status"), AuthenticateWithApiKey(AuthorizePermission(types.PermOrdersUpdate)(h.audit(types.AuditUpdateStatus)(h.UpdateOrderStatusById))))
 }
 
 func (h *Handler) GetOrderById(w http.ResponseWriter, r *http.Request) {
@@ -46,7 +59,7 @@ func (h *Handler) GetOrderById(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	order, err := h.store.GetPopulatedOrderById(*Id)
+	order, err := h.store.WithContext(r.Context()).GetPopulatedOrderById(*Id)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
@@ -62,6 +75,7 @@ func (h *Handler) GetAllOrders(w http.ResponseWriter, r *http.Request) {
 	sortString := utils.GetSortQ(r, whiteListedSortParams)
 
 	orders, count, errs := utils.GenericFilterWithJoins[models.Order, types.GetAllOrdersRows](&utils.GenericFilterConfigWithJoins{
+		DB:                h.DB.WithContext(r.Context()),
 		Filters:           conditions,
 		SortQ:             sortString,
 		SelectQ:           selectAllOrdersQ,
@@ -95,7 +109,7 @@ func (h *Handler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	address, err := h.store.GetAddressById(coPayload.AddressId)
+	address, err := h.store.WithContext(r.Context()).GetAddressById(coPayload.AddressId)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
@@ -106,20 +120,20 @@ func (h *Handler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cart, err := h.store.GetCart(*userId)
+	cart, err := h.store.WithContext(r.Context()).GetCart(*userId)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
-	orderItems := h.store.ConvertToOrderItems(cart)
-	productsIds := h.store.ExtractProductIds(cart)
-	prods, err := h.store.GetProductsByIds(productsIds)
+	orderItems := h.store.WithContext(r.Context()).ConvertToOrderItems(cart)
+	productsIds := h.store.WithContext(r.Context()).ExtractProductIds(cart)
+	prods, err := h.store.WithContext(r.Context()).GetProductsByIds(productsIds)
 	if err != nil {
 		// most likely at this point user is hacking so error 500 is returned
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
-	totalPrice, err := h.store.ValidateAndCalTotalPrice(prods, orderItems)
+	totalPrice, err := h.store.WithContext(r.Context()).ValidateAndCalTotalPrice(prods, orderItems)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
@@ -131,7 +145,7 @@ func (h *Handler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 		TotalPrice: *totalPrice,
 	}
 
-	cartItemsCount, err := h.store.GetCartItemsCount(*userId)
+	cartItemsCount, err := h.store.WithContext(r.Context()).GetCartItemsCount(*userId)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
@@ -141,11 +155,12 @@ func (h *Handler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.store.CreateOrderWithItems(&order, userId, orderItems)
+	err = h.store.WithContext(r.Context()).CreateOrderWithItems(&order, userId, orderItems)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
+	metrics.OrdersCreated.Inc()
 	order.TotalPrice = utils.TruncateToTwoDecimals(order.TotalPrice)
 		
 	utils.WriteJSON(w, http.StatusCreated, map[string]any{"order": order})
@@ -164,7 +179,7 @@ func (h *Handler) UpdateOrderStatusById(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err = h.store.UpdateOrderStatus(*Id, uPayload.Status)
+	err = h.store.WithContext(r.Context()).UpdateOrderStatus(*Id, uPayload.Status)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
@@ -186,7 +201,7 @@ func (h *Handler) CancelOrderById(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.store.CancelOrder(*Id, *userId)
+	err = h.store.WithContext(r.Context()).CancelOrder(*Id, *userId)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
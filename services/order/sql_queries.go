@@ -15,7 +15,7 @@ var selectOneOrderQ = `orders.id as id, orders.user_id as user_id, orders.total_
 var joinWOrderItems = `LEFT JOIN order_items ON order_items.order_id = orders.id`
 var joinWProducts = `LEFT JOIN products ON order_items.product_id = products.id`
 var jointWAddress = `LEFT JOIN addresses ON orders.address_id = addresses.id`
-var jointWProductImages = `LEFT JOIN images ON images.product_id = products.id AND images.is_main = 1`
+var jointWProductImages = `LEFT JOIN images ON images.product_id = products.id AND images.is_main = true`
 
 var selectAllOrdersQ = `orders.id as id, orders.user_id as user_id, orders.total_price as total_price, 
 				orders.status as status, orders.created_at as created_at, orders.updated_at as updated_at,
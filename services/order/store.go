@@ -1,6 +1,7 @@
 package order
 
 import (
+	"context"
 	"fmt"
 	"sync"
 
@@ -23,6 +24,10 @@ func NewStore(DB *gorm.DB) *Store {
 	}
 }
 
+func (orderStore *Store) WithContext(ctx context.Context) types.OrderStore {
+	return NewStore(orderStore.DB.WithContext(ctx))
+}
+
 var (
 	notFoundMsg = "order with id: '%v' was not found"
 )
@@ -268,3 +273,7 @@ func (orderStore *Store) GetOrderItems(orderId uint) ([]models.OrderItem, error)
 
 	return orderItems, nil
 }
+
+func (orderStore *Store) GetAuditSnapshot(ctx context.Context, id uint) (any, error) {
+	return orderStore.Generic.GetAuditSnapshot(ctx, id)
+}
@@ -0,0 +1,292 @@
+package product
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"main.go/middlewares"
+	"main.go/pkg/payloads"
+	"main.go/pkg/utils"
+	"main.go/types"
+)
+
+const (
+	maxImportSizeInMB int64 = 5
+	maxImportRows           = 5000
+	// the export is flushed to the client every batch of rows
+	exportFlushRows = 100
+)
+
+// the columns of the export, the import reads the same columns so an exported file can be edited and imported back.
+var productsCSVColumns = []string{"id", "sku", "barcode", "name", "description", "price", "quantity", "categoryId", "category"}
+
+// the products are matched by their sku, the id column is only informative. The category is read from categoryId, or
+// from the category name when categoryId is empty. The barcode column is optional.
+var requiredImportColumns = []string{"sku", "name", "price", "quantity"}
+
+// streams every product that is not deleted, with the name of its category and its stock.
+func (h *Handler) ExportProducts(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "csv" {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("format must be one of: 'csv'"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="products.csv"`)
+	w.WriteHeader(http.StatusOK)
+
+	// the writer is wrapped by the middlewares, the controller reaches the flusher under them
+	controller := http.NewResponseController(w)
+	writer := csv.NewWriter(w)
+	writer.Write(productsCSVColumns)
+	count := 0
+	err := h.store.WithContext(r.Context()).ExportProducts(r.Context(), func(row *types.RowProductExport) error {
+		writer.Write(productCSVRecord(row))
+		count++
+		if count%exportFlushRows == 0 {
+			writer.Flush()
+			if err := writer.Error(); err != nil {
+				return err
+			}
+			if err := controller.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+				return err
+			}
+		}
+		return writer.Error()
+	})
+	writer.Flush()
+	if err == nil {
+		err = writer.Error()
+	}
+	// the status is already sent, the client gets a truncated file
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to write the products export", "error", err)
+	}
+}
+
+func importDryRun(r *http.Request) (bool, error) {
+	value := r.URL.Query().Get("dryRun")
+	if value == "" {
+		return false, nil
+	}
+	dryRun, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("dryRun must be a boolean")
+	}
+	return dryRun, nil
+}
+
+// the dry runs change nothing so they are not audited, the import records the ids of the products it changed.
+func (h *Handler) auditImport(next http.HandlerFunc) http.HandlerFunc {
+	audited := Audit(types.AuditImport, middlewares.AuditResource{Type: "products", BodyKey: "report", IdsKeys: []string{"createdIds", "updatedIds"}})(next)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if dryRun, err := importDryRun(r); err != nil || dryRun {
+			next(w, r)
+			return
+		}
+		audited(w, r)
+	}
+}
+
+// upserts the products of a csv file (the "file" field) by their sku, every row is validated like a created product
+// and the file is imported only when no row fails. With ?dryRun=true the file is checked without being imported.
+func (h *Handler) ImportProducts(w http.ResponseWriter, r *http.Request) {
+	dryRun, err := importDryRun(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSizeInMB<<20)
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("expected a csv file of at most %vMB in the 'file' field", maxImportSizeInMB))
+		return
+	}
+	defer file.Close()
+
+	categoryIds, err := h.store.WithContext(r.Context()).GetCategoryIdsByName()
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	rows, rowErrors, err := readProductsCSV(file, categoryIds)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// the rows that were parsed are still checked against the database so the report lists every error at once
+	report, err := h.store.WithContext(r.Context()).ImportProducts(rows, dryRun || len(rowErrors) != 0)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	report.DryRun = dryRun
+	report.Rows += len(rowErrors)
+	report.Errors = append(report.Errors, rowErrors...)
+	slices.SortStableFunc(report.Errors, func(a, b types.ProductImportError) int { return a.Line - b.Line })
+
+	if len(report.Errors) != 0 {
+		report.Created, report.Updated = 0, 0
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]any{"report": report})
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]any{"report": report})
+}
+
+func productCSVRecord(row *types.RowProductExport) []string {
+	barcode, description := "", ""
+	if row.Barcode != nil {
+		barcode = *row.Barcode
+	}
+	if row.Description != nil {
+		description = *row.Description
+	}
+
+	return []string{
+		strconv.FormatUint(uint64(row.ID), 10),
+		escapeCSVFormula(row.SKU),
+		escapeCSVFormula(barcode),
+		escapeCSVFormula(row.Name),
+		escapeCSVFormula(description),
+		strconv.FormatFloat(row.Price, 'f', 2, 64),
+		strconv.FormatUint(uint64(row.Quantity), 10),
+		strconv.FormatUint(uint64(row.CategoryID), 10),
+		escapeCSVFormula(row.CategoryName),
+	}
+}
+
+// the spreadsheets run a cell starting with one of these as a formula, and hide a leading quote.
+const csvFormulaPrefixes = "=+-@\t\r'"
+
+// prefixes a value the spreadsheets would run as a formula with a quote, so it is shown as text.
+func escapeCSVFormula(value string) string {
+	if value != "" && strings.ContainsRune(csvFormulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// removes the quote of an escaped value so an exported file is imported back unchanged.
+func unescapeCSVFormula(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(csvFormulaPrefixes, rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
+
+// parses and validates the rows of the file, the errors of the file itself (no header, a missing column, too many
+// rows) are returned as the error and the errors of the rows are reported by line.
+func readProductsCSV(file io.Reader, categoryIds map[string][]uint) ([]types.ProductImportRow, []types.ProductImportError, error) {
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read the header of the csv file: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		// the byte order mark written by the spreadsheets
+		column = strings.TrimPrefix(column, "\ufeff")
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	for _, column := range requiredImportColumns {
+		if _, ok := columns[column]; !ok {
+			return nil, nil, fmt.Errorf("the csv file must have the columns: %v, and categoryid or category", strings.Join(requiredImportColumns, ", "))
+		}
+	}
+	_, hasCategoryId := columns["categoryid"]
+	_, hasCategory := columns["category"]
+	if !hasCategoryId && !hasCategory {
+		return nil, nil, fmt.Errorf("the csv file must have the columns: %v, and categoryid or category", strings.Join(requiredImportColumns, ", "))
+	}
+
+	rows := make([]types.ProductImportRow, 0)
+	rowErrors := make([]types.ProductImportError, 0)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+			return nil, nil, fmt.Errorf("failed to read the csv file: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		if len(rows)+len(rowErrors) == maxImportRows {
+			return nil, nil, fmt.Errorf("the csv file can not have more than %v rows", maxImportRows)
+		}
+
+		value := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(unescapeCSVFormula(record[i]))
+		}
+		if err != nil {
+			rowErrors = append(rowErrors, types.ProductImportError{Line: line, SKU: value("sku"), Error: "the row must have a value per column"})
+			continue
+		}
+
+		product, err := parseImportProduct(value, categoryIds)
+		if err == nil {
+			err = utils.ValidateStruct(product)
+		}
+		if err != nil {
+			rowErrors = append(rowErrors, types.ProductImportError{Line: line, SKU: value("sku"), Error: utils.ValidationErrorMessage(err)})
+			continue
+		}
+
+		rows = append(rows, types.ProductImportRow{Line: line, Product: product.ToModelWithImage("")})
+	}
+
+	return rows, rowErrors, nil
+}
+
+func parseImportProduct(value func(column string) string, categoryIds map[string][]uint) (*payloads.CreateProduct, error) {
+	quantity, err := strconv.ParseUint(value("quantity"), 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quantity")
+	}
+	price, err := strconv.ParseFloat(value("price"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid price")
+	}
+
+	var categoryId uint64
+	if value("categoryid") != "" {
+		categoryId, err = strconv.ParseUint(value("categoryid"), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid category id")
+		}
+	} else {
+		ids := categoryIds[value("category")]
+		if len(ids) != 1 {
+			return nil, fmt.Errorf("expected one category named '%v', found %v", value("category"), len(ids))
+		}
+		categoryId = uint64(ids[0])
+	}
+
+	product := &payloads.CreateProduct{
+		Name:        value("name"),
+		Quantity:    uint(quantity),
+		Description: value("description"),
+		CategoryID:  uint(categoryId),
+		Price:       price,
+		SKU:         value("sku"),
+		Barcode:     value("barcode"),
+	}
+
+	return product.TrimStrs(), nil
+}
@@ -0,0 +1,124 @@
+package product
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"main.go/pkg/models"
+	"main.go/pkg/test_utils/testdb"
+	"main.go/types"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	return testdb.SQLite(t, &models.Category{}, &models.Product{}, &models.ProductSlug{})
+}
+
+func TestReadProductsCSV(t *testing.T) {
+	file := strings.Join([]string{
+		"\ufeffSKU,name,description,price,quantity,category",
+		"SHOE-1,Running shoe,A light shoe,49.90,12,Shoes",
+		"SHOE 2,Walking shoe,,39.90,3,Shoes",
+		"SHOE-3,Boot,,not a price,3,Shoes",
+		"SHOE-4,Sandal,,19.90,3,Hats",
+		"SHOE-5,Slipper",
+		"SHOE-6,Golden shoe,,100000,1,Shoes",
+	}, "\n")
+
+	rows, rowErrors, err := readProductsCSV(strings.NewReader(file), map[string][]uint{"Shoes": {1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].Product.SKU != "SHOE-1" || rows[0].Product.CategoryID != 1 || rows[0].Line != 2 {
+		t.Fatalf("expected only the first row to be valid, got %+v", rows)
+	}
+
+	lines := make([]int, 0, len(rowErrors))
+	for _, rowError := range rowErrors {
+		lines = append(lines, rowError.Line)
+	}
+	if !slices.Equal(lines, []int{3, 4, 5, 6, 7}) {
+		t.Fatalf("expected the errors of the lines 3 to 7, got %+v", rowErrors)
+	}
+}
+
+func TestProductCSVRecordEscapesFormulas(t *testing.T) {
+	description := "-2+3"
+	record := productCSVRecord(&types.RowProductExport{
+		ID:           1,
+		SKU:          "SHOE-1",
+		Name:         "=HYPERLINK(\"http://example.com\")",
+		Description:  &description,
+		Price:        10,
+		CategoryName: "@Shoes",
+	})
+	expected := []string{"1", "SHOE-1", "", "'=HYPERLINK(\"http://example.com\")", "'-2+3", "10.00", "0", "0", "'@Shoes"}
+	if !slices.Equal(record, expected) {
+		t.Fatalf("expected the record %q, got %q", expected, record)
+	}
+
+	for _, value := range []string{"+1", "\tcell", "\rcell", "'=quoted"} {
+		if unescapeCSVFormula(escapeCSVFormula(value)) != value {
+			t.Errorf("expected %q to be imported back unchanged", value)
+		}
+	}
+}
+
+func TestReadProductsCSVRequiresTheColumns(t *testing.T) {
+	_, _, err := readProductsCSV(strings.NewReader("sku,name,price,quantity\n"), nil)
+	if err == nil {
+		t.Fatal("expected a file without a category column to be refused")
+	}
+}
+
+func TestImportProducts(t *testing.T) {
+	DB := openTestDB(t)
+	store := NewStore(DB)
+	DB.Create(&models.Category{Name: "Shoes"})
+	existingSKU := "SHOE-1"
+	DB.Create(&models.Product{Name: "Old shoe", SKU: existingSKU, Quantity: 1, CategoryID: 1, Price: 10})
+
+	row := func(line int, sku string, categoryId uint) types.ProductImportRow {
+		return types.ProductImportRow{Line: line, Product: &models.Product{Name: "Shoe", SKU: sku, Quantity: 5, CategoryID: categoryId, Price: 20}}
+	}
+
+	report, err := store.ImportProducts([]types.ProductImportRow{row(2, "SHOE-1", 1), row(3, "SHOE-2", 1)}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Created != 1 || report.Updated != 1 || len(report.Errors) != 0 {
+		t.Fatalf("expected one created and one updated product, got %+v", report)
+	}
+	if report.CreatedIds != nil || report.UpdatedIds != nil {
+		t.Fatalf("expected the dry run not to report the ids, got %+v", report)
+	}
+	var count int64
+	if DB.Model(&models.Product{}).Count(&count); count != 1 {
+		t.Fatalf("expected the dry run not to write, got %v products", count)
+	}
+
+	report, err = store.ImportProducts([]types.ProductImportRow{row(2, "SHOE-2", 1), row(3, "SHOE-2", 1), row(4, "SHOE-3", 2)}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Errors) != 2 || report.Errors[0].Line != 3 || report.Errors[1].Line != 4 {
+		t.Fatalf("expected the duplicated sku and the unknown category to be reported, got %+v", report.Errors)
+	}
+	if DB.Model(&models.Product{}).Count(&count); count != 1 {
+		t.Fatalf("expected a failed import not to write, got %v products", count)
+	}
+
+	report, err = store.ImportProducts([]types.ProductImportRow{row(2, "SHOE-1", 1), row(3, "SHOE-2", 1)}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(report.CreatedIds, []uint{2}) || !slices.Equal(report.UpdatedIds, []uint{1}) {
+		t.Fatalf("expected the ids of the created and updated products, got %+v", report)
+	}
+	var updated models.Product
+	DB.Where("sku = ?", "SHOE-1").First(&updated)
+	if DB.Model(&models.Product{}).Count(&count); count != 2 || updated.Quantity != 5 {
+		t.Fatalf("expected the import to upsert the products, got %v products and %+v", count, updated)
+	}
+}
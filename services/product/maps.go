@@ -1,15 +1,15 @@
 package product
 
 var whiteListedParams = map[string]any{
-	"name":          1,
+	"name":          "products.name",
 	"price_lte":     1,
 	"price_gte":     1,
 	//"avg_rating_lte": 1,
 	//"avg_rating_gte": 1,
 	//"avgRating":     1,
-	"price":         1,
-	"quantity":      1,
-	"categoryId":    1,
+	"price":         "products.price",
+	"quantity":      "products.quantity",
+	"categoryId":    "products.category_id",
 }
 
 var whiteListedSortParams = map[string]any{
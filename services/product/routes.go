@@ -3,23 +3,34 @@ package product
 import (
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 
+	"gorm.io/gorm"
 	"main.go/constants"
 	"main.go/errors"
 	"main.go/middlewares"
 	"main.go/pkg/models"
 	"main.go/pkg/payloads"
+	"main.go/pkg/storage"
 	"main.go/pkg/utils"
+	"main.go/services/category"
+	"main.go/services/image"
 	"main.go/types"
 )
 
 type Handler struct {
 	store types.ProductStore
+	// the rows of the list route are read by the generic filter.
+	DB     *gorm.DB
+	images storage.ImageStorage
 }
 
-func NewHandler(store Store) *Handler {
+func NewHandler(store Store, images storage.ImageStorage) *Handler {
 	return &Handler{
-		store: &store,
+		store:  &store,
+		DB:     store.DB,
+		images: images,
 	}
 }
 
@@ -28,14 +39,24 @@ func invalidProdIdErr(id string) error {
 }
 
 var Authenticate = middlewares.Authenticate
-var AuthorizeAdmin = middlewares.AuthorizeAdmin
+var AuthorizePermission = middlewares.AuthorizePermission
+var AuthenticateWithApiKey = middlewares.AuthenticateWithApiKey
+var Audit = middlewares.Audit
 var Pagination = middlewares.PaginationMiddleware
 
+func (h *Handler) audit(action types.AuditAction) func(next http.HandlerFunc) http.HandlerFunc {
+	return Audit(action, middlewares.AuditResource{Type: "products", IdKey: constants.IdUrlPathKey, BodyKey: "product", Load: h.store.GetAuditSnapshot})
+}
+
 func (h *Handler) RegisterRoutes(router *http.ServeMux) {
 	router.HandleFunc(utils.RoutePath("GET", "/products/{id}"), h.GetProductById)
+	router.HandleFunc(utils.RoutePath("GET", "/products/by-slug/{slug}"), h.GetProductBySlug)
+	router.HandleFunc(utils.RoutePath("GET", "/products/by-sku/{sku}"), h.GetProductBySKU)
 	router.HandleFunc(utils.RoutePath("GET", "/products"),Pagination(h.GetAllProducts))
-	router.HandleFunc(utils.RoutePath("POST", "/products"), Authenticate(AuthorizeAdmin(h.CreateProduct)))
-	router.HandleFunc(utils.RoutePath("PUT", "/products/{id}"), Authenticate(AuthorizeAdmin(h.UpdateProduct)))
+	router.HandleFunc(utils.RoutePath("POST", "/products"), Authenticate(AuthorizePermission(types.PermProductsCreate)(h.audit(types.AuditCreate)(h.CreateProduct))))
+	router.HandleFunc(utils.RoutePath("PUT", "/products/{id}"), AuthenticateWithApiKey(AuthorizePermission(types.PermProductsUpdate)(h.audit(types.AuditUpdate)(h.UpdateProduct))))
+	router.HandleFunc(utils.RoutePath("GET", "/admin/products/export"), Authenticate(AuthorizePermission(types.PermProductsExport)(h.ExportProducts)))
+	router.HandleFunc(utils.RoutePath("POST", "/admin/products/import"), Authenticate(AuthorizePermission(types.PermProductsImport)(h.auditImport(h.ImportProducts))))
 }
 
 func (h *Handler) GetProductById(w http.ResponseWriter, r *http.Request) {
@@ -45,9 +66,39 @@ func (h *Handler) GetProductById(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	productRows, err := h.store.GetProductById(*Id)
+	h.writeProduct(w, r, *Id)
+}
+
+// the previous slugs of a renamed product are redirected to its current slug.
+func (h *Handler) GetProductBySlug(w http.ResponseWriter, r *http.Request) {
+	slug := r.PathValue("slug")
+	Id, currentSlug, err := h.store.WithContext(r.Context()).GetProductIdBySlug(slug)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if currentSlug != slug {
+		http.Redirect(w, r, constants.Prefix+"/products/by-slug/"+url.PathEscape(currentSlug), http.StatusMovedPermanently)
+		return
+	}
+
+	h.writeProduct(w, r, Id)
+}
+
+func (h *Handler) GetProductBySKU(w http.ResponseWriter, r *http.Request) {
+	Id, err := h.store.WithContext(r.Context()).GetProductIdBySKU(r.PathValue("sku"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	h.writeProduct(w, r, Id)
+}
+
+func (h *Handler) writeProduct(w http.ResponseWriter, r *http.Request, Id uint) {
+	productRows, err := h.store.WithContext(r.Context()).GetProductById(Id)
 	if err != nil || len(productRows) == 0 {
-		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("product with id: '%v' was not found", *Id))
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("product with id: '%v' was not found", Id))
 		return
 	}
 	product := convertRowsToProduct(productRows)
@@ -58,9 +109,14 @@ func (h *Handler) GetProductById(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) GetAllProducts(w http.ResponseWriter, r *http.Request) {
 	pagination := middlewares.GetPagination(r)
 
-	conditions := utils.GetFilterConditions(r, whiteListedParams)
+	conditions, filterErr := h.includeDescendants(r, utils.GetFilterConditions(r, whiteListedParams))
+	if filterErr != nil {
+		utils.WriteError(w, http.StatusBadRequest, filterErr)
+		return
+	}
 	sortString := utils.GetSortQ(r, whiteListedSortParams)
 	rows, count, err := utils.GenericFilterWithJoins[models.Product, types.GetAllProductsRow](&utils.GenericFilterConfigWithJoins{
+		DB:                h.DB.WithContext(r.Context()),
 		Filters:           conditions,
 		SortQ:             sortString,
 		Pagination:        pagination,
@@ -83,6 +139,39 @@ func (h *Handler) GetAllProducts(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// with ?includeDescendants=true the categoryId filter matches the products of the subcategories too.
+func (h *Handler) includeDescendants(r *http.Request, conditions []types.FilterCondition) ([]types.FilterCondition, error) {
+	value := r.URL.Query().Get("includeDescendants")
+	if value == "" {
+		return conditions, nil
+	}
+	include, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("includeDescendants must be a boolean")
+	}
+	if !include {
+		return conditions, nil
+	}
+
+	for i, condition := range conditions {
+		if condition.Field != "categoryId" || condition.Operator != "=" {
+			continue
+		}
+		categoryId, err := utils.ConvertStrToUint(fmt.Sprint(condition.Value))
+		if err != nil {
+			return nil, errors.NewInvalidIDError("category", fmt.Sprint(condition.Value))
+		}
+		categoryIds, err := category.DescendantIds(h.DB.WithContext(r.Context()), *categoryId)
+		if err != nil {
+			return nil, err
+		}
+		conditions[i] = types.FilterCondition{Field: condition.Field, Operator: "IN", Value: categoryIds}
+		return conditions, nil
+	}
+
+	return nil, fmt.Errorf("includeDescendants requires a categoryId")
+}
+
 func (h *Handler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 	crPayload, err := utils.ValidateAndParseFormData(r, func() (*payloads.CreateProduct, error) {
 		payload ,err := payloads.NewCreatePayload(r, utils.ConvertStrToUint,utils.ConvertStrToFloat64)
@@ -105,16 +194,16 @@ func (h *Handler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 	}
 	defer file.Close()
 
-	imgsHandler := utils.NewImagesHandler()
-	resp, err := imgsHandler.UploadOne(&file, fileHeader, types.ProductsFolder, r.Context())
+	resp, err := h.images.Upload(r.Context(), file, fileHeader.Filename, types.ProductsFolder)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
 	}
 
 	prod := crPayload.TrimStrs().ToModelWithImage(resp.SecureUrl)
-	product, err := h.store.CreateProductWithImage(prod, resp)
+	product, err := h.store.WithContext(r.Context()).CreateProductWithImage(prod, resp)
 	if err != nil {
+		image.DiscardUploads(h.DB, []*types.UploadResponse{resp})
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
 	}
@@ -137,12 +226,12 @@ func (h *Handler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if upPayload.IsEmpty() {
-		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("at least one of (name, quantity, description, categoryId, price) is required"))
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("at least one of (name, quantity, description, categoryId, price, sku, barcode) is required"))
 		return
 	}
 
 	prod := upPayload.TrimStrs().ToModel()
-	product, err := h.store.UpdateProduct(*Id, prod, upPayload)
+	product, err := h.store.WithContext(r.Context()).UpdateProduct(*Id, prod, upPayload)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
@@ -9,15 +9,12 @@ import (
 
 	"github.com/brianvoe/gofakeit/v6"
 	"gorm.io/gorm"
-	"main.go/internal/database"
 	"main.go/pkg/models"
 	"main.go/pkg/test_utils"
-	"main.go/services"
 )
 
 func TestProductsHandler(t *testing.T) {
-	server := http.NewServeMux()
-	services.SetupAllServices(database.DB, server)
+	server := test_utils.NewServer()
 	productIdNotExist := 9999999
 
 	t.Run("Should get products and return 200 status code", func(t *testing.T) {
@@ -92,6 +89,7 @@ func TestProductsHandler(t *testing.T) {
 		writer.WriteField("categoryId", "1")
 		writer.WriteField("price", "400")
 		writer.WriteField("name", "product name")
+		writer.WriteField("sku", gofakeit.UUID())
 
 		writer.Close()
 		req, err := http.NewRequest("POST", test_utils.GetRoutePath("/products"), bytes)
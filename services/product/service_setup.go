@@ -4,10 +4,11 @@ import (
 	"net/http"
 
 	"gorm.io/gorm"
+	"main.go/pkg/storage"
 )
 
-func Setup(DB *gorm.DB, router *http.ServeMux) {
+func Setup(DB *gorm.DB, images storage.ImageStorage, router *http.ServeMux) {
 	store := NewStore(DB)
-	handler := NewHandler(*store)
+	handler := NewHandler(*store, images)
 	handler.RegisterRoutes(router)
 }
@@ -0,0 +1,83 @@
+package product
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"main.go/pkg/models"
+	"main.go/pkg/utils"
+)
+
+// the slug of the name that no other product has or had, a number is appended to the taken slugs ("shoe-2"). The
+// slugs of the soft deleted products stay taken since they can be restored.
+func uniqueSlug(tx *gorm.DB, name string, productId uint) (string, error) {
+	base := utils.Slugify(name)
+	if base == "" {
+		base = "product"
+	}
+
+	var current, previous []string
+	err := tx.Unscoped().Model(&models.Product{}).Where("id <> ? AND (slug = ? OR slug LIKE ?)", productId, base, base+"-%").
+		Pluck("slug", &current).Error
+	if err != nil {
+		return "", err
+	}
+	err = tx.Model(&models.ProductSlug{}).Where("product_id <> ? AND (slug = ? OR slug LIKE ?)", productId, base, base+"-%").
+		Pluck("slug", &previous).Error
+	if err != nil {
+		return "", err
+	}
+
+	taken := make(map[string]bool, len(current)+len(previous))
+	for _, slug := range append(current, previous...) {
+		taken[slug] = true
+	}
+	slug := base
+	for i := 2; taken[slug]; i++ {
+		slug = fmt.Sprintf("%s-%d", base, i)
+	}
+
+	return slug, nil
+}
+
+// the slug of the renamed product, its previous slug is kept so its links are redirected. The slug does not change
+// when it was already derived from the name.
+func renameSlug(tx *gorm.DB, product *models.Product, name string) (string, error) {
+	if slugOfName(product.Slug, utils.Slugify(name)) {
+		return product.Slug, nil
+	}
+
+	slug, err := uniqueSlug(tx, name, product.ID)
+	if err != nil {
+		return "", err
+	}
+
+	// a product renamed back gets its previous slug again
+	err = tx.Where("product_id = ? AND slug = ?", product.ID, slug).Delete(&models.ProductSlug{}).Error
+	if err != nil {
+		return "", err
+	}
+	if product.Slug != "" {
+		err = tx.Create(&models.ProductSlug{ProductID: product.ID, Slug: product.Slug}).Error
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return slug, nil
+}
+
+// "shoe" and "shoe-2" are slugs of the name "Shoe".
+func slugOfName(slug, base string) bool {
+	if slug == base {
+		return true
+	}
+	suffix, ok := strings.CutPrefix(slug, base+"-")
+	if !ok {
+		return false
+	}
+	_, err := strconv.Atoi(suffix)
+	return err == nil
+}
@@ -0,0 +1,70 @@
+package product
+
+import (
+	"testing"
+
+	"main.go/pkg/models"
+	"main.go/pkg/payloads"
+)
+
+func TestProductSlugs(t *testing.T) {
+	DB := openTestDB(t)
+	store := NewStore(DB)
+	DB.Create(&models.Category{Name: "Shoes"})
+
+	first, err := store.CreateProduct(&models.Product{Name: "Running Shoe", SKU: "SHOE-1", Quantity: 1, CategoryID: 1, Price: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := store.CreateProduct(&models.Product{Name: "running  shoe", SKU: "SHOE-2", Quantity: 1, CategoryID: 1, Price: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Slug != "running-shoe" || second.Slug != "running-shoe-2" {
+		t.Fatalf("expected the slugs running-shoe and running-shoe-2, got %v and %v", first.Slug, second.Slug)
+	}
+
+	update := &payloads.UpdateProduct{Name: "Trail Shoe"}
+	if _, err := store.UpdateProduct(first.ID, update.ToModel(), update); err != nil {
+		t.Fatal(err)
+	}
+	id, slug, err := store.GetProductIdBySlug("running-shoe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != first.ID || slug != "trail-shoe" {
+		t.Fatalf("expected the previous slug to lead to trail-shoe, got %v %v", id, slug)
+	}
+
+	// the previous slug stays taken by the renamed product
+	third, err := store.CreateProduct(&models.Product{Name: "Running Shoe", SKU: "SHOE-3", Quantity: 1, CategoryID: 1, Price: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third.Slug != "running-shoe-3" {
+		t.Fatalf("expected the slug running-shoe-3, got %v", third.Slug)
+	}
+
+	update = &payloads.UpdateProduct{Name: "Running Shoe"}
+	if _, err := store.UpdateProduct(first.ID, update.ToModel(), update); err != nil {
+		t.Fatal(err)
+	}
+	if _, slug, err := store.GetProductIdBySlug("running-shoe"); err != nil || slug != "running-shoe" {
+		t.Fatalf("expected the product renamed back to get its slug again, got %v %v", slug, err)
+	}
+}
+
+func TestProductSKUIsUnique(t *testing.T) {
+	store := NewStore(openTestDB(t))
+	sku := "SHOE-1"
+
+	if _, err := store.CreateProduct(&models.Product{Name: "Shoe", SKU: sku, Quantity: 1, CategoryID: 1, Price: 10}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.CreateProduct(&models.Product{Name: "Other shoe", SKU: sku, Quantity: 1, CategoryID: 1, Price: 10}); err == nil {
+		t.Fatal("expected the sku to be refused")
+	}
+	if _, err := store.GetProductIdBySKU(sku); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -2,7 +2,9 @@ package product
 
 // * this file better to be re-factored to ensure more readable queries and better structs names.
 import (
+	"cmp"
 	"fmt"
+	"slices"
 
 	"main.go/types"
 )
@@ -10,6 +12,9 @@ import (
 var getProductByIdQ = ` 
 		products.id as id,
 		products.name as name,
+		products.sku as sku,
+		products.barcode as barcode,
+		products.slug as slug,
 		products.quantity as quantity,
 		products.description as description,
 		products.price as price,
@@ -22,6 +27,8 @@ var getProductByIdQ = `
 		images.image_url as image_url,
 		images.image_public_id as image_public_id,
 		images.is_main as is_main,
+		images.position as image_position,
+		images.alt_text as image_alt_text,
 		categories.name as category_name,
 		reviews.review_id as review_id,
 		reviews.comment as review_comment,
@@ -33,6 +40,9 @@ var getProductByIdQ = `
 		users.email as user_email,
 		users.avatar as user_avatar
 	`
+var productsExportQ = `products.id, products.sku, products.barcode, products.name, products.description, products.price, products.quantity,
+	products.category_id, COALESCE(categories.name, '') AS category_name`
+
 var getProductByIdJoins = `
 LEFT JOIN images ON images.product_id = products.id
 LEFT JOIN categories ON categories.id = products.category_id
@@ -58,7 +68,8 @@ LEFT JOIN (SELECT
  LIMIT 9) reviews ON reviews.product_id = products.id
  LEFT JOIN users ON reviews.user_id = users.id
  `
-var groupByGetProductById = `products.id, images.id, review_id, users.id, avg_rating.avg_rating`
+var groupByGetProductById = `products.id, images.id, categories.name, avg_rating.avg_rating, reviews.review_id, reviews.comment,
+	reviews.rate, reviews.user_id, reviews.created_at, reviews.updated_at, users.id`
 
 func convertRowsToProduct(rows []types.RowGetProductById) *types.RespGetOneProductShape{
 	var product types.RespGetOneProductShape
@@ -72,6 +83,9 @@ func convertRowsToProduct(rows []types.RowGetProductById) *types.RespGetOneProdu
 			product = types.RespGetOneProductShape{
 				ID: row.ID,
 				Name: row.Name,
+				SKU: row.SKU,
+				Barcode: row.Barcode,
+				Slug: row.Slug,
 				Description: row.Description,
 				Quantity: row.Quantity,
 				Price: row.Price,
@@ -91,6 +105,8 @@ func convertRowsToProduct(rows []types.RowGetProductById) *types.RespGetOneProdu
 				ImageUrl: row.ImageUrl,
 				ImagePublicId: row.ImagePublicId,
 				IsMain: row.IsMain,
+				Position: row.ImagePosition,
+				AltText: row.ImageAltText,
 			})
 
 			IdsMap[fmt.Sprintf("imageId-%v",row.ImageId)] = fmt.Sprintf("imageId-%v",row.ImageId) 
@@ -116,19 +132,22 @@ func convertRowsToProduct(rows []types.RowGetProductById) *types.RespGetOneProdu
 		}
 		
 	}
+	slices.SortStableFunc(images, func(a, b types.RowGetOneProductImage) int {
+		return cmp.Or(cmp.Compare(a.Position, b.Position), cmp.Compare(a.ImageId, b.ImageId))
+	})
 	product.Images = images
 	product.Reviews = reviews
 	return &product
 }
 
-var prodsSelectCols = `	products.id as id, products.name as name, products.quantity as quantity,
+var prodsSelectCols = `	products.id as id, products.name as name, products.sku as sku, products.slug as slug, products.quantity as quantity,
 				products.description as description, products.category_id as category_id,
 				products.price as price,products.created_at as created_at,
 				products.updated_at as updated_at,
  				images.id as image_id ,images.image_url as image_url, images.image_public_id as image_public_id,
 				AVG(reviews.rate) AS avg_rating
 			`
-var imagesJoin = "LEFT JOIN images on products.id = images.product_id AND images.is_main = 1"
+var imagesJoin = "LEFT JOIN images on products.id = images.product_id AND images.is_main = true"
 var reviewsJoin = "LEFT JOIN reviews on products.id = reviews.product_id"
 var prodsGroupBy = "products.id, images.id"
 
@@ -142,6 +161,8 @@ func convertRowsToResp(rows []types.GetAllProductsRow) []types.RespGetAllProduct
 			resAllProducts := &types.RespGetAllProductsShape{
 				Id:          row.Id,
 				Name:        row.Name,
+				SKU:         row.SKU,
+				Slug:        row.Slug,
 				Quantity:    row.Quantity,
 				Description: row.Description,
 				CategoryId:  row.CategoryId,
@@ -1,10 +1,14 @@
 package product
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"slices"
 
 	"gorm.io/gorm"
 	"main.go/constants"
+	appErrors "main.go/errors"
 	"main.go/pkg/models"
 	"main.go/pkg/utils"
 	"main.go/services/generic"
@@ -24,6 +28,10 @@ func NewStore(DB *gorm.DB) *Store {
 	}
 }
 
+func (prodStore *Store) WithContext(ctx context.Context) types.ProductStore {
+	return NewStore(prodStore.DB.WithContext(ctx))
+}
+
 var (
 	notFoundMsg = "product with id: '%v' was not found"
 )
@@ -50,32 +58,107 @@ func (prodStore *Store) GetAllProducts(page, limit int, filter func(db *gorm.DB,
 }
 
 func (prodStore *Store) CreateProduct(product *models.Product) (*models.Product, error) {
-	products, errs := prodStore.Generic.Create(product, constants.ProductCols)
+	slug, err := uniqueSlug(prodStore.DB, product.Name, 0)
+	if err != nil {
+		return nil, err
+	}
+	product.Slug = slug
+
+	products, errs := prodStore.Generic.Create(product, append(utils.CopyCols(constants.ProductCols), "Slug"))
 	if errs != nil {
-		return nil, errs
+		return nil, uniqueValuesErr(errs)
 	}
 
 	return products, nil
 }
 
+// a renamed product gets the slug of its new name, its previous slug is kept for the redirects.
 func (prodStore *Store) UpdateProduct(id uint, changes *models.Product, excluder types.Excluder) (*models.Product, error) {
 	prodColsCopy := utils.CopyCols(constants.ProductCols)
 	fields := excluder.Exclude(prodColsCopy)
-	product, errs := prodStore.Generic.FindThenUpdate(id, changes, fields, notFoundMsg)
-	if errs != nil {
-		return nil, errs
+
+	var product *models.Product
+	err := prodStore.DB.Transaction(func(tx *gorm.DB) error {
+		if changes.Name != "" {
+			var current models.Product
+			err := tx.Select("id", "slug").Where("id = ?", id).Limit(1).Find(&current).Error
+			if err != nil {
+				return err
+			}
+			if current.ID == 0 {
+				return appErrors.NewResourceWasNotFoundError(notFoundMsg, id)
+			}
+			changes.Slug, err = renameSlug(tx, &current, changes.Name)
+			if err != nil {
+				return err
+			}
+			fields = append(fields, "Slug")
+		}
+
+		var err error
+		product, err = (&generic.GenericRepository[models.Product]{DB: tx}).FindThenUpdate(id, changes, fields, notFoundMsg)
+		return err
+	})
+	if err != nil {
+		return nil, uniqueValuesErr(err)
 	}
 
 	return product, nil
 }
 
+// the id of the product that is not deleted with the sku.
+func (prodStore *Store) GetProductIdBySKU(sku string) (uint, error) {
+	var product models.Product
+	err := prodStore.DB.Select("id").Where("sku = ?", sku).Limit(1).Find(&product).Error
+	if err != nil {
+		return 0, err
+	}
+	if product.ID == 0 {
+		return 0, fmt.Errorf("product with sku: '%v' was not found", sku)
+	}
+
+	return product.ID, nil
+}
+
+// the id and the current slug of the product that has or had the slug, the slugs differ when the product was renamed.
+func (prodStore *Store) GetProductIdBySlug(slug string) (uint, string, error) {
+	var product models.Product
+	err := prodStore.DB.Select("id", "slug").Where("slug = ?", slug).Limit(1).Find(&product).Error
+	if err != nil {
+		return 0, "", err
+	}
+	if product.ID == 0 {
+		err = prodStore.DB.Select("products.id", "products.slug").
+		Joins("JOIN product_slugs ON product_slugs.product_id = products.id").
+		Where("product_slugs.slug = ?", slug).Limit(1).Find(&product).Error
+		if err != nil {
+			return 0, "", err
+		}
+	}
+	if product.ID == 0 {
+		return 0, "", fmt.Errorf("product with slug: '%v' was not found", slug)
+	}
+
+	return product.ID, product.Slug, nil
+}
+
+// the sku and the barcode are unique.
+func uniqueValuesErr(err error) error {
+	if utils.IsDuplicateKeyErr(err) {
+		return fmt.Errorf("the sku or the barcode is already used by another product")
+	}
+	return err
+}
+
 func (prodStore *Store) CreateImageTx(tx *gorm.DB, uploadResp *types.UploadResponse, productId uint, isMain bool) (*models.Image, error) {
-	imageStore := image.NewStore(tx)
+	// only the rows are created, the image is already stored
+	imageStore := image.NewStore(tx, nil)
 	newImage := &models.Image{
 		ProductID:     productId,
 		IsMain:        &isMain,
 		ImageUrl:      uploadResp.URL,
 		ImagePublicId: uploadResp.PublicID,
+		Variants:      uploadResp.Variants,
 	}
 
 	err := imageStore.Generic.CreateTx(newImage, tx)
@@ -91,7 +174,13 @@ func (prodStore *Store) CreateProductWithImage(product *models.Product, uploadRe
 	var returnedImg models.Image
 
 	err := prodStore.DB.Transaction(func(tx *gorm.DB) error {
-		err := prodStore.Generic.CreateTx(product, tx)
+		slug, err := uniqueSlug(tx, product.Name, 0)
+		if err != nil {
+			return err
+		}
+		product.Slug = slug
+
+		err = prodStore.Generic.CreateTx(product, tx)
 		if err != nil {
 			return err
 		}
@@ -108,9 +197,194 @@ func (prodStore *Store) CreateProductWithImage(product *models.Product, uploadRe
 	})
 
 	if err != nil {
-		return nil, err
+		return nil, uniqueValuesErr(err)
 	}
 	returnedProduct.Image = &returnedImg
 
 	return &returnedProduct, nil
-}
\ No newline at end of file
+}
+
+// calls write with each product ordered by id, the products are read from the database while they are written.
+func (prodStore *Store) ExportProducts(ctx context.Context, write func(row *types.RowProductExport) error) error {
+	rows, err := prodStore.DB.WithContext(ctx).Model(&models.Product{}).Select(productsExportQ).
+	Joins("LEFT JOIN categories ON categories.id = products.category_id").Order("products.id").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row types.RowProductExport
+		if err := prodStore.DB.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := write(&row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// the ids of the categories by their name, the names are not unique.
+func (prodStore *Store) GetCategoryIdsByName() (map[string][]uint, error) {
+	var categories []models.Category
+	err := prodStore.DB.Select("id", "name").Find(&categories).Error
+	if err != nil {
+		return nil, err
+	}
+
+	idsByName := make(map[string][]uint, len(categories))
+	for _, category := range categories {
+		idsByName[category.Name] = append(idsByName[category.Name], category.ID)
+	}
+
+	return idsByName, nil
+}
+
+// upserts the products by their sku in one transaction, nothing is written when a row fails or on a dry run so the
+// report of a dry run is the report of the import.
+func (prodStore *Store) ImportProducts(rows []types.ProductImportRow, dryRun bool) (*types.ProductsImportReport, error) {
+	report := &types.ProductsImportReport{DryRun: dryRun, Rows: len(rows), Errors: make([]types.ProductImportError, 0)}
+	rowError := func(row types.ProductImportRow, format string, args ...any) {
+		report.Errors = append(report.Errors, types.ProductImportError{Line: row.Line, SKU: row.Product.SKU, Error: fmt.Sprintf(format, args...)})
+	}
+
+	err := prodStore.DB.Transaction(func(tx *gorm.DB) error {
+		var categoryIds []uint
+		err := tx.Model(&models.Category{}).Pluck("id", &categoryIds).Error
+		if err != nil {
+			return err
+		}
+
+		skus := make([]string, 0, len(rows))
+		barcodes := make([]string, 0, len(rows))
+		for _, row := range rows {
+			skus = append(skus, row.Product.SKU)
+			if row.Product.Barcode != nil {
+				barcodes = append(barcodes, *row.Product.Barcode)
+			}
+		}
+		bySKU, err := productsByColumn(tx, "sku", skus)
+		if err != nil {
+			return err
+		}
+		byBarcode, err := productsByColumn(tx, "barcode", barcodes)
+		if err != nil {
+			return err
+		}
+
+		lines := make(map[string]int, len(rows))
+		barcodeLines := make(map[string]int, len(barcodes))
+		for _, row := range rows {
+			sku := row.Product.SKU
+			if line, ok := lines[sku]; ok {
+				rowError(row, "the sku is already used by the line '%v'", line)
+				continue
+			}
+			lines[sku] = row.Line
+
+			if !slices.Contains(categoryIds, row.Product.CategoryID) {
+				rowError(row, "category with id: '%v' was not found", row.Product.CategoryID)
+				continue
+			}
+
+			var current *models.Product
+			if product, ok := bySKU[sku]; ok {
+				current = &product
+			}
+			if current != nil && current.DeletedAt != nil && current.DeletedAt.Valid {
+				rowError(row, "the product with id: '%v' that has the sku is deleted", current.ID)
+				continue
+			}
+
+			if barcode := row.Product.Barcode; barcode != nil {
+				if line, ok := barcodeLines[*barcode]; ok {
+					rowError(row, "the barcode is already used by the line '%v'", line)
+					continue
+				}
+				barcodeLines[*barcode] = row.Line
+				if owner, ok := byBarcode[*barcode]; ok && (current == nil || owner.ID != current.ID) {
+					rowError(row, "the barcode is already used by the product with id: '%v'", owner.ID)
+					continue
+				}
+			}
+
+			err = saveImportedProduct(tx, current, row.Product)
+			if err != nil {
+				return err
+			}
+			if current == nil {
+				report.Created++
+				report.CreatedIds = append(report.CreatedIds, row.Product.ID)
+			} else {
+				report.Updated++
+				report.UpdatedIds = append(report.UpdatedIds, current.ID)
+			}
+		}
+
+		if dryRun || len(report.Errors) != 0 {
+			return errImportRolledBack
+		}
+		return nil
+	})
+	if errors.Is(err, errImportRolledBack) {
+		report.CreatedIds, report.UpdatedIds = nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+var errImportRolledBack = errors.New("the import is rolled back")
+
+// the products by the value of the column among the values, the soft deleted products are included since their
+// values are still taken.
+func productsByColumn(tx *gorm.DB, column string, values []string) (map[string]models.Product, error) {
+	products := make(map[string]models.Product, len(values))
+	for chunk := range slices.Chunk(values, 500) {
+		var found []models.Product
+		err := tx.Unscoped().Where(column+" IN ?", chunk).Find(&found).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, product := range found {
+			if column == "sku" {
+				products[product.SKU] = product
+			} else {
+				products[*product.Barcode] = product
+			}
+		}
+	}
+
+	return products, nil
+}
+
+// creates the product when current is nil, an empty barcode keeps the barcode of the updated product.
+func saveImportedProduct(tx *gorm.DB, current *models.Product, product *models.Product) error {
+	fields := utils.CopyCols(constants.ProductCols)
+	if product.Barcode == nil {
+		fields = slices.DeleteFunc(fields, func(field string) bool { return field == "Barcode" })
+	}
+	fields = append(fields, "Slug")
+
+	var err error
+	if current == nil {
+		product.Slug, err = uniqueSlug(tx, product.Name, 0)
+		if err != nil {
+			return err
+		}
+		return tx.Select(fields).Create(product).Error
+	}
+
+	product.Slug, err = renameSlug(tx, current, product.Name)
+	if err != nil {
+		return err
+	}
+	return tx.Model(current).Select(fields).Updates(product).Error
+}
+
+func (prodStore *Store) GetAuditSnapshot(ctx context.Context, id uint) (any, error) {
+	return prodStore.Generic.GetAuditSnapshot(ctx, id)
+}
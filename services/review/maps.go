@@ -1,12 +1,12 @@
 package review
 
 var whiteListedParams = map[string]any{
-	"userId":    1,
+	"userId":    "reviews.user_id",
 	"rate_lte":  1,
 	"rate_gte":  1,
-	"rate":      1,
-	"comment":   1,
-	"productId": 1,
+	"rate":      "reviews.rate",
+	"comment":   "reviews.comment",
+	"productId": "reviews.product_id",
 }
 
 var whiteListedSortParams = map[string]any{
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"gorm.io/gorm"
 	"main.go/constants"
 	appErrors "main.go/errors"
 	"main.go/middlewares"
@@ -16,11 +17,14 @@ import (
 
 type Handler struct {
 	store types.ReviewStore
+	// the rows of the list route are read by the generic filter.
+	DB *gorm.DB
 }
 
 func NewHandler(store Store) *Handler {
 	return &Handler{
 		store: &store,
+		DB:    store.DB,
 	}
 }
 
@@ -33,11 +37,11 @@ func invalidRevIdErr(id string) error {
 }
 
 var Authenticate = middlewares.Authenticate
-var AuthorizeAdmin = middlewares.AuthorizeAdmin
+var AuthorizePermission = middlewares.AuthorizePermission
 var Pagination = middlewares.PaginationMiddleware
 
 func (h *Handler) RegisterRoutes(router *http.ServeMux) {
-	router.HandleFunc(utils.RoutePath("GET", "/reviews"), Pagination(Authenticate(AuthorizeAdmin(h.GetAllReviews))))
+	router.HandleFunc(utils.RoutePath("GET", "/reviews"), Pagination(Authenticate(AuthorizePermission(types.PermReviewsRead)(h.GetAllReviews))))
 	router.HandleFunc(utils.RoutePath("POST", "/products/{id}/reviews"), Authenticate(h.AddReview))
 	router.HandleFunc(utils.RoutePath("PUT", "/products/{id}/reviews/{reviewId}"), Authenticate(h.EditReview))
 	router.HandleFunc(utils.RoutePath("DELETE", "/products/{id}/reviews/{reviewId}"), Authenticate(h.DeleteReview))
@@ -51,6 +55,7 @@ func (h *Handler) GetAllReviews(w http.ResponseWriter, r *http.Request) {
 
 	reviews, count, err := utils.GenericFilterWithJoins[models.Review, types.GetAllReviewsRow](
 		&utils.GenericFilterConfigWithJoins{
+			DB:                h.DB.WithContext(r.Context()),
 			Filters:           conditions,
 			SortQ:             sortString,
 			Pagination:        pagination,
@@ -88,14 +93,14 @@ func (h *Handler) AddReview(w http.ResponseWriter, r *http.Request) {
 		utils.WriteError(w, http.StatusBadRequest, appErrors.NewInvalidIDError("product",receivedStr))
 		return
 	}
-	product, err := h.store.GetProductById(*productId)
+	product, err := h.store.WithContext(r.Context()).GetProductById(*productId)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, appErrors.NewResourceWasNotFoundError("product with id: '%v' was not found", *productId))
 		return
 	}
 
 	review := crPayload.TrimStrs().ToModel(*userId, product.ID)
-	newRev, err := h.store.CreateReview(review)
+	newRev, err := h.store.WithContext(r.Context()).CreateReview(review)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
@@ -127,7 +132,7 @@ func (h *Handler) EditReview(w http.ResponseWriter, r *http.Request) {
 	}
 
 	model := upPayload.TrimStrs().ToModel()
-	review, err := h.store.UpdateReview(*Id, *userId, model, upPayload)
+	review, err := h.store.WithContext(r.Context()).UpdateReview(*Id, *userId, model, upPayload)
 	if err != nil {
 		if errors.Is(err, appErrors.ErrForbidden) {
 			utils.WriteError(w, http.StatusForbidden, err)
@@ -158,7 +163,7 @@ func (h *Handler) DeleteReview(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.store.HardDelete(*Id, *userId)
+	err = h.store.WithContext(r.Context()).HardDelete(*Id, *userId)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
@@ -9,15 +9,12 @@ import (
 
 	"github.com/brianvoe/gofakeit/v6"
 	"github.com/stretchr/testify/assert"
-	"main.go/internal/database"
 	"main.go/pkg/models"
 	"main.go/pkg/test_utils"
-	"main.go/services"
 )
 
 func TestReviewsHandler(t *testing.T) {
-	server := http.NewServeMux()
-	services.SetupAllServices(database.DB, server)
+	server := test_utils.NewServer()
 	reviewIdNotExistent := 9999999
 
 	t.Run("Should get all reviews with pagination and return 200 status code", func(t *testing.T) {
@@ -1,6 +1,7 @@
 package review
 
 import (
+	"context"
 	"gorm.io/gorm"
 	"main.go/constants"
 	"main.go/pkg/models"
@@ -25,6 +26,10 @@ func NewStore(DB *gorm.DB) *Store {
 	}
 }
 
+func (reviewStore *Store) WithContext(ctx context.Context) types.ReviewStore {
+	return NewStore(reviewStore.DB.WithContext(ctx))
+}
+
 func (reviewStore *Store) GetReviewById(Id uint) (*models.Review, error) {
 	review, err := reviewStore.Generic.GetOne(Id, notFoundMsg)
 	if err != nil {
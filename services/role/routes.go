@@ -22,25 +22,33 @@ func NewHandler(store Store) *Handler {
 }
 
 var Authenticate = middlewares.Authenticate
-var AuthorizeSuperAdmin = middlewares.AuthorizeSuperAdmin
+var AuthorizePermission = middlewares.AuthorizePermission
+var Audit = middlewares.Audit
 var Pagination = middlewares.PaginationMiddleware
 
 func invalidRoleIdErr(id string) error {
 	return errors.NewInvalidIDError("role", id)
 }
 
+func (h *Handler) audit(action types.AuditAction) func(next http.HandlerFunc) http.HandlerFunc {
+	return Audit(action, middlewares.AuditResource{Type: "roles", IdKey: constants.IdUrlPathKey, BodyKey: "role", Load: h.store.GetAuditSnapshot})
+}
 
 func (h *Handler) RegisterRoutes(router *http.ServeMux) {
-	router.HandleFunc(utils.RoutePath("GET","/roles"), Pagination(Authenticate(AuthorizeSuperAdmin(h.GetAllRoles))))
-	router.HandleFunc(utils.RoutePath("PUT","/roles/{id}"), Authenticate(AuthorizeSuperAdmin(h.UpdateRole)))
-	router.HandleFunc(utils.RoutePath("POST","/roles"), Authenticate(AuthorizeSuperAdmin(h.CreateRole)))
-	router.HandleFunc(utils.RoutePath("DELETE","/roles/{id}"), Authenticate(AuthorizeSuperAdmin(h.DeleteRole)))
+	router.HandleFunc(utils.RoutePath("GET","/roles"), Pagination(Authenticate(AuthorizePermission(types.PermRolesRead)(h.GetAllRoles))))
+	router.HandleFunc(utils.RoutePath("PUT","/roles/{id}"), Authenticate(AuthorizePermission(types.PermRolesUpdate)(h.audit(types.AuditUpdate)(h.UpdateRole))))
+	router.HandleFunc(utils.RoutePath("POST","/roles"), Authenticate(AuthorizePermission(types.PermRolesCreate)(h.audit(types.AuditCreate)(h.CreateRole))))
+	router.HandleFunc(utils.RoutePath("DELETE","/roles/{id}"), Authenticate(AuthorizePermission(types.PermRolesDelete)(h.audit(types.AuditDelete)(h.DeleteRole))))
+	router.HandleFunc(utils.RoutePath("GET","/permissions"), Authenticate(AuthorizePermission(types.PermPermissionsRead)(h.GetAllPermissions)))
+	router.HandleFunc(utils.RoutePath("GET","/roles/{id}/permissions"), Authenticate(AuthorizePermission(types.PermRolesRead)(h.GetRolePermissions)))
+	router.HandleFunc(utils.RoutePath("POST","/roles/{id}/permissions"), Authenticate(AuthorizePermission(types.PermRolesAssignPermissions)(h.audit(types.AuditAssignPermissions)(h.AssignRolePermissions))))
+	router.HandleFunc(utils.RoutePath("DELETE","/roles/{id}/permissions/{permissionId}"), Authenticate(AuthorizePermission(types.PermRolesAssignPermissions)(h.audit(types.AuditRemovePermission)(h.RemoveRolePermission))))
 }
 
 func (h *Handler) GetAllRoles(w http.ResponseWriter, r *http.Request) {
 	pagination := middlewares.GetPagination(r)
 
-	roles, count, err := h.store.GetAllRoles(pagination.Page, pagination.Limit)
+	roles, count, err := h.store.WithContext(r.Context()).GetAllRoles(pagination.Page, pagination.Limit)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
@@ -61,7 +69,7 @@ func (h *Handler) CreateRole(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	role, err := h.store.CreateRole(rcPayload.TrimStrs().ToModel())
+	role, err := h.store.WithContext(r.Context()).CreateRole(rcPayload.TrimStrs().ToModel())
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
@@ -84,11 +92,12 @@ func (h *Handler) UpdateRole(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	role, err := h.store.UpdateRole(*Id, ruPayload.TrimStrs().ToModel())
+	role, err := h.store.WithContext(r.Context()).UpdateRole(*Id, ruPayload.TrimStrs().ToModel())
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
 	}
+	middlewares.InvalidateRolesCache()
 
 	utils.WriteJSON(w, http.StatusAccepted, map[string]any{
 		"role":role,
@@ -102,11 +111,91 @@ func (h *Handler) DeleteRole(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	err = h.store.DeleteRole(*Id)
+	err = h.store.WithContext(r.Context()).DeleteRole(*Id)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	middlewares.InvalidateRolesCache()
+
+	utils.WriteJSON(w, http.StatusNoContent, map[string]any{})
+}
+
+func (h *Handler) GetAllPermissions(w http.ResponseWriter, r *http.Request) {
+	permissions, err := h.store.WithContext(r.Context()).GetAllPermissions()
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]any{
+		"permissions": permissions,
+	})
+}
+
+func (h *Handler) GetRolePermissions(w http.ResponseWriter, r *http.Request) {
+	Id, receivedStr,err := utils.GetValidateId(r, constants.IdUrlPathKey)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, invalidRoleIdErr(receivedStr))
+		return
+	}
+
+	permissions, err := h.store.WithContext(r.Context()).GetRolePermissions(*Id)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]any{
+		"permissions": permissions,
+	})
+}
+
+// assigning a permission that the role already has is ignored.
+func (h *Handler) AssignRolePermissions(w http.ResponseWriter, r *http.Request) {
+	Id, receivedStr,err := utils.GetValidateId(r, constants.IdUrlPathKey)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, invalidRoleIdErr(receivedStr))
+		return
+	}
+
+	apPayload, err := utils.ValidateAndParseBody[payloads.AssignPermissionsPayload](r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	permissions, err := h.store.WithContext(r.Context()).AssignRolePermissions(*Id, apPayload.PermissionIds)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	middlewares.InvalidateRolesCache()
+
+	utils.WriteJSON(w, http.StatusAccepted, map[string]any{
+		"permissions": permissions,
+	})
+}
+
+func (h *Handler) RemoveRolePermission(w http.ResponseWriter, r *http.Request) {
+	Id, receivedStr,err := utils.GetValidateId(r, constants.IdUrlPathKey)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, invalidRoleIdErr(receivedStr))
+		return
+	}
+
+	permissionId, receivedStr,err := utils.GetValidateId(r, "permissionId")
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, errors.NewInvalidIDError("permission", receivedStr))
+		return
+	}
+
+	err = h.store.WithContext(r.Context()).RemoveRolePermission(*Id, *permissionId)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
 	}
+	middlewares.InvalidateRolesCache()
 
 	utils.WriteJSON(w, http.StatusNoContent, map[string]any{})
-}
\ No newline at end of file
+}
@@ -1,10 +1,15 @@
 package role
 
 import (
+	"context"
+	"fmt"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"main.go/constants"
 	"main.go/pkg/models"
 	"main.go/services/generic"
+	"main.go/types"
 )
 
 var (
@@ -23,6 +28,10 @@ func NewStore(DB *gorm.DB) *Store {
 	}
 }
 
+func (roleStore *Store) WithContext(ctx context.Context) types.RolesStore {
+	return NewStore(roleStore.DB.WithContext(ctx))
+}
+
 func (roleStore *Store) GetAllRoles(page, limit int) ([]models.Role, int64, error) {
 	roles, count, errs := roleStore.Generic.GetAll(page, limit)
 	if len(errs) != 0 {
@@ -63,4 +72,78 @@ func (roleStore *Store) UpdateRole(id uint, role *models.Role) (*models.Role, er
 		return nil, err
 	}
 	return updatedRole, nil
-}
\ No newline at end of file
+}
+
+func (roleStore *Store) GetAllPermissions() ([]models.Permission, error) {
+	var permissions []models.Permission
+	err := roleStore.DB.Order("name").Find(&permissions).Error
+	if err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+func (roleStore *Store) GetRolePermissions(roleId uint) ([]models.Permission, error) {
+	role, err := roleStore.GetRole(roleId)
+	if err != nil {
+		return nil, err
+	}
+
+	var permissions []models.Permission
+	err = roleStore.DB.Model(role).Order("name").Association("Permissions").Find(&permissions)
+	if err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+func (roleStore *Store) AssignRolePermissions(roleId uint, permissionIds []uint) ([]models.Permission, error) {
+	_, err := roleStore.GetRole(roleId)
+	if err != nil {
+		return nil, err
+	}
+
+	var permissions []models.Permission
+	err = roleStore.DB.Where("id IN ?", permissionIds).Find(&permissions).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(permissions) != len(permissionIds) {
+		return nil, fmt.Errorf("one or more of the permissions were not found")
+	}
+
+	rolePermissions := make([]models.RolePermissions, 0, len(permissions))
+	for _, permission := range permissions {
+		rolePermissions = append(rolePermissions, models.RolePermissions{RoleID: roleId, PermissionID: permission.ID})
+	}
+
+	err = roleStore.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&rolePermissions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return roleStore.GetRolePermissions(roleId)
+}
+
+func (roleStore *Store) RemoveRolePermission(roleId, permissionId uint) error {
+	var rolePermission = models.RolePermissions{
+		RoleID:       roleId,
+		PermissionID: permissionId,
+	}
+	err := roleStore.DB.Where(&rolePermission).First(&rolePermission).Error
+	if err != nil {
+		return fmt.Errorf("role permission with keys (roleId-permissionId) '%v'-'%v' was not found", roleId, permissionId)
+	}
+
+	err = roleStore.DB.Where(&rolePermission).Delete(&models.RolePermissions{}).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// the permissions are included so assigning and removing permissions shows up in the changes.
+func (roleStore *Store) GetAuditSnapshot(ctx context.Context, id uint) (any, error) {
+	return roleStore.Generic.GetAuditSnapshot(ctx, id, "Permissions")
+}
@@ -5,10 +5,15 @@ import (
 
 	"gorm.io/gorm"
 	"main.go/pkg/models"
+	"main.go/pkg/storage"
 	"main.go/services/address"
+	"main.go/services/apikey"
+	"main.go/services/auditlog"
 	"main.go/services/cart"
 	"main.go/services/category"
 	"main.go/services/generic"
+	"main.go/services/identity"
+	"main.go/services/impersonation"
 	"main.go/services/image"
 	"main.go/services/message"
 	"main.go/services/order"
@@ -18,16 +23,16 @@ import (
 	"main.go/services/user"
 )
 
-func SetupAllServices(DB *gorm.DB, router *http.ServeMux) {
+func SetupAllServices(DB *gorm.DB, images storage.ImageStorage, router *http.ServeMux) {
 	category.Setup(DB, router)
-	generic.Setup[models.Category](DB, router, "categories", *adminRoles)
-	product.Setup(DB, router)
-	generic.Setup[models.Product](DB, router, "products", *adminRoles)
+	generic.Setup[models.Category](DB, router, "categories", *categoriesOpts())
+	product.Setup(DB, images, router)
+	generic.Setup[models.Product](DB, router, "products", *productsOpts())
 
-	image.Setup(DB, router)
+	image.Setup(DB, images, router)
 	order.Setup(DB, router)
-	user.Setup(DB, router)
-	generic.Setup[models.User](DB, router, "users", *superAdminOpts)
+	user.Setup(DB, images, router)
+	generic.Setup[models.User](DB, router, "users", *usersOpts(user.OnUserChange(DB)))
 	review.Setup(DB, router)
 	
 	cart.Setup(DB,router)
@@ -35,4 +40,8 @@ func SetupAllServices(DB *gorm.DB, router *http.ServeMux) {
 	message.Setup(DB, router)
 	
 	role.Setup(DB, router)
+	apikey.Setup(DB, router)
+	identity.Setup(DB, router)
+	impersonation.Setup(DB, router)
+	auditlog.Setup(DB, router)
 }
\ No newline at end of file
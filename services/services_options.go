@@ -2,27 +2,45 @@ package services
 
 import (
 	"main.go/middlewares"
+	"main.go/services/category"
 	"main.go/services/generic"
+	"main.go/services/image"
+	"main.go/types"
 )
 
-var superAdminOnlyRO = generic.NewRouteOptions(&generic.RouteOptions{
-	IsEnabled:      true,
-	AuthenticateMiddleware: middlewares.Authenticate,
-	AuthorizeMiddleware: &middlewares.AuthorizeSuperAdmin,
-})
+// soft delete, restore and hard delete routes of a resource are authorized by the "<resource>:delete" permission.
+func deletePermissionRO(permission types.Permission) generic.RouteOptions {
+	authorizeMW := middlewares.AuthorizePermission(permission)
+	return generic.NewRouteOptions(&generic.RouteOptions{
+		IsEnabled:              true,
+		AuthenticateMiddleware: middlewares.Authenticate,
+		AuthorizeMiddleware:    &authorizeMW,
+	})
+}
 
-var adminRO = generic.NewRouteOptions(&generic.RouteOptions{
-	IsEnabled:      true,
-	AuthenticateMiddleware: middlewares.Authenticate,
-	AuthorizeMiddleware: &middlewares.AuthorizeAdmin,
-}) 
+// the options are built on setup since the authorization middlewares need the lookups of middlewares.Setup.
+// the images of the products deleted by the cascade are deleted from the storage by the deletions worker.
+func categoriesOpts() *generic.Options {
+	return generic.NewOptions(&generic.Options{
+		SoftDeleteRoutes: deletePermissionRO(types.PermCategoriesDelete),
+		HardDelete:       deletePermissionRO(types.PermCategoriesDelete),
+		BeforeHardDelete: category.BeforeHardDelete,
+	})
+}
 
-var adminRoles = generic.NewOptions(&generic.Options{
-	SoftDeleteRoutes: adminRO,
-	HardDelete:       adminRO,
-})
+func productsOpts() *generic.Options {
+	return generic.NewOptions(&generic.Options{
+		SoftDeleteRoutes: deletePermissionRO(types.PermProductsDelete),
+		HardDelete:       deletePermissionRO(types.PermProductsDelete),
+		BeforeHardDelete: image.EnqueueProductImagesDeletions,
+	})
+}
 
-var superAdminOpts = generic.NewOptions(&generic.Options{
-	SoftDeleteRoutes: superAdminOnlyRO,
-	HardDelete:       superAdminOnlyRO,
-})
+// users are deleted by "DELETE /users/{id}" which anonymizes their data after a grace period, so the generic hard delete is disabled.
+func usersOpts(onChange func(id uint)) *generic.Options {
+	return generic.NewOptions(&generic.Options{
+		SoftDeleteRoutes: deletePermissionRO(types.PermUsersDelete),
+		HardDelete:       generic.RouteOptions{IsEnabled: false},
+		OnChange:         onChange,
+	})
+}
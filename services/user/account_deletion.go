@@ -0,0 +1,181 @@
+package user
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"main.go/config"
+	"main.go/middlewares"
+	"main.go/pkg/models"
+	"main.go/pkg/storage"
+	"main.go/services/auth"
+	"main.go/services/image"
+	"main.go/types"
+)
+
+const deletedUserName = "Deleted User"
+
+// an invalid value stops the api on startup instead of failing the deletion requests.
+func deletionGracePeriod() time.Duration {
+	return config.Envs.ACCOUNT_DELETION_GRACE_PERIOD
+}
+
+// soft deletes the user and schedules the purge after the grace period.
+func (userStore *Store) RequestAccountDeletion(userId uint, purgeAfter time.Time) (*models.AccountDeletion, error) {
+	deletion := &models.AccountDeletion{
+		UserID:      userId,
+		RequestedAt: time.Now(),
+		PurgeAfter:  purgeAfter,
+	}
+
+	err := userStore.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(deletion).Error
+		if err != nil {
+			return err
+		}
+
+		res := tx.Delete(&models.User{}, userId)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("user with id:'%v' was not found", userId)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deletion, nil
+}
+
+// used as the generic users routes hook, restoring a user during the grace period cancels its pending deletion.
+func OnUserChange(DB *gorm.DB) func(id uint) {
+	return func(id uint) {
+		middlewares.InvalidateUserCache(id)
+
+		var user models.User
+		err := DB.Unscoped().First(&user, id).Error
+		if err != nil || user.DeletedAt != nil && user.DeletedAt.Valid {
+			return
+		}
+
+		err = DB.Where("user_id = ? AND completed_at IS NULL", id).Delete(&models.AccountDeletion{}).Error
+		if err != nil {
+			slog.Error("failed to cancel the account deletion", "userId", id, "error", err)
+		}
+	}
+}
+
+// anonymizes the data that must be kept (orders and their addresses, reviews, the audit logs of the user) and hard
+// deletes the rest, the user row is kept as an anonymized tombstone so the orders and reviews keep their owner.
+func purgeAccount(DB *gorm.DB, deletion models.AccountDeletion, now time.Time) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		err := tx.Unscoped().First(&user, deletion.UserID).Error
+		if err != nil {
+			return err
+		}
+
+		// restored without passing by the generic hook
+		if user.DeletedAt == nil || !user.DeletedAt.Valid {
+			return tx.Delete(&deletion).Error
+		}
+
+		ordersAddresses := tx.Model(&models.Order{}).Select("address_id").Where("user_id = ?", user.ID)
+		err = tx.Unscoped().Model(&models.Address{}).
+			Where("user_id = ? AND id IN (?)", user.ID, ordersAddresses).
+			Updates(map[string]any{
+				"full_name":      deletedUserName,
+				"street_address": "REDACTED",
+				"zip_code":       nil,
+				"state":          nil,
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Unscoped().Where("user_id = ? AND id NOT IN (?)", user.ID, ordersAddresses).Delete(&models.Address{}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("user_id = ?", user.ID).Delete(&models.CartItem{}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where(&models.Message{From: user.ID}).Or(&models.Message{To: user.ID}).Delete(&models.Message{}).Error
+		if err != nil {
+			return err
+		}
+
+		for _, model := range []any{&models.Identity{}, &models.ApiKey{}, &models.UserRoles{}} {
+			err = tx.Where("user_id = ?", user.ID).Delete(model).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		err = tx.Where("user_id = ? OR email = ?", user.ID, user.Email).Delete(&models.AccountLockout{}).Error
+		if err != nil {
+			return err
+		}
+
+		// the snapshots of the user (e.g the one of its deletion) hold its name, email and roles
+		err = tx.Model(&models.AuditLog{}).
+			Where("resource_type = ? AND resource_id = ?", "users", user.ID).
+			Update("changes", gorm.Expr("NULL")).Error
+		if err != nil {
+			return err
+		}
+
+		if user.Avatar != nil {
+			publicId, ok := storage.PublicIdFromURL(config.Envs.IMAGE_STORAGE, *user.Avatar, types.UsersFolder)
+			if ok {
+				err = image.EnqueueDeletions(tx, []string{publicId})
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		err = tx.Unscoped().Model(&user).Updates(map[string]any{
+			"name":          deletedUserName,
+			"email":         fmt.Sprintf("deleted-user-%v@deleted.invalid", user.ID),
+			"password":      auth.NoPasswordMarker,
+			"avatar":        nil,
+			"mobile_number": nil,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&deletion).Update("completed_at", now).Error
+	})
+}
+
+// purges the accounts whose grace period is over and returns how many were purged.
+func PurgeDueAccounts(DB *gorm.DB, now time.Time) (int, error) {
+	var deletions []models.AccountDeletion
+	err := DB.Where("completed_at IS NULL AND purge_after <= ?", now).Find(&deletions).Error
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, deletion := range deletions {
+		err = purgeAccount(DB, deletion, now)
+		if err != nil {
+			slog.Error("failed to purge the account", "userId", deletion.UserID, "error", err)
+			continue
+		}
+		purged++
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,75 @@
+package user
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"main.go/config"
+	"main.go/pkg/models"
+	"main.go/pkg/storage"
+	"main.go/pkg/test_utils/testdb"
+	"main.go/types"
+)
+
+func TestPurgeAccount(t *testing.T) {
+	t.Run("Should leave no personal data of the user", func(t *testing.T) {
+		imageStorage := config.Envs.IMAGE_STORAGE
+		config.Envs.IMAGE_STORAGE = storage.Local
+		t.Cleanup(func() { config.Envs.IMAGE_STORAGE = imageStorage })
+
+		DB := testdb.SQLite(t,
+			&models.User{}, &models.Address{}, &models.Order{}, &models.CartItem{}, &models.Message{},
+			&models.Identity{}, &models.ApiKey{}, &models.AccountLockout{}, &models.AuditLog{},
+			&models.StorageDeletion{}, &models.AccountDeletion{},
+		)
+		avatarId := string(types.UsersFolder) + "/me_1a2b.png"
+		avatar := "http://localhost:8080" + storage.LocalRoute + avatarId
+		mobileNumber := "+15555550100"
+		user := models.User{Name: "Jane Doe", Email: "jane@example.com", Password: "hash", Avatar: &avatar, MobileNumber: &mobileNumber}
+		assert.NoError(t, DB.Create(&user).Error)
+		assert.NoError(t, DB.Create(&models.Address{FullName: "Jane Doe", City: "Paris", StreetAddress: "1 rue de Rivoli", Country: "France", UserID: user.ID}).Error)
+		assert.NoError(t, DB.Create(&models.AccountLockout{Email: user.Email, IP: "127.0.0.1", Reason: "account", LockedUntil: time.Now()}).Error)
+		assert.NoError(t, DB.Create(&models.AuditLog{
+			Action:       string(types.AuditDelete),
+			ResourceType: "users",
+			ResourceID:   &user.ID,
+			Changes:      map[string]models.AuditChange{"email": {Before: user.Email}, "name": {Before: user.Name}},
+			Method:       "DELETE",
+			Path:         "/api/v1/users/1",
+			StatusCode:   200,
+			IP:           "127.0.0.1",
+		}).Error)
+
+		store := NewStore(DB)
+		deletion, err := store.RequestAccountDeletion(user.ID, time.Now())
+		assert.NoError(t, err)
+		assert.NoError(t, purgeAccount(DB, *deletion, time.Now()))
+
+		var purged models.User
+		assert.NoError(t, DB.Unscoped().First(&purged, user.ID).Error)
+		assert.Equal(t, deletedUserName, purged.Name)
+		assert.NotEqual(t, user.Email, purged.Email)
+		assert.Nil(t, purged.Avatar)
+		assert.Nil(t, purged.MobileNumber)
+
+		var tables []string
+		assert.NoError(t, DB.Raw("SELECT name FROM sqlite_master WHERE type = 'table'").Scan(&tables).Error)
+		for _, table := range tables {
+			var rows []map[string]any
+			assert.NoError(t, DB.Table(table).Find(&rows).Error)
+			for _, row := range rows {
+				for column, value := range row {
+					for _, pii := range []string{user.Email, user.Name, mobileNumber, "Rivoli", avatar} {
+						assert.NotContains(t, fmt.Sprint(value), pii, "%v.%v", table, column)
+					}
+				}
+			}
+		}
+
+		var deletions []string
+		assert.NoError(t, DB.Model(&models.StorageDeletion{}).Pluck("public_id", &deletions).Error)
+		assert.Equal(t, []string{avatarId}, deletions)
+	})
+}
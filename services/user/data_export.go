@@ -0,0 +1,97 @@
+package user
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+	"main.go/pkg/models"
+	"main.go/types"
+)
+
+// collects everything kept about the user, the soft deleted addresses are included because they are still stored.
+func (userStore *Store) GetUserDataExport(userId uint) (*types.UserDataExport, error) {
+	export := &types.UserDataExport{ExportedAt: time.Now()}
+
+	var user models.User
+	err := userStore.DB.Preload("Roles").First(&user, userId).Error
+	if err != nil {
+		return nil, fmt.Errorf("user with id:'%v' was not found", userId)
+	}
+	export.Profile = &user
+
+	queries := []struct {
+		dest  any
+		query *gorm.DB
+	}{
+		{&export.Addresses, userStore.DB.Unscoped().Where("user_id = ?", userId)},
+		{&export.Orders, userStore.DB.Preload("OrderItems").Preload("Address", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		}).Where("user_id = ?", userId)},
+		{&export.Reviews, userStore.DB.Where("user_id = ?", userId)},
+		{&export.Messages, userStore.DB.Where(&models.Message{From: userId}).Or(&models.Message{To: userId})},
+		{&export.Cart, userStore.DB.Where("user_id = ?", userId)},
+		{&export.Identities, userStore.DB.Where("user_id = ?", userId)},
+	}
+	for _, q := range queries {
+		err = q.query.Order("id").Find(q.dest).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return export, nil
+}
+
+func writeExportJSON(w http.ResponseWriter, export *types.UserDataExport, fileName string) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%v.json"`, fileName))
+	w.WriteHeader(http.StatusOK)
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(export)
+}
+
+// each section of the export is written to its own file inside the archive.
+func writeExportZIP(w http.ResponseWriter, export *types.UserDataExport, fileName string) error {
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%v.zip"`, fileName))
+	w.WriteHeader(http.StatusOK)
+
+	archive := zip.NewWriter(w)
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", export.Profile},
+		{"addresses.json", export.Addresses},
+		{"orders.json", export.Orders},
+		{"reviews.json", export.Reviews},
+		{"messages.json", export.Messages},
+		{"cart.json", export.Cart},
+		{"identities.json", export.Identities},
+	}
+	for _, file := range files {
+		fileWriter, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: export.ExportedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(fileWriter)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(file.data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
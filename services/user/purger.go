@@ -0,0 +1,36 @@
+package user
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// runs PurgeDueAccounts every interval until the context is canceled, wg is done once the running purge returned.
+func StartDeletionPurger(ctx context.Context, wg *sync.WaitGroup, DB *gorm.DB, interval time.Duration) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				purged, err := PurgeDueAccounts(DB, now)
+				if err != nil {
+					slog.Error("failed to purge the deleted accounts", "error", err)
+					continue
+				}
+				if purged > 0 {
+					slog.Info("purged the deleted accounts", "count", purged)
+				}
+			}
+		}
+	}()
+}
@@ -1,44 +1,63 @@
 package user
 
 import (
-	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
 	"main.go/constants"
 	appErrors "main.go/errors"
 	"main.go/internal/websocket"
 	"main.go/middlewares"
+	"main.go/pkg/imaging"
+	"main.go/pkg/models"
 	"main.go/pkg/payloads"
+	"main.go/pkg/storage"
 	"main.go/pkg/utils"
 	"main.go/services/auth"
 	"main.go/types"
 )
 
 type Handler struct {
-	store types.UserStore
+	store  types.UserStore
+	images storage.ImageStorage
+	// read once on setup, the deleted accounts are purged after it
+	gracePeriod time.Duration
 }
 
-func NewHandler(store Store) *Handler {
+func NewHandler(store Store, images storage.ImageStorage) *Handler {
 	return &Handler{
-		store: &store,
+		store:       &store,
+		images:      images,
+		gracePeriod: deletionGracePeriod(),
 	}
 }
 
 var Authenticate = middlewares.Authenticate
-var AuthorizeSuperAdmin = middlewares.AuthorizeSuperAdmin
+var AuthorizePermission = middlewares.AuthorizePermission
+var AuthorizeSelfOrPermission = middlewares.AuthorizeSelfOrPermission
+var BlockImpersonation = middlewares.BlockImpersonation
+var Audit = middlewares.Audit
+
+func (h *Handler) audit(action types.AuditAction) func(next http.HandlerFunc) http.HandlerFunc {
+	return Audit(action, middlewares.AuditResource{Type: "users", IdKey: constants.IdUrlPathKey, Load: h.store.GetAuditSnapshot})
+}
 
 func (h *Handler) RegisterRoutes(router *http.ServeMux) {
 	router.HandleFunc(utils.RoutePath("GET", "/users"), Authenticate(h.GetUserByToken))
 	router.HandleFunc(utils.RoutePath("POST", "/users/login"), h.Login)
 	router.HandleFunc(utils.RoutePath("POST", "/users/sign-up"), h.SignUp)
-	router.HandleFunc(utils.RoutePath("POST", "/users/refresh-token"), Authenticate(h.RefreshToken))
-	router.HandleFunc(utils.RoutePath("PATCH", "/users/{id}/reset-password"), Authenticate(h.ResetPassword))
-	router.HandleFunc(utils.RoutePath("PUT", "/users/{id}/profile"), Authenticate(h.UpdateProfile))
-	router.HandleFunc(utils.RoutePath("POST", "/users/{id}/roles"), Authenticate(AuthorizeSuperAdmin(h.AssignUserRole)))
-	router.HandleFunc(utils.RoutePath("DELETE", "/users/{id}/roles/{roleId}"), Authenticate(AuthorizeSuperAdmin(h.RemoveUserRole)))
+	router.HandleFunc(utils.RoutePath("POST", "/users/refresh-token"), h.RefreshToken)
+	router.HandleFunc(utils.RoutePath("PATCH", "/users/{id}/reset-password"), Authenticate(BlockImpersonation(h.ResetPassword)))
+	router.HandleFunc(utils.RoutePath("PUT", "/users/{id}/profile"), Authenticate(BlockImpersonation(h.UpdateProfile)))
+	router.HandleFunc(utils.RoutePath("POST", "/users/{id}/roles"), Authenticate(AuthorizePermission(types.PermUsersAssignRoles)(h.audit(types.AuditAssignRole)(h.AssignUserRole))))
+	router.HandleFunc(utils.RoutePath("DELETE", "/users/{id}/roles/{roleId}"), Authenticate(AuthorizePermission(types.PermUsersAssignRoles)(h.audit(types.AuditRemoveRole)(h.RemoveUserRole))))
+	router.HandleFunc(utils.RoutePath("POST", "/users/{id}/unlock"), Authenticate(AuthorizePermission(types.PermUsersUnlock)(h.audit(types.AuditUnlock)(h.UnlockUser))))
+	router.HandleFunc(utils.RoutePath("POST", "/users/{id}/export"), Authenticate(BlockImpersonation(AuthorizeSelfOrPermission(constants.IdUrlPathKey, types.PermUsersExport)(h.ExportUserData))))
+	router.HandleFunc(utils.RoutePath("DELETE", "/users/{id}"), Authenticate(BlockImpersonation(AuthorizeSelfOrPermission(constants.IdUrlPathKey, types.PermUsersDelete)(h.audit(types.AuditDelete)(h.DeleteAccount)))))
 }
 
 func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
@@ -49,19 +68,29 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 	loginPayload.TrimStrs()
 
-	user, err := h.store.GetUserByEmail(loginPayload.Email)
+	throttler := middlewares.GetLoginThrottler()
+	retryAfter := throttler.Reserve(r, loginPayload.Email)
+	if retryAfter > 0 {
+		middlewares.WriteTooManyRequests(w, retryAfter)
+		return
+	}
+
+	user, err := h.store.WithContext(r.Context()).GetUserByEmail(loginPayload.Email)
 	if err != nil {
+		h.registerLoginFailure(r, loginPayload.Email, nil)
 		utils.WriteError(w, http.StatusBadRequest, appErrors.ErrWrongPWOrEmail)
 		return
 	}
 	isEqual := auth.ComparePassword(user.Password, []byte(loginPayload.Password))
 	if !isEqual {
+		h.registerLoginFailure(r, loginPayload.Email, &user.ID)
 		utils.WriteError(w, http.StatusBadRequest, appErrors.ErrWrongPWOrEmail)
 		return
 	}
+	throttler.RegisterSuccess(r, loginPayload.Email)
 
 	// generate and set the cookie
-	_, _, err = auth.GenerateAndSetTokens(*user, w, r)
+	accessToken, refreshToken, err := auth.GenerateAndSetTokens(*user, w, r)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
@@ -69,10 +98,37 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 
 	otp := websocket.GlobalManager.Otps.NewOTP()
 
-	utils.WriteJSON(w, http.StatusCreated, map[string]any{
+	resp := map[string]any{
 		"user":  user,
 		"otp":   otp.Key,
-	})
+	}
+	if loginPayload.ReturnTokens {
+		resp["tokens"] = map[string]any{
+			"accessToken":  accessToken,
+			"refreshToken": refreshToken,
+			"tokenType":    "Bearer",
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, resp)
+}
+
+// the lockout is audited, failing to save the audit entry must not change the login response.
+func (h *Handler) registerLoginFailure(r *http.Request, email string, userId *uint) {
+	lockouts := middlewares.GetLoginThrottler().RegisterFailure(r, email)
+	for _, lockout := range lockouts {
+		err := h.store.WithContext(r.Context()).CreateAccountLockout(&models.AccountLockout{
+			Email:       lockout.Email,
+			UserID:      userId,
+			IP:          lockout.IP,
+			Reason:      lockout.Reason,
+			Failures:    lockout.Failures,
+			LockedUntil: lockout.LockedUntil,
+		})
+		if err != nil {
+			slog.ErrorContext(r.Context(), "failed to save the account lockout", "error", err)
+		}
+	}
 }
 
 func (h *Handler) SignUp(w http.ResponseWriter, r *http.Request) {
@@ -84,7 +140,7 @@ func (h *Handler) SignUp(w http.ResponseWriter, r *http.Request) {
 	signUpPayload.TrimStrs()
 	signUpPayload.Email = strings.ToLower(signUpPayload.Email)
 	// hash password and create user
-	user, err := h.store.CreateUser(*signUpPayload)
+	user, err := h.store.WithContext(r.Context()).CreateUser(*signUpPayload)
 	if err != nil {
 		if utils.IsDuplicateKeyErr(err) {
 			utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("user with this email already existing"))
@@ -126,7 +182,7 @@ func (h *Handler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.store.GetUserByEmail(*email)
+	user, err := h.store.WithContext(r.Context()).GetUserByEmail(*email)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
@@ -144,11 +200,12 @@ func (h *Handler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.store.UpdatePassword(hashedPW, *email)
+	err = h.store.WithContext(r.Context()).UpdatePassword(hashedPW, *email)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
+	middlewares.InvalidateUserCache(user.ID)
 
 	_, _, err = auth.GenerateAndSetTokens(*user, w, r)
 	if err != nil {
@@ -193,8 +250,11 @@ func (h *Handler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 
 	model := upPayload.ToModel()
 	if file != nil {
-		imgHandler := utils.NewImagesHandler()
-		upResult, err := imgHandler.UploadOne(&file, fileHeader, types.UsersFolder, context.Background())
+		upResult, err := h.images.Upload(r.Context(), file, fileHeader.Filename, types.UsersFolder)
+		if errors.Is(err, imaging.ErrInvalidImage) {
+			utils.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
 		if err != nil {
 			utils.WriteError(w, http.StatusInternalServerError, appErrors.ErrUnexpectedDuringImageUpload)
 			return
@@ -204,11 +264,12 @@ func (h *Handler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 		upPayload.Avatar = upResult.URL
 	}
 
-	user, err := h.store.UpdateProfile(*userId, model, upPayload)
+	user, err := h.store.WithContext(r.Context()).UpdateProfile(*userId, model, upPayload)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
 	}
+	middlewares.InvalidateUserCache(*userId)
 	user.ID = *userId
 
 	utils.WriteJSON(w, http.StatusAccepted, map[string]any{
@@ -229,7 +290,7 @@ func (h *Handler) AssignUserRole(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userRole, err := h.store.AssignUserRole(rPayload.RoleId, *Id)
+	userRole, err := h.store.WithContext(r.Context()).AssignUserRole(rPayload.RoleId, *Id)
 	if err != nil {
 		if utils.IsDuplicateKeyErr(err) {
 			utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("user already has this role"))
@@ -238,6 +299,7 @@ func (h *Handler) AssignUserRole(w http.ResponseWriter, r *http.Request) {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
 	}
+	middlewares.InvalidateUserCache(*Id)
 
 	utils.WriteJSON(w, http.StatusAccepted, map[string]any{"userRole": userRole})
 }
@@ -255,15 +317,100 @@ func (h *Handler) RemoveUserRole(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.store.RemoveUserRole(*roleId, *Id)
+	err = h.store.WithContext(r.Context()).RemoveUserRole(*roleId, *Id)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
 	}
+	middlewares.InvalidateUserCache(*Id)
 
 	utils.WriteJSON(w, http.StatusNoContent, map[string]any{})
 }
 
+func (h *Handler) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	Id, receivedStr,err := utils.GetValidateId(r, constants.IdUrlPathKey)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, appErrors.NewInvalidIDError("user", receivedStr))
+		return
+	}
+
+	adminId, err := utils.GetUserIdCtx(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, appErrors.ErrGenericMessage)
+		return
+	}
+
+	user, err := h.store.WithContext(r.Context()).UnlockAccount(*Id, *adminId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	middlewares.GetLoginThrottler().UnlockAccount(user.Email)
+
+	utils.WriteJSON(w, http.StatusOK, map[string]any{
+		"message": "user was unlocked successfully",
+	})
+}
+
+// the format is selected by the "format" query param, "json" (default) or "zip".
+func (h *Handler) ExportUserData(w http.ResponseWriter, r *http.Request) {
+	Id, receivedStr,err := utils.GetValidateId(r, constants.IdUrlPathKey)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, appErrors.NewInvalidIDError("user", receivedStr))
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "zip" {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("format must be one of: 'json', 'zip'"))
+		return
+	}
+
+	export, err := h.store.WithContext(r.Context()).GetUserDataExport(*Id)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	fileName := fmt.Sprintf("user-%v-data", *Id)
+	if format == "zip" {
+		err = writeExportZIP(w, export, fileName)
+	} else {
+		err = writeExportJSON(w, export, fileName)
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to write the user data export", "error", err)
+	}
+}
+
+// the user is soft deleted right away and purged after the grace period, restoring the user before that cancels the deletion.
+func (h *Handler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	Id, receivedStr,err := utils.GetValidateId(r, constants.IdUrlPathKey)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, appErrors.NewInvalidIDError("user", receivedStr))
+		return
+	}
+
+	deletion, err := h.store.WithContext(r.Context()).RequestAccountDeletion(*Id, time.Now().Add(h.gracePeriod))
+	if err != nil {
+		if utils.IsDuplicateKeyErr(err) {
+			utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("the deletion of this account was already requested"))
+			return
+		}
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	middlewares.InvalidateUserCache(*Id)
+
+	utils.WriteJSON(w, http.StatusAccepted, map[string]any{
+		"message":    "the account was deleted and its data will be purged after the grace period",
+		"purgeAfter": deletion.PurgeAfter,
+	})
+}
+
 func (h *Handler) GetUserByToken(w http.ResponseWriter, r *http.Request) {
 	user, err := utils.GetUserCtx(r)
 	if err != nil {
@@ -280,19 +427,20 @@ func (h *Handler) GetUserByToken(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) RefreshToken(w http.ResponseWriter, r *http.Request) {
-	user, err := utils.GetUserCtx(r)
+	refreshToken, source, err := auth.GetRefreshToken(r)
 	if err != nil {
-		utils.WriteError(w, http.StatusInternalServerError, appErrors.ErrGenericMessage)
+		auth.Unauthorized(w)
 		return
 	}
-	refreshToken, err := auth.GetRefreshToken(r)
-	if err != nil {
-		utils.WriteError(w, http.StatusInternalServerError, appErrors.ErrGenericMessage)
-		return
+	if source == auth.CookieTokenSource {
+		if err := auth.ValidateCSRF(r); err != nil {
+			auth.DenyPermission(w)
+			return
+		}
 	}
 
 	// here user must sign in again because refresh token is invalid/expired
-	validatedRefToken, err := auth.ValidateToken(refreshToken)
+	validatedRefToken, err := auth.ValidateToken(refreshToken, auth.RefreshTokenType)
 	if err != nil {
 		auth.Unauthorized(w)
 		return
@@ -303,9 +451,27 @@ func (h *Handler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 		auth.Unauthorized(w)
 		return
 	}
-	// checking user id in refresh token and access token, both must be equal
-	if *userId != user.ID {
-		auth.DenyPermission(w)
+	// the deleted users can not refresh their tokens
+	user, err := h.store.WithContext(r.Context()).GetUserById(*userId)
+	if err != nil {
+		auth.Unauthorized(w)
+		return
+	}
+
+	// bearer clients do not use the cookie, the new access token is returned inside the body instead
+	if source == auth.BearerTokenSource {
+		accessToken, err := auth.GenerateAccessToken(user)
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, appErrors.ErrGenericMessage)
+			return
+		}
+
+		utils.WriteJSON(w, http.StatusOK, map[string]any{
+			"tokens": map[string]any{
+				"accessToken": accessToken,
+				"tokenType":   "Bearer",
+			},
+		})
 		return
 	}
 
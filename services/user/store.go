@@ -1,7 +1,9 @@
 package user
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 	"main.go/constants"
@@ -26,6 +28,10 @@ func NewStore(DB *gorm.DB) *Store {
 	}
 }
 
+func (userStore *Store) WithContext(ctx context.Context) types.UserStore {
+	return NewStore(userStore.DB.WithContext(ctx))
+}
+
 func (userStore *Store) GetUserById(Id uint) (*models.User, error) {
 	notFoundMsg := "user with id: '%v' is not found"
 	user, err := userStore.Generic.GetOne(Id, notFoundMsg)
@@ -165,4 +171,31 @@ func (userStore *Store) AssignUserRole(roleId, userId uint) (*models.UserRoles,
 	userRole.User = user
 
 	return &userRole, nil
-}
\ No newline at end of file
+}
+
+func (userStore *Store) CreateAccountLockout(lockout *models.AccountLockout) error {
+	return userStore.DB.Create(lockout).Error
+}
+
+// marks the active lockouts of the user as unlocked, the limiter itself is reset by the handler.
+func (userStore *Store) UnlockAccount(userId, unlockedById uint) (*models.User, error) {
+	user, err := userStore.GetUserById(userId)
+	if err != nil {
+		return nil, fmt.Errorf("user with id:'%v' was not found", userId)
+	}
+
+	now := time.Now()
+	err = userStore.DB.Model(&models.AccountLockout{}).
+		Where("email = ? AND reason = ? AND unlocked_at IS NULL AND locked_until > ?", user.Email, types.LockoutReasonAccount, now).
+		Updates(map[string]any{"unlocked_at": now, "unlocked_by_id": unlockedById}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// the roles are included so assigning and removing roles shows up in the changes.
+func (userStore *Store) GetAuditSnapshot(ctx context.Context, id uint) (any, error) {
+	return userStore.Generic.GetAuditSnapshot(ctx, id, "Roles")
+}
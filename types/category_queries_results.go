@@ -0,0 +1,7 @@
+package types
+
+// an ancestor of a category, the breadcrumbs go from the root down to the category.
+type CategoryBreadcrumb struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
@@ -1,10 +1,9 @@
 package types
 
 import (
-	"context"
-	"mime/multipart"
-	"net/http"
+	"time"
 
+	"main.go/pkg/models"
 )
 
 type Folder string
@@ -14,14 +13,6 @@ const (
 	UsersFolder    Folder = "golang-shop/users"
 )
 
-
-type IImagesHandler interface {
-	UploadOne(file *multipart.File, folder Folder) (*UploadResponse, error)
-	UploadMany(r *http.Request, folder Folder, keyName string, ctx context.Context) ([]*UploadResponse, []error)
-	DeleteOne(file *multipart.File, folder Folder) error
-	DeleteMany(PublicIDs []string, ctx context.Context) (*DeleteResponse, error)
-}
-
 type UploadResponse struct {
 	Width     int
 	Height    int
@@ -29,13 +20,15 @@ type UploadResponse struct {
 	PublicID  string
 	URL       string
 	Format    string
+	// set for the images processed before their upload.
+	Variants models.ImageVariants
 }
 
-type DeleteManyResponse struct {
-	DeletedCounts map[string]interface{}
+// the form of a direct upload to the storage: the client posts a multipart form to URL with the Fields then the file
+// in the "file" field.
+type SignedUpload struct {
+	PublicId  string            `json:"publicId"`
+	URL       string            `json:"url"`
+	Fields    map[string]string `json:"fields"`
+	ExpiresAt time.Time         `json:"expiresAt"`
 }
-
-// TODO : resolving the issue with cloudinary delete response type
-type DeleteResponse struct {
-	result string
-}
\ No newline at end of file
@@ -0,0 +1,96 @@
+package types
+
+import "strings"
+
+// permissions are named as "resource:action", they are assigned to roles and checked by the authorization middleware,
+// so custom roles created by SuperAdmins get only what they are assigned.
+type Permission string
+
+const (
+	PermCategoriesCreate Permission = "categories:create"
+	PermCategoriesUpdate Permission = "categories:update"
+	PermCategoriesDelete Permission = "categories:delete"
+
+	PermProductsCreate Permission = "products:create"
+	PermProductsUpdate Permission = "products:update"
+	PermProductsDelete Permission = "products:delete"
+	PermProductsExport Permission = "products:export"
+	PermProductsImport Permission = "products:import"
+
+	PermImagesCreate Permission = "images:create"
+	PermImagesUpdate Permission = "images:update"
+	PermImagesDelete Permission = "images:delete"
+
+	PermOrdersUpdate Permission = "orders:update"
+
+	PermReviewsRead  Permission = "reviews:read"
+	PermMessagesRead Permission = "messages:read"
+
+	PermUsersDelete      Permission = "users:delete"
+	PermUsersAssignRoles Permission = "users:assign-roles"
+	PermUsersUnlock      Permission = "users:unlock"
+	PermUsersExport      Permission = "users:export"
+	PermUsersImpersonate Permission = "users:impersonate"
+
+	PermRolesRead              Permission = "roles:read"
+	PermRolesCreate            Permission = "roles:create"
+	PermRolesUpdate            Permission = "roles:update"
+	PermRolesDelete            Permission = "roles:delete"
+	PermRolesAssignPermissions Permission = "roles:assign-permissions"
+	PermPermissionsRead        Permission = "permissions:read"
+
+	PermApiKeysManage Permission = "api-keys:manage"
+	PermAuditLogsRead Permission = "audit-logs:read"
+)
+
+var adminPermissions = []Permission{
+	PermCategoriesCreate, PermCategoriesUpdate, PermCategoriesDelete,
+	PermProductsCreate, PermProductsUpdate, PermProductsDelete, PermProductsExport, PermProductsImport,
+	PermImagesCreate, PermImagesUpdate, PermImagesDelete,
+	PermOrdersUpdate, PermReviewsRead, PermMessagesRead,
+	PermUsersUnlock,
+}
+
+var superAdminOnlyPermissions = []Permission{
+	PermUsersDelete, PermUsersAssignRoles, PermUsersExport, PermUsersImpersonate,
+	PermRolesRead, PermRolesCreate, PermRolesUpdate, PermRolesDelete,
+	PermRolesAssignPermissions, PermPermissionsRead, PermApiKeysManage, PermAuditLogsRead,
+}
+
+// all the permissions known by the application, they are seeded on start.
+func AllPermissions() []Permission {
+	all := make([]Permission, 0, len(adminPermissions)+len(superAdminOnlyPermissions))
+	all = append(all, adminPermissions...)
+	return append(all, superAdminOnlyPermissions...)
+}
+
+// the roles that get the permission when its seeded for the first time, after that the assignments are managed through the roles endpoints.
+func DefaultRolesOfPermission(permission Permission) []UserRole {
+	for _, p := range adminPermissions {
+		if p == permission {
+			return []UserRole{SuperAdmin, Admin}
+		}
+	}
+
+	return []UserRole{SuperAdmin}
+}
+
+// api keys have coarse scopes, a "resource:write" scope covers every non read action of the resource.
+func (p *Principal) CanUsePermission(permission Permission) bool {
+	if p == nil {
+		return false
+	}
+
+	resource, action, _ := strings.Cut(string(permission), ":")
+	for _, scope := range p.Scopes {
+		scopeResource, scopeAction, _ := strings.Cut(scope, ":")
+		if scopeResource != resource {
+			continue
+		}
+		if scopeAction == action || (scopeAction == "write" && action != "read") {
+			return true
+		}
+	}
+
+	return false
+}
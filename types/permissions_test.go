@@ -0,0 +1,35 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanUsePermission(t *testing.T) {
+	tests := []struct {
+		name       string
+		scopes     []string
+		permission Permission
+		expected   bool
+	}{
+		{name: "Should allow the permission of the same action", scopes: []string{"products:export"}, permission: PermProductsExport, expected: true},
+		{name: "Should map the write scope onto the delete permission", scopes: []string{"products:write"}, permission: PermProductsDelete, expected: true},
+		{name: "Should map the write scope onto the create permission", scopes: []string{"products:write"}, permission: PermProductsCreate, expected: true},
+		{name: "Should not map the write scope onto the read permission", scopes: []string{"reviews:write"}, permission: PermReviewsRead},
+		{name: "Should not map the scope onto another resource", scopes: []string{"products:write"}, permission: PermCategoriesDelete},
+		{name: "Should refuse a key without scopes", permission: PermProductsCreate},
+		{name: "Should check every scope", scopes: []string{"orders:read", "categories:write"}, permission: PermCategoriesUpdate, expected: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			principal := &Principal{UserID: 1, ApiKeyID: new(uint), Scopes: test.scopes}
+			assert.Equal(t, test.expected, principal.CanUsePermission(test.permission))
+		})
+	}
+
+	t.Run("Should refuse a nil principal", func(t *testing.T) {
+		var principal *Principal
+		assert.False(t, principal.CanUsePermission(PermProductsCreate))
+	})
+}
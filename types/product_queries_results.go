@@ -1,12 +1,19 @@
 package types
 
-import "time"
+import (
+	"time"
+
+	"main.go/pkg/models"
+)
 
 // ** Get One Product By Id
 
 type RespGetOneProductShape struct {
 	ID           uint                     `json:"id"`
 	Name         string                   `json:"name"`
+	SKU          string                   `json:"sku"`
+	Barcode      *string                  `json:"barcode"`
+	Slug         string                   `json:"slug"`
 	Description  *string                  `json:"description"`
 	Quantity     uint                     `json:"quantity"`
 	Price        float64                  `json:"price"`
@@ -21,6 +28,9 @@ type RespGetOneProductShape struct {
 type RowGetProductById struct {
 	ID              uint
 	Name            string
+	SKU             string
+	Barcode         *string
+	Slug            string
 	Description     *string
 	Quantity        uint
 	Price           float64
@@ -39,6 +49,8 @@ type RowGetProductById struct {
 	ImageUrl        string
 	ImagePublicId   string
 	IsMain          bool
+	ImagePosition   int
+	ImageAltText    string
 	UserName        string
 	UserEmail       string
 	UserAvatar      string
@@ -58,6 +70,8 @@ type RowGetOneProductImage struct {
 	ImageUrl      string `json:"imageUrl"`
 	ImagePublicId string `json:"imagePublicId"`
 	IsMain        bool   `json:"isMain"`
+	Position      int    `json:"position"`
+	AltText       string `json:"altText"`
 }
 
 type RowProductCategory struct {
@@ -75,6 +89,8 @@ type RowProductReviewUser struct {
 type GetAllProductsRow struct {
 	Id            uint
 	Name          string
+	SKU           string
+	Slug          string
 	Quantity      uint
 	Description   string
 	CategoryId    uint
@@ -90,6 +106,8 @@ type GetAllProductsRow struct {
 type RespGetAllProductsShape struct {
 	Id          uint      `json:"id"`
 	Name        string    `json:"name"`
+	SKU         string    `json:"sku"`
+	Slug        string    `json:"slug"`
 	Quantity    uint      `json:"quantity"`
 	Description string    `json:"description"`
 	CategoryId  uint      `json:"categoryId"`
@@ -104,3 +122,42 @@ type RowGetAllProductsImage struct {
 	ImageUrl      string `json:"imageUrl"`
 	ImagePublicId string `json:"imagePublicId"`
 }
+
+// ** Products CSV
+
+// a product of the export, its stock is its quantity.
+type RowProductExport struct {
+	ID           uint
+	SKU          string
+	Barcode      *string
+	Name         string
+	Description  *string
+	Price        float64
+	Quantity     uint
+	CategoryID   uint
+	CategoryName string
+}
+
+// a parsed and validated row of the import.
+type ProductImportRow struct {
+	Line    int
+	Product *models.Product
+}
+
+// the ids of the created and updated products are only set when the file is imported.
+type ProductsImportReport struct {
+	DryRun     bool                 `json:"dryRun"`
+	Rows       int                  `json:"rows"`
+	Created    int                  `json:"created"`
+	Updated    int                  `json:"updated"`
+	CreatedIds []uint               `json:"createdIds,omitempty"`
+	UpdatedIds []uint               `json:"updatedIds,omitempty"`
+	Errors     []ProductImportError `json:"errors"`
+}
+
+// the line of the row in the file, the header is the line 1.
+type ProductImportError struct {
+	Line  int    `json:"line"`
+	SKU   string `json:"sku,omitempty"`
+	Error string `json:"error"`
+}
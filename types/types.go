@@ -2,6 +2,7 @@ package types
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"net"
 	"net/http"
@@ -11,6 +12,7 @@ import (
 	"gorm.io/gorm"
 	"main.go/pkg/models"
 	"main.go/pkg/payloads"
+	"main.go/services/auth/oidc"
 )
 
 type GenericRepository[TModel any] interface {
@@ -32,10 +34,14 @@ type GenericRepository[TModel any] interface {
 }
 
 type CategoryStore interface {
+	WithContext(ctx context.Context) CategoryStore
 	GetCategoryById(Id uint) (*models.Category, error)
 	GetAllCategories(page, limit int) ([]models.Category, int64, error)
 	CreateCategory(category *models.Category) (*models.Category, error)
 	UpdateCategory(id uint, category *models.Category) (*models.Category, error)
+	MoveCategory(id uint, parentId *uint) (*models.Category, error)
+	GetBreadcrumbs(category *models.Category) ([]CategoryBreadcrumb, error)
+	GetAuditSnapshot(ctx context.Context, id uint) (any, error)
 }
 
 type ProductAmountDiscounter interface {
@@ -44,6 +50,7 @@ type ProductAmountDiscounter interface {
 }
 
 type OrderStore interface {
+	WithContext(ctx context.Context) OrderStore
 	GetPopulatedOrderById(Id uint) ([]GetOneOrderRow, error)
 	CreateOrder(tx *gorm.DB, order *models.Order) error
 	GetAllOrders(page, limit int) ([]models.Order, int64, error)
@@ -61,18 +68,27 @@ type OrderStore interface {
 	ExtractProductIds(cart []models.CartItem) []uint
 	UpdateProductQtys(tx *gorm.DB,orderId uint) ([]ProductAmountDiscounter, error)
 	GetOrderItems(orderId uint) ([]models.OrderItem, error)
+	GetAuditSnapshot(ctx context.Context, id uint) (any, error)
 }
 
 type ProductStore interface {
+	WithContext(ctx context.Context) ProductStore
 	GetProductById(Id uint) ([]RowGetProductById, error)
 	GetAllProducts(page, limit int, filter func(db *gorm.DB, filters []FilterCondition) ([]models.Product, error)) ([]models.Product, int64, error)
 	CreateProduct(product *models.Product) (*models.Product, error)
 	UpdateProduct(id uint, changes *models.Product, excluder Excluder) (*models.Product, error)
 	CreateImageTx(tx *gorm.DB, uploadResp *UploadResponse, productId uint, isMain bool) (*models.Image, error)
 	CreateProductWithImage(product *models.Product, uploadResp *UploadResponse) (*models.Product, error)
+	ExportProducts(ctx context.Context, write func(row *RowProductExport) error) error
+	ImportProducts(rows []ProductImportRow, dryRun bool) (*ProductsImportReport, error)
+	GetCategoryIdsByName() (map[string][]uint, error)
+	GetProductIdBySKU(sku string) (uint, error)
+	GetProductIdBySlug(slug string) (uint, string, error)
+	GetAuditSnapshot(ctx context.Context, id uint) (any, error)
 }
 
 type UserStore interface {
+	WithContext(ctx context.Context) UserStore
 	GetUserById(Id uint) (*models.User, error)
 	GetUserWithRolesById(Id uint) (*models.User, error)
 	GetUserByEmail(email string) (*models.User, error)
@@ -81,9 +97,15 @@ type UserStore interface {
 	UpdateProfile(id uint, user *models.User, excluder Excluder) (*models.User, error)
 	RemoveUserRole(roleId, userId uint) (error)
 	AssignUserRole(roleId, userId uint) (*models.UserRoles, error)
+	CreateAccountLockout(lockout *models.AccountLockout) error
+	UnlockAccount(userId, unlockedById uint) (*models.User, error)
+	GetUserDataExport(userId uint) (*UserDataExport, error)
+	RequestAccountDeletion(userId uint, purgeAfter time.Time) (*models.AccountDeletion, error)
+	GetAuditSnapshot(ctx context.Context, id uint) (any, error)
 }
 
 type ReviewStore interface {
+	WithContext(ctx context.Context) ReviewStore
 	GetReviewById(Id uint) (*models.Review, error)
 	GetAllReviews(page, limit int) ([]models.Review, int64, error)
 	UpdateReview(id,userId uint, updatePayload *models.Review,excluder Excluder) (*models.Review, error)
@@ -93,32 +115,53 @@ type ReviewStore interface {
 }
 
 type ImageStore interface {
+	WithContext(ctx context.Context) ImageStore
 	GetImageById(id uint) (*models.Image, error)
 	CreateImage(image *models.Image) (*models.Image, error)
-	UpdateImageUrl(id uint, newImageUrl string) (error)
+	UpdateImageFile(id uint, upResult *UploadResponse) error
 	GetCountOfProductImages(productId uint) (*int64, error)
 	GetProductById(productId uint) (*models.Product, error)
 	DeleteImageById(id uint) error
 	SetImageAsMainTx(tx *gorm.DB, id, productId uint) error
 	SetImageAsNotMainTx(tx *gorm.DB, productId uint) error
 	SwapMainStatus(id, productId uint) error
-	CreateManyImages(uploadResults []*UploadResponse, productId *uint) ([]models.Image, error)
+	CreateManyImages(uploadResults []*UploadResponse, altTexts []string, productId *uint) ([]models.Image, error)
+	ReorderImages(productId uint, imageIds []uint) error
+	UpdateImageAltText(id, productId uint, altText string) (*models.Image, error)
+	CreatePendingUploads(productId uint, uploads []*SignedUpload, confirmWindow time.Duration) error
+	GetCountOfPendingUploads(productId uint, now time.Time) (int64, error)
+	GetPendingUploads(productId uint, publicIds []string, now time.Time) ([]models.PendingUpload, error)
+	DiscardPendingUpload(publicId string) error
+	ConfirmUploads(productId uint, uploadResults []*UploadResponse, altTexts []string) ([]models.Image, error)
+	// the uploaded files are deleted when their rows could not be created.
+	DiscardUploads(uploadResults []*UploadResponse)
+	GetAuditSnapshot(ctx context.Context, id uint) (any, error)
+	GetProductImagesAuditSnapshot(ctx context.Context, productId uint) (any, error)
+	GetPendingUploadsAuditSnapshot(ctx context.Context, productId uint) (any, error)
 }
 
 type RolesStore interface {
+	WithContext(ctx context.Context) RolesStore
 	GetRole(id uint) (*models.Role, error)
 	GetAllRoles(page, limit int) ([]models.Role, int64, error)
 	CreateRole(role *models.Role) (*models.Role, error)
 	UpdateRole(id uint, role *models.Role) (*models.Role, error)
 	DeleteRole(id uint) error
+	GetAllPermissions() ([]models.Permission, error)
+	GetRolePermissions(roleId uint) ([]models.Permission, error)
+	AssignRolePermissions(roleId uint, permissionIds []uint) ([]models.Permission, error)
+	RemoveRolePermission(roleId, permissionId uint) error
+	GetAuditSnapshot(ctx context.Context, id uint) (any, error)
 }
 
 type MessageStore interface {
+	WithContext(ctx context.Context) MessageStore
 	GetById(userId uint, lastMessageId uint, cursor time.Time, limit int) ([]*models.Message, error)
 	GetByUsersIds(userId uint, to uint, lastMessageId uint, cursor time.Time, limit int) ([]*models.Message, error)
 }
 
 type CartStore interface {
+	WithContext(ctx context.Context) CartStore
 	GetCartItemById(Id uint) (*models.CartItem, error)
 	ChangeCartItemQty(oldQty uint, payload *payloads.ChangeCartItemQty, cartItem *models.CartItem) (*models.CartItem, error)
 	GetCartByUserId(userId uint) (*RespCartShape, error)
@@ -128,6 +171,7 @@ type CartStore interface {
 }
 
 type AddressStore interface {
+	WithContext(ctx context.Context) AddressStore
 	GetById(id uint, userId uint) (*models.Address, error)
 	GetAddressById(id uint) (*models.Address, error)
 	CreateAddress(address *models.Address) (*models.Address, error)
@@ -137,6 +181,46 @@ type AddressStore interface {
 	GetUndeletedAddressesCount(userId uint) (*int64, error)
 }
 
+type ApiKeyStore interface {
+	WithContext(ctx context.Context) ApiKeyStore
+	GetAllApiKeys(page, limit int) ([]models.ApiKey, int64, error)
+	CreateApiKey(apiKey *models.ApiKey) (*models.ApiKey, error)
+	RevokeApiKey(id uint) (*models.ApiKey, error)
+	GetUserById(id uint) (*models.User, error)
+	GetAuditSnapshot(ctx context.Context, id uint) (any, error)
+}
+
+type AuditLogStore interface {
+	WithContext(ctx context.Context) AuditLogStore
+	GetAllAuditLogs(page, limit int, filter *AuditLogFilter) ([]models.AuditLog, int64, error)
+}
+
+type ImpersonationStore interface {
+	WithContext(ctx context.Context) ImpersonationStore
+	GetUserById(id uint) (*models.User, error)
+	CreateSession(session *models.ImpersonationSession) (*models.ImpersonationSession, error)
+	GetAllSessions(page, limit int) ([]models.ImpersonationSession, int64, error)
+	GetSessionRequests(sessionId uint, page, limit int) ([]models.ImpersonationRequest, int64, error)
+	EndSession(id uint) (*models.ImpersonationSession, error)
+	GetAuditSnapshot(ctx context.Context, id uint) (any, error)
+}
+
+type IdentityStore interface {
+	WithContext(ctx context.Context) IdentityStore
+	GetIdentityById(id uint) (*models.Identity, error)
+	GetUserIdentities(userId uint) ([]models.Identity, error)
+	LinkIdentity(userId uint, provider string, claims *oidc.Claims) (*models.Identity, error)
+	FindOrCreateUser(provider string, claims *oidc.Claims) (*models.User, error)
+	UnlinkIdentity(identity *models.Identity) error
+}
+
+// the reasons of the account lockouts, the account is locked after too many failed logins to it and the ip after
+// too many failed logins from it.
+const (
+	LockoutReasonAccount = "account"
+	LockoutReasonIP      = "ip"
+)
+
 type TokenPayload struct {
 	Email     string `json:"email"`
 	UserId    int    `json:"userId"`
@@ -152,6 +236,8 @@ type TokenKey string
 type UserRole string
 type UserKey string
 type AuthorizedResource string
+type AuthSourceKey string
+type PrincipalKey string
 
 const (
 	SuperAdmin  UserRole = "SuperAdmin"
@@ -159,6 +245,83 @@ const (
 	RegularUser UserRole = "RegularUser"
 )
 
+// scopes are granted to api keys, they are used by machine clients (e.g. the warehouse system) that have no human login.
+type Scope string
+
+const (
+	ScopeProductsRead  Scope = "products:read"
+	ScopeProductsWrite Scope = "products:write"
+	ScopeOrdersRead    Scope = "orders:read"
+	ScopeOrdersWrite   Scope = "orders:write"
+)
+
+// the action saved in the audit logs, routes that are not a plain create, update or delete use their own action (e.g "assign-permissions").
+type AuditAction string
+
+const (
+	AuditCreate     AuditAction = "create"
+	AuditUpdate     AuditAction = "update"
+	AuditDelete     AuditAction = "delete"
+	AuditSoftDelete AuditAction = "soft-delete"
+	AuditRestore    AuditAction = "restore"
+	AuditHardDelete AuditAction = "hard-delete"
+	AuditImport     AuditAction = "import"
+
+	AuditUpdateStatus       AuditAction = "update-status"
+	AuditAssignRole         AuditAction = "assign-role"
+	AuditRemoveRole         AuditAction = "remove-role"
+	AuditUnlock             AuditAction = "unlock"
+	AuditAssignPermissions  AuditAction = "assign-permissions"
+	AuditRemovePermission   AuditAction = "remove-permission"
+	AuditSetMainImage       AuditAction = "set-main-image"
+	AuditReorderImages      AuditAction = "reorder-images"
+	AuditCreateUploadURLs   AuditAction = "create-upload-urls"
+	AuditRevoke             AuditAction = "revoke"
+	AuditStartImpersonation AuditAction = "start-impersonation"
+	AuditEndImpersonation   AuditAction = "end-impersonation"
+)
+
+// the optional filters of the audit logs listing.
+type AuditLogFilter struct {
+	ActorID      *uint
+	Action       string
+	ResourceType string
+	ResourceID   *uint
+	From         *time.Time
+	To           *time.Time
+}
+
+// the identity of the authenticated request, ApiKeyID is nil when the request is authenticated by a user token.
+type Principal struct {
+	UserID   uint
+	ApiKeyID *uint
+	Scopes   []string
+	// set when the request is made by an admin impersonating the user.
+	ImpersonatorID         *uint
+	ImpersonationSessionID *uint
+}
+
+func (p *Principal) IsImpersonated() bool {
+	return p != nil && p.ImpersonatorID != nil
+}
+
+func (p *Principal) IsApiKey() bool {
+	return p != nil && p.ApiKeyID != nil
+}
+
+func (p *Principal) HasScope(scope Scope) bool {
+	if p == nil {
+		return false
+	}
+	for _, s := range p.Scopes {
+		if s == string(scope) {
+			return true
+		}
+	}
+
+	return false
+}
+
 type Pagination struct {
 	Page  int
 	Limit int
@@ -172,8 +335,9 @@ type FilterCondition struct {
 
 // this has been created instead of the user store to solve an import cycle
 type IUserFetcher interface {
-	GetUserById(Id uint) (*models.User, error)
-	GetUserRolesByUserId(Id uint) ([]models.UserRoles, error)
+	GetUserById(ctx context.Context, Id uint) (*models.User, error)
+	GetUserRolesByUserId(ctx context.Context, Id uint) ([]models.UserRoles, error)
+	GetUserPermissions(ctx context.Context, Id uint) ([]Permission, error)
 }
 
 type SortCondition struct {
@@ -203,6 +367,13 @@ type SoftDeletable interface {
 type AppResponse struct {
     http.ResponseWriter
     StatusCode int
+    BytesWritten int
+}
+
+func (ar *AppResponse) Write(data []byte) (int, error) {
+    written, err := ar.ResponseWriter.Write(data)
+    ar.BytesWritten += written
+    return written, err
 }
 
 func (ar *AppResponse) WriteHeader(statusCode int) {
@@ -218,6 +389,17 @@ func (w *AppResponse) Hijack() (net.Conn, *bufio.ReadWriter, error) {
     return hijacker.Hijack()
 }
 
+func (w *AppResponse) Flush() {
+    if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+        flusher.Flush()
+    }
+}
+
+// lets http.ResponseController reach the features of the wrapped writer.
+func (w *AppResponse) Unwrap() http.ResponseWriter {
+    return w.ResponseWriter
+}
+
 type WSMessageStatus string
 
 const (
@@ -0,0 +1,19 @@
+package types
+
+import (
+	"time"
+
+	"main.go/pkg/models"
+)
+
+// all the data kept about a user, exported on the user request.
+type UserDataExport struct {
+	ExportedAt time.Time         `json:"exportedAt"`
+	Profile    *models.User      `json:"profile"`
+	Addresses  []models.Address  `json:"addresses"`
+	Orders     []models.Order    `json:"orders"`
+	Reviews    []models.Review   `json:"reviews"`
+	Messages   []models.Message  `json:"messages"`
+	Cart       []models.CartItem `json:"cart"`
+	Identities []models.Identity `json:"identities"`
+}